@@ -1,12 +1,14 @@
 package main
 
 import (
+	"crypto/x509"
 	"encoding/base64"
 	"fmt"
+	"reflect"
+	"sort"
 	"strconv"
 	"strings"
 
-	"github.com/hyperledger/fabric-chaincode-go/v2/pkg/cid"
 	"github.com/hyperledger/fabric-contract-api-go/v2/contractapi"
 )
 
@@ -19,9 +21,18 @@ type AccessRule struct {
 	AllowedRoles        []string // Roles allowed to execute (from certificate attribute)
 	RequiredPermissions []string // Specific permissions required (e.g., "canRecordWage")
 	MinClearanceLevel   int      // Minimum clearance level required (1-10)
+	MaxClearanceLevel   int      // Maximum clearance level allowed (0 = no ceiling)
 	AllowedMSPs         []string // MSP IDs allowed (e.g., "Org1MSP", "Org2MSP")
 	AllowSelf           bool     // Allow users to access their own data only
 	Description         string   // Human-readable description
+
+	// RequiredAttributes lists certificate attributes that must equal the given values
+	// (e.g. {"program": "MGNREGA"}); checked against the CA-signed certificate
+	RequiredAttributes map[string]string
+
+	// AllowedMSPRoles restricts access to specific (MSP, role) pairs, e.g. Org1MSP admins but
+	// only Org2MSP auditors. When set it replaces the AllowedMSPs and AllowedRoles checks.
+	AllowedMSPRoles map[string][]string
 }
 
 // AccessDeniedError represents an access denial with details
@@ -48,6 +59,18 @@ type ClientIdentity struct {
 	State          string            // State/region attribute
 }
 
+// CallerScope is the data scope of a caller, resolved once from the certificate
+type CallerScope struct {
+	State      string // Empty for national-level callers
+	Department string // Empty when the caller has no department
+	National   bool   // True when the caller is not restricted to a state
+}
+
+// AllowsState reports whether the caller may see data for a state
+func (c *CallerScope) AllowsState(state string) bool {
+	return c.National || c.State == state
+}
+
 // ============================================================================
 // ACCESS RULES CONFIGURATION
 // ============================================================================
@@ -63,11 +86,60 @@ func GetAccessRules() map[string]AccessRule {
 			AllowedMSPs:         []string{"Org1MSP", "Org2MSP"},
 			Description:         "Record a new wage transaction",
 		},
+		"RecordWagePrivate": {
+			AllowedRoles:        []string{"employer", "admin"},
+			RequiredPermissions: []string{"canRecordWage"},
+			MinClearanceLevel:   5,
+			AllowedMSPs:         []string{"Org1MSP", "Org2MSP"},
+			Description:         "Record a wage with sensitive fields in a private data collection",
+		},
+		"ReadWagePrivate": {
+			AllowedRoles:      []string{"employer", "government_official", "auditor", "admin"},
+			MinClearanceLevel: 6,
+			AllowedMSPs:       []string{"Org1MSP", "Org2MSP"},
+			Description:       "Read a private wage from the caller organization's collection",
+		},
 		"ReadWage": {
 			AllowedRoles:      []string{"worker", "employer", "government_official", "auditor", "bank_officer", "admin"},
 			MinClearanceLevel: 1,
 			AllowedMSPs:       []string{"Org1MSP", "Org2MSP"},
-			Description:       "Read wage record by ID",
+			AllowSelf:         true,
+			Description:       "Read wage record by ID (workers: own wages only)",
+		},
+		"ReadWages": {
+			AllowedRoles:      []string{"worker", "employer", "government_official", "auditor", "bank_officer", "admin"},
+			MinClearanceLevel: 1,
+			AllowedMSPs:       []string{"Org1MSP", "Org2MSP"},
+			AllowSelf:         true,
+			Description:       "Read several wage records by ID (workers: own wages only)",
+		},
+		"GetWageRecordRaw": {
+			AllowedRoles:      []string{"worker", "employer", "government_official", "auditor", "bank_officer", "admin"},
+			MinClearanceLevel: 1,
+			AllowedMSPs:       []string{"Org1MSP", "Org2MSP"},
+			AllowSelf:         true,
+			Description:       "Read the stored bytes of a wage record (workers: own wages only)",
+		},
+		"GetWageDetail": {
+			AllowedRoles:      []string{"worker", "employer", "government_official", "auditor", "bank_officer", "admin"},
+			MinClearanceLevel: 1,
+			AllowedMSPs:       []string{"Org1MSP", "Org2MSP"},
+			AllowSelf:         true,
+			Description:       "Get a wage with its history count, UPI payments, and anomalies (workers: own wages only)",
+		},
+		"VerifyWageDocument": {
+			AllowedRoles:      []string{"worker", "employer", "government_official", "auditor", "bank_officer", "admin"},
+			MinClearanceLevel: 1,
+			AllowedMSPs:       []string{"Org1MSP", "Org2MSP"},
+			AllowSelf:         true,
+			Description:       "Verify a supporting document against a wage's recorded hash",
+		},
+		"GetWageReceiptData": {
+			AllowedRoles:      []string{"worker", "employer", "government_official", "auditor", "bank_officer", "admin"},
+			MinClearanceLevel: 1,
+			AllowedMSPs:       []string{"Org1MSP", "Org2MSP"},
+			AllowSelf:         true, // Workers and employers can only fetch receipts for their own wages
+			Description:       "Get structured data for a wage receipt",
 		},
 		"QueryWagesByWorker": {
 			AllowedRoles:      []string{"worker", "employer", "government_official", "auditor", "bank_officer", "admin"},
@@ -76,6 +148,26 @@ func GetAccessRules() map[string]AccessRule {
 			AllowSelf:         true, // Workers can only query their own wages
 			Description:       "Query wages by worker ID hash",
 		},
+		"QueryWagesByWorkerFields": {
+			AllowedRoles:      []string{"worker", "employer", "government_official", "auditor", "bank_officer", "admin"},
+			MinClearanceLevel: 1,
+			AllowedMSPs:       []string{"Org1MSP", "Org2MSP"},
+			AllowSelf:         true, // Workers can only query their own wages
+			Description:       "Query wages by worker ID hash, returning only the requested fields",
+		},
+		"QueryWagesByWorkers": {
+			AllowedRoles:      []string{"government_official", "auditor", "admin"},
+			MinClearanceLevel: 5,
+			AllowedMSPs:       []string{"Org1MSP", "Org2MSP"},
+			Description:       "Query wages for several workers at once",
+		},
+		"QueryWagesByWorkerFull": {
+			AllowedRoles:      []string{"worker", "employer", "government_official", "auditor", "bank_officer", "admin"},
+			MinClearanceLevel: 1,
+			AllowedMSPs:       []string{"Org1MSP", "Org2MSP"},
+			AllowSelf:         true, // Workers can only query their own wages (any of their hashes)
+			Description:       "Query wages across a worker's canonical hash and aliases",
+		},
 		"QueryWagesByEmployer": {
 			AllowedRoles:      []string{"employer", "government_official", "auditor", "admin"},
 			MinClearanceLevel: 3,
@@ -83,6 +175,80 @@ func GetAccessRules() map[string]AccessRule {
 			AllowSelf:         true, // Employers can only query their own wages
 			Description:       "Query wages by employer ID hash",
 		},
+		"QueryWagesByEmployerFields": {
+			AllowedRoles:      []string{"employer", "government_official", "auditor", "admin"},
+			MinClearanceLevel: 3,
+			AllowedMSPs:       []string{"Org1MSP", "Org2MSP"},
+			AllowSelf:         true, // Employers can only query their own wages
+			Description:       "Query wages by employer ID hash, returning only the requested fields",
+		},
+		"QueryWagesByProgram": {
+			AllowedRoles:      []string{"government_official", "auditor", "admin"},
+			MinClearanceLevel: 5,
+			AllowedMSPs:       []string{"Org1MSP", "Org2MSP"},
+			Description:       "Query wages by welfare/employment program",
+		},
+		"QueryWagesByState": {
+			AllowedRoles:      []string{"government_official", "auditor", "admin"},
+			MinClearanceLevel: 5,
+			AllowedMSPs:       []string{"Org1MSP", "Org2MSP"},
+			Description:       "Query wages by state, one page at a time (state-scoped)",
+		},
+		"GetStateWageSummary": {
+			AllowedRoles:      []string{"government_official", "auditor", "admin"},
+			MinClearanceLevel: 5,
+			AllowedMSPs:       []string{"Org1MSP", "Org2MSP"},
+			Description:       "Summarize wages for a state (state-scoped)",
+		},
+		"QueryWagesByDay": {
+			AllowedRoles:      []string{"government_official", "auditor", "admin"},
+			MinClearanceLevel: 5,
+			AllowedMSPs:       []string{"Org1MSP", "Org2MSP"},
+			Description:       "Query wages recorded on a calendar day",
+		},
+		"QueryWagesByCreator": {
+			AllowedRoles:      []string{"government_official", "auditor", "admin"},
+			MinClearanceLevel: 5,
+			AllowedMSPs:       []string{"Org1MSP", "Org2MSP"},
+			Description:       "Query wages recorded by a given caller",
+		},
+		"GetWageAmountHistogram": {
+			AllowedRoles:      []string{"government_official", "auditor"},
+			MinClearanceLevel: 5,
+			AllowedMSPs:       []string{"Org1MSP", "Org2MSP"},
+			Description:       "Count wages per amount bucket (state-scoped)",
+		},
+		"QueryWagesByCurrency": {
+			AllowedRoles:      []string{"government_official", "auditor", "admin"},
+			MinClearanceLevel: 5,
+			AllowedMSPs:       []string{"Org1MSP", "Org2MSP"},
+			Description:       "Query wages by currency",
+		},
+		"QueryWagesByCurrencyPaginated": {
+			AllowedRoles:      []string{"government_official", "auditor", "admin"},
+			MinClearanceLevel: 5,
+			AllowedMSPs:       []string{"Org1MSP", "Org2MSP"},
+			Description:       "Query wages by currency, one page at a time",
+		},
+		"QueryWagesByStatus": {
+			AllowedRoles:      []string{"government_official", "admin"},
+			MinClearanceLevel: 6,
+			AllowedMSPs:       []string{"Org1MSP", "Org2MSP"},
+			Description:       "Query wages by approval status, one page at a time",
+		},
+		"UpdateWageStatus": {
+			AllowedRoles:      []string{"government_official", "admin"},
+			MinClearanceLevel: 6,
+			AllowedMSPs:       []string{"Org1MSP", "Org2MSP"},
+			Description:       "Approve or reject a pending wage",
+		},
+		"QueryWagesByEmployerPaginated": {
+			AllowedRoles:      []string{"employer", "government_official", "auditor", "admin"},
+			MinClearanceLevel: 3,
+			AllowedMSPs:       []string{"Org1MSP", "Org2MSP"},
+			AllowSelf:         true, // Employers can only query their own wages
+			Description:       "Query wages by employer ID hash, one page at a time",
+		},
 		"BatchRecordWages": {
 			AllowedRoles:        []string{"employer", "admin"},
 			RequiredPermissions: []string{"canRecordWage", "canBatchProcess"},
@@ -96,6 +262,12 @@ func GetAccessRules() map[string]AccessRule {
 			AllowedMSPs:       []string{"Org1MSP", "Org2MSP"},
 			Description:       "Query wage history for a record",
 		},
+		"GetWageRecordDiff": {
+			AllowedRoles:      []string{"auditor", "government_official", "admin"},
+			MinClearanceLevel: 6,
+			AllowedMSPs:       []string{"Org1MSP", "Org2MSP"},
+			Description:       "Diff two history versions of a wage record",
+		},
 		"CalculateTotalIncome": {
 			AllowedRoles:      []string{"worker", "employer", "government_official", "auditor", "bank_officer", "admin"},
 			MinClearanceLevel: 2,
@@ -110,6 +282,60 @@ func GetAccessRules() map[string]AccessRule {
 			AllowSelf:         true,
 			Description:       "Get monthly income breakdown",
 		},
+		"GetWorkerEmployers": {
+			AllowedRoles:      []string{"worker", "government_official", "auditor", "bank_officer", "admin"},
+			MinClearanceLevel: 2,
+			AllowedMSPs:       []string{"Org1MSP", "Org2MSP"},
+			AllowSelf:         true,
+			Description:       "List distinct employers that have paid a worker",
+		},
+		"GetWorkersByEmployer": {
+			AllowedRoles:      []string{"employer", "government_official", "auditor", "admin"},
+			MinClearanceLevel: 3,
+			AllowedMSPs:       []string{"Org1MSP", "Org2MSP"},
+			AllowSelf:         true, // Employers can only list their own workers
+			Description:       "List distinct workers an employer has paid",
+		},
+		"GetWorkerRiskScore": {
+			AllowedRoles:      []string{"worker", "government_official", "auditor", "bank_officer", "admin"},
+			MinClearanceLevel: 2,
+			AllowedMSPs:       []string{"Org1MSP", "Org2MSP"},
+			AllowSelf:         true,
+			Description:       "Get a worker's composite vulnerability score",
+		},
+		"GetWorkerWageVolatility": {
+			AllowedRoles:      []string{"worker", "government_official", "auditor", "bank_officer", "admin"},
+			MinClearanceLevel: 2,
+			AllowedMSPs:       []string{"Org1MSP", "Org2MSP"},
+			AllowSelf:         true,
+			Description:       "Get the coefficient of variation of a worker's monthly income",
+		},
+		"GetWorkerCaseFile": {
+			AllowedRoles:      []string{"government_official", "auditor", "admin"},
+			MinClearanceLevel: 6,
+			AllowedMSPs:       []string{"Org1MSP", "Org2MSP"},
+			Description:       "Get a worker's profile, income, poverty status, open anomalies and recent payments",
+		},
+		"GetWorkerCreditProfile": {
+			AllowedRoles:      []string{"bank_officer", "admin"},
+			MinClearanceLevel: 5,
+			AllowedMSPs:       []string{"Org1MSP", "Org2MSP"},
+			Description:       "Get derived income metrics for credit assessment",
+		},
+		"GetWorkerPaymentSpan": {
+			AllowedRoles:      []string{"worker", "government_official", "auditor", "bank_officer", "admin"},
+			MinClearanceLevel: 2,
+			AllowedMSPs:       []string{"Org1MSP", "Org2MSP"},
+			AllowSelf:         true,
+			Description:       "Get the first and last payment dates for a worker",
+		},
+		"GetWorkerPaymentCalendar": {
+			AllowedRoles:      []string{"worker", "government_official", "auditor", "bank_officer", "admin"},
+			MinClearanceLevel: 2,
+			AllowedMSPs:       []string{"Org1MSP", "Org2MSP"},
+			AllowSelf:         true,
+			Description:       "Get a worker's payments per day for a month",
+		},
 
 		// UPI TRANSACTION FUNCTIONS
 		"RecordUPITransaction": {
@@ -132,6 +358,50 @@ func GetAccessRules() map[string]AccessRule {
 			AllowSelf:         true,
 			Description:       "Query UPI transactions for a worker",
 		},
+		"QueryUPITransactionsByWorkerFields": {
+			AllowedRoles:      []string{"worker", "employer", "government_official", "bank_officer", "auditor", "admin"},
+			MinClearanceLevel: 2,
+			AllowedMSPs:       []string{"Org1MSP", "Org2MSP"},
+			AllowSelf:         true,
+			Description:       "Query UPI transactions for a worker, returning only the requested fields",
+		},
+		"GetUPISettlementSummary": {
+			AllowedRoles:      []string{"bank_officer", "auditor", "admin"},
+			MinClearanceLevel: 5,
+			AllowedMSPs:       []string{"Org1MSP", "Org2MSP"},
+			Description:       "Get daily UPI settlement totals for bank reconciliation",
+		},
+		"QueryUPIBySender": {
+			AllowedRoles:      []string{"bank_officer", "auditor", "admin"},
+			MinClearanceLevel: 5,
+			AllowedMSPs:       []string{"Org1MSP", "Org2MSP"},
+			Description:       "Query UPI transactions by exact sender name",
+		},
+		"QueryUPIByEmployer": {
+			AllowedRoles:      []string{"employer", "bank_officer", "auditor", "admin"},
+			MinClearanceLevel: 3,
+			AllowedMSPs:       []string{"Org1MSP", "Org2MSP"},
+			AllowSelf:         true, // Employers can only query their own payments
+			Description:       "Query UPI transactions attributed to an employer",
+		},
+		"QueryWagesByOutdatedPolicy": {
+			AllowedRoles:      []string{"government_official", "admin"},
+			MinClearanceLevel: 6,
+			AllowedMSPs:       []string{"Org1MSP", "Org2MSP"},
+			Description:       "List wages recorded under a superseded policy version",
+		},
+		"GetWagesWithoutUPI": {
+			AllowedRoles:      []string{"government_official", "auditor", "admin"},
+			MinClearanceLevel: 6,
+			AllowedMSPs:       []string{"Org1MSP", "Org2MSP"},
+			Description:       "List declared wages with no linked UPI payment",
+		},
+		"GetUPITransactionsWithoutWage": {
+			AllowedRoles:      []string{"government_official", "auditor"},
+			MinClearanceLevel: 6,
+			AllowedMSPs:       []string{"Org1MSP", "Org2MSP"},
+			Description:       "List UPI payments with no linked declared wage",
+		},
 
 		// USER MANAGEMENT FUNCTIONS
 		"RegisterUser": {
@@ -148,6 +418,10 @@ func GetAccessRules() map[string]AccessRule {
 			AllowSelf:         true,
 			Description:       "Get user profile by ID hash",
 		},
+		"DetectRoleDrift": {
+			AllowedMSPs: []string{"Org1MSP", "Org2MSP"},
+			Description: "Compare the caller's certificate role with their stored user role",
+		},
 		"UpdateUserStatus": {
 			AllowedRoles:        []string{"government_official", "admin"},
 			RequiredPermissions: []string{"canManageUsers"},
@@ -155,12 +429,42 @@ func GetAccessRules() map[string]AccessRule {
 			AllowedMSPs:         []string{"Org1MSP"},
 			Description:         "Update user status (active/inactive/suspended)",
 		},
+		"GetUserCount": {
+			AllowedRoles:      []string{"government_official", "admin"},
+			MinClearanceLevel: 6,
+			AllowedMSPs:       []string{"Org1MSP", "Org2MSP"},
+			Description:       "Count registered users",
+		},
+		"GetActiveUserCount": {
+			AllowedRoles:      []string{"government_official", "admin"},
+			MinClearanceLevel: 6,
+			AllowedMSPs:       []string{"Org1MSP", "Org2MSP"},
+			Description:       "Count active users",
+		},
+		"GetUserCountsByRole": {
+			AllowedRoles:      []string{"government_official", "admin"},
+			MinClearanceLevel: 6,
+			AllowedMSPs:       []string{"Org1MSP", "Org2MSP"},
+			Description:       "Count users by role and status",
+		},
+		"GetUserRegistrationTrend": {
+			AllowedRoles:      []string{"government_official", "admin"},
+			MinClearanceLevel: 6,
+			AllowedMSPs:       []string{"Org1MSP", "Org2MSP"},
+			Description:       "Count user registrations per day or week",
+		},
 		"VerifyUserRole": {
 			AllowedRoles:      []string{"worker", "employer", "government_official", "bank_officer", "auditor", "admin"},
 			MinClearanceLevel: 1,
 			AllowedMSPs:       []string{"Org1MSP", "Org2MSP"},
 			Description:       "Verify user has specific role",
 		},
+		"AddWorkerAlias": {
+			AllowedRoles:      []string{"admin"},
+			MinClearanceLevel: 9,
+			AllowedMSPs:       []string{"Org1MSP"},
+			Description:       "Map an alias idHash to a worker's canonical hash",
+		},
 
 		// POVERTY THRESHOLD FUNCTIONS
 		"SetPovertyThreshold": {
@@ -176,6 +480,31 @@ func GetAccessRules() map[string]AccessRule {
 			AllowedMSPs:       []string{"Org1MSP", "Org2MSP"},
 			Description:       "Get poverty threshold for state",
 		},
+		"SetExchangeRate": {
+			AllowedRoles:        []string{"government_official", "admin"},
+			RequiredPermissions: []string{"canUpdateThresholds"},
+			MinClearanceLevel:   8,
+			AllowedMSPs:         []string{"Org1MSP"},
+			Description:         "Set a reference exchange rate for receipt display",
+		},
+		"GetExchangeRate": {
+			AllowedRoles:      []string{"worker", "employer", "government_official", "bank_officer", "auditor", "admin"},
+			MinClearanceLevel: 1,
+			AllowedMSPs:       []string{"Org1MSP", "Org2MSP"},
+			Description:       "Get a reference exchange rate",
+		},
+		"CompareThresholds": {
+			AllowedRoles:      []string{"government_official", "auditor", "admin"},
+			MinClearanceLevel: 6,
+			AllowedMSPs:       []string{"Org1MSP", "Org2MSP"},
+			Description:       "Compare poverty thresholds across states",
+		},
+		"GetThresholdHistory": {
+			AllowedRoles:      []string{"government_official", "auditor", "admin"},
+			MinClearanceLevel: 6,
+			AllowedMSPs:       []string{"Org1MSP", "Org2MSP"},
+			Description:       "Get the change history of a state's thresholds",
+		},
 		"CheckPovertyStatus": {
 			AllowedRoles:      []string{"worker", "employer", "government_official", "bank_officer", "auditor", "admin"},
 			MinClearanceLevel: 2,
@@ -192,6 +521,12 @@ func GetAccessRules() map[string]AccessRule {
 			AllowedMSPs:         []string{"Org1MSP", "Org2MSP"},
 			Description:         "Flag wage record as suspicious",
 		},
+		"GetAnomaly": {
+			AllowedRoles:      []string{"auditor", "government_official", "admin"},
+			MinClearanceLevel: 6,
+			AllowedMSPs:       []string{"Org1MSP", "Org2MSP"},
+			Description:       "Get one anomaly by ID",
+		},
 		"GetFlaggedWages": {
 			AllowedRoles:      []string{"auditor", "government_official", "admin"},
 			MinClearanceLevel: 6,
@@ -205,6 +540,43 @@ func GetAccessRules() map[string]AccessRule {
 			AllowedMSPs:         []string{"Org1MSP", "Org2MSP"},
 			Description:         "Update anomaly review status",
 		},
+		"BatchUpdateAnomalyStatus": {
+			AllowedRoles:        []string{"auditor", "government_official", "admin"},
+			RequiredPermissions: []string{"canReviewAnomaly"},
+			MinClearanceLevel:   7,
+			AllowedMSPs:         []string{"Org1MSP", "Org2MSP"},
+			Description:         "Apply one review status to many anomalies",
+		},
+		"GetAnomalyResolutionStats": {
+			AllowedRoles:      []string{"government_official", "admin"},
+			MinClearanceLevel: 6,
+			AllowedMSPs:       []string{"Org1MSP", "Org2MSP"},
+			Description:       "Measure how quickly anomalies are resolved",
+		},
+		"GetWagesNeedingReview": {
+			AllowedRoles:      []string{"auditor", "government_official", "admin"},
+			MinClearanceLevel: 6,
+			AllowedMSPs:       []string{"Org1MSP", "Org2MSP"},
+			Description:       "Get the wage review worklist",
+		},
+		"GetAuditLogsChunk": {
+			AllowedRoles:      []string{"auditor", "government_official", "admin"},
+			MinClearanceLevel: 6,
+			AllowedMSPs:       []string{"Org1MSP", "Org2MSP"},
+			Description:       "Export audit logs in bounded chunks",
+		},
+		"GetFlaggedWagesWithDetails": {
+			AllowedRoles:      []string{"auditor", "government_official", "admin"},
+			MinClearanceLevel: 6,
+			AllowedMSPs:       []string{"Org1MSP", "Org2MSP"},
+			Description:       "Get open anomalies joined with their wage records",
+		},
+		"QueryAnomaliesByFlagger": {
+			AllowedRoles:      []string{"government_official", "admin"},
+			MinClearanceLevel: 8,
+			AllowedMSPs:       []string{"Org1MSP", "Org2MSP"},
+			Description:       "Get anomalies raised by a given flagger",
+		},
 
 		// COMPLIANCE & REPORTING FUNCTIONS
 		"GenerateComplianceReport": {
@@ -222,6 +594,12 @@ func GetAccessRules() map[string]AccessRule {
 			AllowedMSPs:       []string{"Org1MSP"},
 			Description:       "Initialize ledger with seed data",
 		},
+		"ForceInitLedger": {
+			AllowedRoles:      []string{"admin"},
+			MinClearanceLevel: 10,
+			AllowedMSPs:       []string{"Org1MSP"},
+			Description:       "Re-seed an already initialized ledger",
+		},
 
 		// EXISTENCE CHECK FUNCTIONS (read-only, all roles)
 		"WageExists": {
@@ -230,6 +608,12 @@ func GetAccessRules() map[string]AccessRule {
 			AllowedMSPs:       []string{"Org1MSP", "Org2MSP"},
 			Description:       "Check if wage record exists",
 		},
+		"WagesExist": {
+			AllowedRoles:      []string{"worker", "employer", "government_official", "auditor", "bank_officer", "admin"},
+			MinClearanceLevel: 1,
+			AllowedMSPs:       []string{"Org1MSP", "Org2MSP"},
+			Description:       "Check if several wage records exist",
+		},
 		"UPITransactionExists": {
 			AllowedRoles:      []string{"worker", "employer", "government_official", "auditor", "bank_officer", "admin"},
 			MinClearanceLevel: 1,
@@ -242,7 +626,266 @@ func GetAccessRules() map[string]AccessRule {
 			AllowedMSPs:       []string{"Org1MSP", "Org2MSP"},
 			Description:       "Check if user exists",
 		},
+
+		// AUDIT FUNCTIONS
+		// Each audit query has its own rule so audit visibility can be tuned independently
+		"GetAuditLogs": {
+			AllowedRoles:      []string{"auditor", "government_official", "admin"},
+			MinClearanceLevel: 6,
+			AllowedMSPs:       []string{"Org1MSP", "Org2MSP"},
+			Description:       "Query audit logs with filters",
+		},
+		"GetAuditSummary": {
+			AllowedRoles:        []string{"government_official", "auditor", "admin"},
+			RequiredPermissions: []string{"canGenerateReport"},
+			MinClearanceLevel:   6,
+			AllowedMSPs:         []string{"Org1MSP", "Org2MSP"},
+			Description:         "Get aggregated audit statistics",
+		},
+		"GetUserActivityLog": {
+			AllowedRoles:      []string{"worker", "employer", "government_official", "bank_officer", "auditor", "admin"},
+			MinClearanceLevel: 1,
+			AllowedMSPs:       []string{"Org1MSP", "Org2MSP"},
+			AllowSelf:         true,
+			Description:       "Get audit activity for a user (users: own activity only)",
+		},
+		"GetHighRiskEvents": {
+			AllowedRoles:        []string{"government_official", "auditor", "admin"},
+			RequiredPermissions: []string{"canGenerateReport"},
+			MinClearanceLevel:   6,
+			AllowedMSPs:         []string{"Org1MSP", "Org2MSP"},
+			Description:         "Get high and critical risk audit events",
+		},
+		"GetAuditTimeSeries": {
+			AllowedRoles:      []string{"auditor", "admin"},
+			MinClearanceLevel: 6,
+			AllowedMSPs:       []string{"Org1MSP", "Org2MSP"},
+			Description:       "Count audit events per day or week for trend charts",
+		},
+		"GetAccessDenials": {
+			AllowedRoles:        []string{"government_official", "admin"},
+			RequiredPermissions: []string{"canManageUsers"},
+			MinClearanceLevel:   9,
+			AllowedMSPs:         []string{"Org1MSP"},
+			Description:         "Get access denial events for security monitoring",
+		},
+		"GetAccessDenialsPage": {
+			AllowedRoles:        []string{"government_official", "admin"},
+			RequiredPermissions: []string{"canManageUsers"},
+			MinClearanceLevel:   9,
+			AllowedMSPs:         []string{"Org1MSP"},
+			Description:         "Get access denial events one page at a time",
+		},
+		"GetAccessDenialCounts": {
+			AllowedRoles:        []string{"government_official", "admin"},
+			RequiredPermissions: []string{"canManageUsers"},
+			MinClearanceLevel:   9,
+			AllowedMSPs:         []string{"Org1MSP"},
+			Description:         "Count access denial events per caller or function",
+		},
+		"GetCallerActivityCount": {
+			AllowedRoles:      []string{"auditor", "admin"},
+			MinClearanceLevel: 6,
+			AllowedMSPs:       []string{"Org1MSP", "Org2MSP"},
+			Description:       "Count audit events per type for a caller",
+		},
+		"GetAuditTrailForTarget": {
+			AllowedRoles:      []string{"auditor", "admin"},
+			MinClearanceLevel: 6,
+			AllowedMSPs:       []string{"Org1MSP", "Org2MSP"},
+			Description:       "Get every audit event touching a record",
+		},
+		"DetectBruteForcePatterns": {
+			AllowedRoles:      []string{"admin"},
+			MinClearanceLevel: 8,
+			AllowedMSPs:       []string{"Org1MSP", "Org2MSP"},
+			Description:       "Flag callers with bursts of access denials",
+		},
+		"GetMSPActivitySummary": {
+			AllowedRoles:      []string{"admin"},
+			MinClearanceLevel: 8,
+			AllowedMSPs:       []string{"Org1MSP", "Org2MSP"},
+			Description:       "Summarize audit activity per MSP",
+		},
+
+		// NOTIFICATION FUNCTIONS
+		"CheckMyPaymentDiscrepancy": {
+			AllowedRoles:      []string{"worker"},
+			MinClearanceLevel: 1,
+			AllowedMSPs:       []string{"Org1MSP", "Org2MSP"},
+			AllowSelf:         true,
+			Description:       "Compare the caller's recorded wages with their received UPI payments",
+		},
+		"GetMyNotifications": {
+			AllowedRoles:      []string{"worker"},
+			MinClearanceLevel: 1,
+			AllowedMSPs:       []string{"Org1MSP", "Org2MSP"},
+			AllowSelf:         true,
+			Description:       "List the caller's own notifications",
+		},
+		"MarkNotificationRead": {
+			AllowedRoles:      []string{"worker"},
+			MinClearanceLevel: 1,
+			AllowedMSPs:       []string{"Org1MSP", "Org2MSP"},
+			AllowSelf:         true,
+			Description:       "Mark one of the caller's notifications as read",
+		},
+		"GrantConsent": {
+			AllowedRoles:      []string{"worker"},
+			MinClearanceLevel: 1,
+			AllowedMSPs:       []string{"Org1MSP", "Org2MSP"},
+			AllowSelf:         true,
+			Description:       "Allow an organisation to read the caller's income data",
+		},
+		"RevokeConsent": {
+			AllowedRoles:      []string{"worker"},
+			MinClearanceLevel: 1,
+			AllowedMSPs:       []string{"Org1MSP", "Org2MSP"},
+			AllowSelf:         true,
+			Description:       "Withdraw one of the caller's consents",
+		},
+		"GetMyConsents": {
+			AllowedRoles:      []string{"worker"},
+			MinClearanceLevel: 1,
+			AllowedMSPs:       []string{"Org1MSP", "Org2MSP"},
+			AllowSelf:         true,
+			Description:       "List the caller's active consents",
+		},
+		"GetGrantedConsentsForWorker": {
+			AllowedRoles:      []string{"government_official", "auditor", "admin"},
+			MinClearanceLevel: 6,
+			AllowedMSPs:       []string{"Org1MSP", "Org2MSP"},
+			Description:       "List the active consents a worker has granted",
+		},
+
+		// CONFIGURATION FUNCTIONS
+		"SetConfig": {
+			AllowedRoles:      []string{"admin"},
+			MinClearanceLevel: 9,
+			AllowedMSPs:       []string{"Org1MSP"},
+			Description:       "Change a contract configuration setting",
+		},
+		"QuarantineState": {
+			AllowedRoles:      []string{"admin"},
+			MinClearanceLevel: 9,
+			AllowedMSPs:       []string{"Org1MSP"},
+			Description:       "Freeze wage and UPI writes for a state",
+		},
+		"LiftQuarantine": {
+			AllowedRoles:      []string{"admin"},
+			MinClearanceLevel: 9,
+			AllowedMSPs:       []string{"Org1MSP"},
+			Description:       "Resume writes for a quarantined state",
+		},
+		"GetConfig": {
+			AllowedRoles:      []string{"admin", "government_official", "auditor"},
+			MinClearanceLevel: 6,
+			AllowedMSPs:       []string{"Org1MSP", "Org2MSP"},
+			Description:       "Read a contract configuration setting",
+		},
+		"GetSystemConfig": {
+			AllowedRoles:      []string{"admin"},
+			MinClearanceLevel: 6,
+			AllowedMSPs:       []string{"Org1MSP", "Org2MSP"},
+			Description:       "Read all contract configuration settings",
+		},
+		"ValidateAccessRules": {
+			AllowedRoles:      []string{"admin"},
+			MinClearanceLevel: 6,
+			AllowedMSPs:       []string{"Org1MSP", "Org2MSP"},
+			Description:       "Check the access rules for misconfigurations",
+		},
+	}
+}
+
+// knownRoles are the roles a certificate role attribute or User record may hold
+var knownRoles = map[string]bool{
+	"worker":              true,
+	"employer":            true,
+	"government_official": true,
+	"bank_officer":        true,
+	"auditor":             true,
+	"admin":               true,
+}
+
+// validateAccessRules checks rules for misconfigurations that would silently lock callers
+// out or open a function to every organization: clearance levels outside 1-10, unknown
+// roles, empty MSP lists, and rules that name no transaction function. As in CheckAccess,
+// a MinClearanceLevel of 0 means no minimum and empty AllowedRoles allow any role (see
+// DetectRoleDrift). It returns one message per problem, sorted.
+func validateAccessRules(rules map[string]AccessRule) []string {
+	contract := reflect.TypeOf(&SmartContract{})
+	var problems []string
+	for function, rule := range rules {
+		report := func(format string, args ...interface{}) {
+			problems = append(problems, function+": "+fmt.Sprintf(format, args...))
+		}
+
+		if _, ok := contract.MethodByName(function); !ok {
+			report("no such transaction function")
+		}
+		if rule.MinClearanceLevel < 0 || rule.MinClearanceLevel > 10 {
+			report("MinClearanceLevel %d outside 1-10", rule.MinClearanceLevel)
+		}
+		if rule.MaxClearanceLevel != 0 && (rule.MaxClearanceLevel < rule.MinClearanceLevel || rule.MaxClearanceLevel > 10) {
+			report("MaxClearanceLevel %d outside %d-10", rule.MaxClearanceLevel, rule.MinClearanceLevel)
+		}
+
+		if len(rule.AllowedMSPRoles) > 0 {
+			for msp, roles := range rule.AllowedMSPRoles {
+				if strings.TrimSpace(msp) == "" {
+					report("AllowedMSPRoles has an empty MSP ID")
+				}
+				if len(roles) == 0 {
+					report("AllowedMSPRoles[%s] allows no roles", msp)
+				}
+				for _, role := range roles {
+					if !knownRoles[role] {
+						report("AllowedMSPRoles[%s] has unknown role %q", msp, role)
+					}
+				}
+			}
+			continue
+		}
+
+		for _, role := range rule.AllowedRoles {
+			if !knownRoles[role] {
+				report("unknown role %q", role)
+			}
+		}
+		if len(rule.AllowedMSPs) == 0 {
+			report("AllowedMSPs is empty")
+		}
+		for _, msp := range rule.AllowedMSPs {
+			if strings.TrimSpace(msp) == "" {
+				report("AllowedMSPs has an empty MSP ID")
+			}
+		}
+	}
+
+	sort.Strings(problems)
+	return problems
+}
+
+// ValidateAccessRules returns the misconfigurations found in the compiled access rules, or
+// an empty list when there are none. The same check runs once when the chaincode starts.
+// SECURITY: Only admins.
+func (s *SmartContract) ValidateAccessRules(ctx contractapi.TransactionContextInterface) ([]string, error) {
+	// IAM Check
+	if IAMEnabled {
+		_, err := CheckAccess(ctx, "ValidateAccessRules")
+		if err != nil {
+			s.LogAccessDenied(ctx, "ValidateAccessRules", "rules", "system", err.Error())
+			return nil, fmt.Errorf("access denied: %w", err)
+		}
+		s.LogDataRead(ctx, "ValidateAccessRules", "rules", "system")
 	}
+
+	problems := validateAccessRules(GetAccessRules())
+	if problems == nil {
+		problems = []string{}
+	}
+	return problems, nil
 }
 
 // ============================================================================
@@ -257,21 +900,21 @@ func GetClientIdentity(ctx contractapi.TransactionContextInterface) (*ClientIden
 	}
 
 	// Get client ID (enrollment ID)
-	clientID, err := cid.GetID(ctx.GetStub())
+	clientID, err := ctx.GetClientIdentity().GetID()
 	if err != nil {
 		return nil, fmt.Errorf("failed to get client ID: %w", err)
 	}
 	identity.ID = clientID
 
 	// Get MSP ID
-	mspID, err := cid.GetMSPID(ctx.GetStub())
+	mspID, err := ctx.GetClientIdentity().GetMSPID()
 	if err != nil {
 		return nil, fmt.Errorf("failed to get MSP ID: %w", err)
 	}
 	identity.MSPID = mspID
 
 	// Get role attribute
-	role, found, err := cid.GetAttributeValue(ctx.GetStub(), "role")
+	role, found, err := ctx.GetClientIdentity().GetAttributeValue("role")
 	if err != nil {
 		return nil, fmt.Errorf("failed to get role attribute: %w", err)
 	}
@@ -283,7 +926,7 @@ func GetClientIdentity(ctx contractapi.TransactionContextInterface) (*ClientIden
 	// AUTO-DETECT ADMIN FROM CERTIFICATE OU (Organizational Unit)
 	// This allows default Fabric admin certificates to work without explicit role attributes
 	if identity.Role == "" {
-		// The clientID from cid.GetID() is base64 encoded, so we need to decode it
+		// The clientID from GetID() is base64 encoded, so we need to decode it
 		// to check for admin OU. The format is: x509::CN=...,OU=admin,...::...
 		decodedID := clientID
 		if decoded, err := base64.StdEncoding.DecodeString(clientID); err == nil {
@@ -313,7 +956,7 @@ func GetClientIdentity(ctx contractapi.TransactionContextInterface) (*ClientIden
 
 	// Get clearance level (if not already set by admin detection)
 	if identity.ClearanceLevel == 0 {
-		clearanceStr, found, err := cid.GetAttributeValue(ctx.GetStub(), "clearanceLevel")
+		clearanceStr, found, err := ctx.GetClientIdentity().GetAttributeValue("clearanceLevel")
 		if err == nil && found {
 			clearance, _ := strconv.Atoi(clearanceStr)
 			identity.ClearanceLevel = clearance
@@ -369,15 +1012,19 @@ func GetClientIdentity(ctx contractapi.TransactionContextInterface) (*ClientIden
 		}
 	}
 
-	// Get department
-	department, found, _ := cid.GetAttributeValue(ctx.GetStub(), "department")
-	if found {
+	// Get department, falling back to the certificate OU when the attribute is absent
+	departmentAttr, found, _ := ctx.GetClientIdentity().GetAttributeValue("department")
+	var cert *x509.Certificate
+	if !found {
+		cert, _ = ctx.GetClientIdentity().GetX509Certificate()
+	}
+	if department := resolveDepartment(departmentAttr, found, cert); department != "" {
 		identity.Department = department
 		identity.Attributes["department"] = department
 	}
 
 	// Get state
-	state, found, _ := cid.GetAttributeValue(ctx.GetStub(), "state")
+	state, found, _ := ctx.GetClientIdentity().GetAttributeValue("state")
 	if found {
 		identity.State = state
 		identity.Attributes["state"] = state
@@ -392,7 +1039,7 @@ func GetClientIdentity(ctx contractapi.TransactionContextInterface) (*ClientIden
 	}
 
 	for _, perm := range permissionAttrs {
-		permValue, found, err := cid.GetAttributeValue(ctx.GetStub(), perm)
+		permValue, found, err := ctx.GetClientIdentity().GetAttributeValue(perm)
 		if err == nil && found {
 			identity.Permissions[perm] = permValue == "true"
 			identity.Attributes[perm] = permValue
@@ -400,7 +1047,7 @@ func GetClientIdentity(ctx contractapi.TransactionContextInterface) (*ClientIden
 	}
 
 	// Get idHash (for self-access checks)
-	idHash, found, _ := cid.GetAttributeValue(ctx.GetStub(), "idHash")
+	idHash, found, _ := ctx.GetClientIdentity().GetAttributeValue("idHash")
 	if found {
 		identity.Attributes["idHash"] = idHash
 	}
@@ -412,6 +1059,35 @@ func GetClientIdentity(ctx contractapi.TransactionContextInterface) (*ClientIden
 // ACCESS CONTROL FUNCTIONS
 // ============================================================================
 
+// checkClearance enforces a rule's clearance band: at least MinClearanceLevel and, for
+// band-restricted functions, at most MaxClearanceLevel
+func checkClearance(identity *ClientIdentity, functionName string, rule AccessRule) error {
+	// Check clearance level
+	if rule.MinClearanceLevel > 0 {
+		if identity.ClearanceLevel < rule.MinClearanceLevel {
+			return &AccessDeniedError{
+				Reason:     fmt.Sprintf("Clearance level %d below required %d", identity.ClearanceLevel, rule.MinClearanceLevel),
+				UserID:     identity.ID,
+				Function:   functionName,
+				RequiredBy: fmt.Sprintf("MinClearanceLevel: %d", rule.MinClearanceLevel),
+			}
+		}
+	}
+
+	// Check clearance ceiling for band-restricted functions
+	if rule.MaxClearanceLevel > 0 {
+		if identity.ClearanceLevel > rule.MaxClearanceLevel {
+			return &AccessDeniedError{
+				Reason:     fmt.Sprintf("Clearance level %d above allowed maximum %d", identity.ClearanceLevel, rule.MaxClearanceLevel),
+				UserID:     identity.ID,
+				Function:   functionName,
+				RequiredBy: fmt.Sprintf("MaxClearanceLevel: %d", rule.MaxClearanceLevel),
+			}
+		}
+	}
+	return nil
+}
+
 // CheckAccess verifies if the client meets access requirements for a function
 func CheckAccess(ctx contractapi.TransactionContextInterface, functionName string) (*ClientIdentity, error) {
 	// Get access rules
@@ -432,8 +1108,15 @@ func CheckAccess(ctx contractapi.TransactionContextInterface, functionName strin
 		return nil, fmt.Errorf("failed to get client identity: %w", err)
 	}
 
+	// Check (MSP, role) pairs; these take precedence over the flat lists
+	if len(rule.AllowedMSPRoles) > 0 {
+		if err := checkMSPRoles(identity, functionName, rule.AllowedMSPRoles); err != nil {
+			return nil, err
+		}
+	}
+
 	// Check MSP ID
-	if len(rule.AllowedMSPs) > 0 {
+	if len(rule.AllowedMSPs) > 0 && len(rule.AllowedMSPRoles) == 0 {
 		allowed := false
 		for _, allowedMSP := range rule.AllowedMSPs {
 			if identity.MSPID == allowedMSP {
@@ -452,7 +1135,7 @@ func CheckAccess(ctx contractapi.TransactionContextInterface, functionName strin
 	}
 
 	// Check role
-	if len(rule.AllowedRoles) > 0 {
+	if len(rule.AllowedRoles) > 0 && len(rule.AllowedMSPRoles) == 0 {
 		if identity.Role == "" {
 			return nil, &AccessDeniedError{
 				Reason:     "No role attribute found in certificate",
@@ -479,16 +1162,14 @@ func CheckAccess(ctx contractapi.TransactionContextInterface, functionName strin
 		}
 	}
 
-	// Check clearance level
-	if rule.MinClearanceLevel > 0 {
-		if identity.ClearanceLevel < rule.MinClearanceLevel {
-			return nil, &AccessDeniedError{
-				Reason:     fmt.Sprintf("Clearance level %d below required %d", identity.ClearanceLevel, rule.MinClearanceLevel),
-				UserID:     identity.ID,
-				Function:   functionName,
-				RequiredBy: fmt.Sprintf("MinClearanceLevel: %d", rule.MinClearanceLevel),
-			}
-		}
+	// Check clearance band
+	if err := checkClearance(identity, functionName, rule); err != nil {
+		return nil, err
+	}
+
+	// Check required attribute values
+	if err := checkRequiredAttributes(ctx, identity, functionName, rule.RequiredAttributes); err != nil {
+		return nil, err
 	}
 
 	// Check required permissions
@@ -506,10 +1187,85 @@ func CheckAccess(ctx contractapi.TransactionContextInterface, functionName strin
 	return identity, nil
 }
 
+// checkMSPRoles verifies that the caller's role is allowed for the caller's MSP.
+func checkMSPRoles(identity *ClientIdentity, functionName string, allowed map[string][]string) error {
+	roles, ok := allowed[identity.MSPID]
+	if !ok {
+		return &AccessDeniedError{
+			Reason:     fmt.Sprintf("MSP '%s' not allowed", identity.MSPID),
+			UserID:     identity.ID,
+			Function:   functionName,
+			RequiredBy: fmt.Sprintf("AllowedMSPRoles: %v", allowed),
+		}
+	}
+	for _, role := range roles {
+		if identity.Role != "" && identity.Role == role {
+			return nil
+		}
+	}
+	return &AccessDeniedError{
+		Reason:     fmt.Sprintf("Role '%s' not allowed for MSP '%s'", identity.Role, identity.MSPID),
+		UserID:     identity.ID,
+		Function:   functionName,
+		RequiredBy: fmt.Sprintf("AllowedMSPRoles[%s]: %v", identity.MSPID, roles),
+	}
+}
+
+// checkRequiredAttributes verifies that each required attribute in the caller's certificate
+// equals its expected value. Attributes are checked in name order so the reported failure
+// is deterministic.
+func checkRequiredAttributes(ctx contractapi.TransactionContextInterface, identity *ClientIdentity, functionName string, required map[string]string) error {
+	names := make([]string, 0, len(required))
+	for name := range required {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		expected := required[name]
+		value, found, err := ctx.GetClientIdentity().GetAttributeValue(name)
+		if err != nil {
+			return fmt.Errorf("failed to get attribute %s: %w", name, err)
+		}
+
+		reason := ""
+		if !found {
+			reason = fmt.Sprintf("Missing required attribute: %s", name)
+		} else if value != expected {
+			reason = fmt.Sprintf("Attribute %s is '%s', required '%s'", name, value, expected)
+		}
+		if reason != "" {
+			return &AccessDeniedError{
+				Reason:     reason,
+				UserID:     identity.ID,
+				Function:   functionName,
+				RequiredBy: fmt.Sprintf("RequiredAttributes: %s=%s", name, expected),
+			}
+		}
+	}
+	return nil
+}
+
 // CheckSelfAccess verifies if the user is accessing their own data
 // This is a soft check - if idHash is not set, we allow access based on role alone
 // In production with strict self-access requirements, idHash must be set in certificates
-func CheckSelfAccess(identity *ClientIdentity, functionName string, targetIDHash string) error {
+// Roles listed in the selfAccessBypassRoles config skip the check entirely, as do callers
+// whose clearance reaches the selfAccessBypassClearance config (when set)
+func CheckSelfAccess(ctx contractapi.TransactionContextInterface, identity *ClientIdentity, functionName string, targetIDHash string) error {
+	if minClearance, err := getConfigInt(ctx, ConfigSelfAccessBypassClearance); err == nil && minClearance > 0 && identity.ClearanceLevel >= minClearance {
+		return nil // Clearance-based bypass
+	}
+
+	bypassRoles, err := getConfigList(ctx, ConfigSelfAccessBypassRoles)
+	if err != nil {
+		// Fall back to the compiled default rather than failing every self-access check
+		bypassRoles = splitConfigList(DefaultSelfAccessBypassRoles)
+	}
+	return checkSelfAccess(identity, functionName, targetIDHash, bypassRoles)
+}
+
+// checkSelfAccess implements CheckSelfAccess for an explicit set of bypass roles
+func checkSelfAccess(identity *ClientIdentity, functionName string, targetIDHash string, bypassRoles []string) error {
 	rules := GetAccessRules()
 	rule, exists := rules[functionName]
 	if !exists {
@@ -519,10 +1275,9 @@ func CheckSelfAccess(identity *ClientIdentity, functionName string, targetIDHash
 	// If self-access is enabled
 	if rule.AllowSelf {
 		// Privileged roles can access any data
-		privilegedRoles := map[string]bool{
-			"admin":               true,
-			"government_official": true,
-			"auditor":             true,
+		privilegedRoles := make(map[string]bool, len(bypassRoles))
+		for _, role := range bypassRoles {
+			privilegedRoles[role] = true
 		}
 
 		if privilegedRoles[identity.Role] {
@@ -570,13 +1325,63 @@ func CheckSelfAccess(identity *ClientIdentity, functionName string, targetIDHash
 	return nil
 }
 
+// CheckEmployerIdentity verifies that an employer caller is acting as the employer named
+// in a write. Unlike CheckSelfAccess this is strict: an employer certificate without an
+// idHash attribute cannot be matched and is denied. Other roles (admin, and any role the
+// function's rule admits in future) are not restricted here.
+func CheckEmployerIdentity(identity *ClientIdentity, functionName string, employerIDHash string) error {
+	if identity.Role != "employer" {
+		return nil
+	}
+	if callerHash := identity.Attributes["idHash"]; callerHash == "" || callerHash != employerIDHash {
+		return &AccessDeniedError{
+			Reason:     "Employers can only record wages under their own employerIDHash",
+			UserID:     identity.ID,
+			Function:   functionName,
+			RequiredBy: "Employer identity (idHash attribute)",
+		}
+	}
+	return nil
+}
+
+// fabricNodeOUs are the NodeOU classifiers Fabric CA adds to every certificate;
+// they identify the identity type rather than an affiliation
+var fabricNodeOUs = map[string]bool{
+	"client":  true,
+	"peer":    true,
+	"admin":   true,
+	"orderer": true,
+	"member":  true,
+}
+
+// resolveDepartment returns the department attribute when present. Otherwise it
+// derives the department from the certificate subject's OUs: Fabric CA encodes an
+// affiliation such as "org1.department1" as OU=client,OU=org1,OU=department1, so the
+// most specific (last) non-NodeOU value is used.
+func resolveDepartment(attribute string, attributeFound bool, cert *x509.Certificate) string {
+	if attributeFound {
+		return attribute
+	}
+	if cert == nil {
+		return ""
+	}
+	ous := cert.Subject.OrganizationalUnit
+	for i := len(ous) - 1; i >= 0; i-- {
+		ou := strings.TrimSpace(ous[i])
+		if ou != "" && !fabricNodeOUs[strings.ToLower(ou)] {
+			return ou
+		}
+	}
+	return ""
+}
+
 // ============================================================================
 // HELPER FUNCTIONS
 // ============================================================================
 
 // GetAttributeValue retrieves a single attribute from the certificate
 func GetAttributeValue(ctx contractapi.TransactionContextInterface, attrName string) (string, bool, error) {
-	return cid.GetAttributeValue(ctx.GetStub(), attrName)
+	return ctx.GetClientIdentity().GetAttributeValue(attrName)
 }
 
 // HasRole checks if the client has a specific role
@@ -613,17 +1418,17 @@ func HasPermission(ctx contractapi.TransactionContextInterface, permission strin
 
 // GetCallerMSPID returns the caller's MSP ID
 func GetCallerMSPID(ctx contractapi.TransactionContextInterface) (string, error) {
-	return cid.GetMSPID(ctx.GetStub())
+	return ctx.GetClientIdentity().GetMSPID()
 }
 
 // GetCallerID returns the caller's enrollment ID
 func GetCallerID(ctx contractapi.TransactionContextInterface) (string, error) {
-	return cid.GetID(ctx.GetStub())
+	return ctx.GetClientIdentity().GetID()
 }
 
 // IsOrgMember checks if the caller belongs to a specific organization
 func IsOrgMember(ctx contractapi.TransactionContextInterface, orgMSP string) (bool, error) {
-	mspID, err := cid.GetMSPID(ctx.GetStub())
+	mspID, err := ctx.GetClientIdentity().GetMSPID()
 	if err != nil {
 		return false, err
 	}
@@ -632,7 +1437,7 @@ func IsOrgMember(ctx contractapi.TransactionContextInterface, orgMSP string) (bo
 
 // AssertAttributeValue checks if an attribute has a specific value
 func AssertAttributeValue(ctx contractapi.TransactionContextInterface, attrName string, expectedValue string) error {
-	value, found, err := cid.GetAttributeValue(ctx.GetStub(), attrName)
+	value, found, err := ctx.GetClientIdentity().GetAttributeValue(attrName)
 	if err != nil {
 		return fmt.Errorf("failed to get attribute %s: %w", attrName, err)
 	}
@@ -645,6 +1450,25 @@ func AssertAttributeValue(ctx contractapi.TransactionContextInterface, attrName
 	return nil
 }
 
+// GetCallerScope resolves the caller's state and department scope with a single identity
+// lookup. Prefer it over calling GetStateFilter and GetDepartmentFilter separately.
+func GetCallerScope(ctx contractapi.TransactionContextInterface) (*CallerScope, error) {
+	identity, err := GetClientIdentity(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return scopeOf(identity), nil
+}
+
+// scopeOf builds a CallerScope from an identity that has already been resolved
+func scopeOf(identity *ClientIdentity) *CallerScope {
+	return &CallerScope{
+		State:      identity.State,
+		Department: identity.Department,
+		National:   identity.State == "",
+	}
+}
+
 // GetDepartmentFilter returns department-based data filter for the caller
 func GetDepartmentFilter(ctx contractapi.TransactionContextInterface) (string, error) {
 	identity, err := GetClientIdentity(ctx)
@@ -665,7 +1489,7 @@ func GetStateFilter(ctx contractapi.TransactionContextInterface) (string, error)
 
 // ValidateWageAmountLimit checks if wage amount is within employer's limit
 func ValidateWageAmountLimit(ctx contractapi.TransactionContextInterface, amount float64) error {
-	maxAmountStr, found, err := cid.GetAttributeValue(ctx.GetStub(), "maxWageAmount")
+	maxAmountStr, found, err := ctx.GetClientIdentity().GetAttributeValue("maxWageAmount")
 	if err != nil || !found {
 		return nil // No limit set
 	}
@@ -0,0 +1,588 @@
+package main
+
+import (
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func certWithOUs(ous ...string) *x509.Certificate {
+	return &x509.Certificate{Subject: pkix.Name{CommonName: "user1", OrganizationalUnit: ous}}
+}
+
+func TestResolveDepartmentPrefersAttribute(t *testing.T) {
+	got := resolveDepartment("labour", true, certWithOUs("client", "org1", "department1"))
+	if got != "labour" {
+		t.Fatalf("expected attribute department %q, got %q", "labour", got)
+	}
+}
+
+func TestResolveDepartmentEmptyAttributeIsKept(t *testing.T) {
+	// An explicitly empty attribute is still an explicit value; do not fall back to the OU
+	got := resolveDepartment("", true, certWithOUs("client", "department1"))
+	if got != "" {
+		t.Fatalf("expected empty department, got %q", got)
+	}
+}
+
+func TestResolveDepartmentFallsBackToOU(t *testing.T) {
+	tests := []struct {
+		name string
+		cert *x509.Certificate
+		want string
+	}{
+		{"affiliation OUs", certWithOUs("client", "org1", "department1"), "department1"},
+		{"single affiliation", certWithOUs("client", "welfare"), "welfare"},
+		{"node OU only", certWithOUs("client"), ""},
+		{"admin node OU", certWithOUs("admin"), ""},
+		{"node OU case-insensitive", certWithOUs("finance", "Client"), "finance"},
+		{"no OUs", certWithOUs(), ""},
+		{"no certificate", nil, ""},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := resolveDepartment("", false, tc.cert); got != tc.want {
+				t.Fatalf("expected %q, got %q", tc.want, got)
+			}
+		})
+	}
+}
+
+func identityWithHash(role string, idHash string) *ClientIdentity {
+	return &ClientIdentity{
+		ID:          role + "-user",
+		Role:        role,
+		Permissions: map[string]bool{},
+		Attributes:  map[string]string{"idHash": idHash},
+	}
+}
+
+func TestCheckSelfAccessDefaultBypassRoles(t *testing.T) {
+	defaults := splitConfigList(DefaultSelfAccessBypassRoles)
+
+	if err := checkSelfAccess(identityWithHash("worker", "worker-1"), "QueryWagesByWorker", "worker-1", defaults); err != nil {
+		t.Fatalf("worker should read own wages: %v", err)
+	}
+	if err := checkSelfAccess(identityWithHash("worker", "worker-1"), "QueryWagesByWorker", "worker-2", defaults); err == nil {
+		t.Fatal("worker must not read another worker's wages")
+	}
+	if err := checkSelfAccess(identityWithHash("bank_officer", "bank-1"), "QueryWagesByWorker", "worker-2", defaults); err == nil {
+		t.Fatal("bank_officer is not privileged by default and must be held to self-access")
+	}
+	for _, role := range []string{"admin", "government_official", "auditor"} {
+		if err := checkSelfAccess(identityWithHash(role, role+"-1"), "QueryWagesByWorker", "worker-2", defaults); err != nil {
+			t.Fatalf("%s should bypass self-access by default: %v", role, err)
+		}
+	}
+}
+
+func TestCheckSelfAccessConfiguredBypassRoles(t *testing.T) {
+	configured := splitConfigList("admin,government_official,bank_officer")
+
+	if err := checkSelfAccess(identityWithHash("bank_officer", "bank-1"), "QueryWagesByWorker", "worker-2", configured); err != nil {
+		t.Fatalf("configured bank_officer should bypass self-access: %v", err)
+	}
+	if err := checkSelfAccess(identityWithHash("auditor", "auditor-1"), "QueryWagesByWorker", "worker-2", configured); err == nil {
+		t.Fatal("auditor removed from the bypass list must be held to self-access")
+	}
+	if err := checkSelfAccess(identityWithHash("worker", "worker-1"), "QueryWagesByWorker", "worker-2", configured); err == nil {
+		t.Fatal("worker must still be held to self-access")
+	}
+}
+
+func TestValidateRoleList(t *testing.T) {
+	if err := validateRoleList(DefaultSelfAccessBypassRoles); err != nil {
+		t.Fatalf("default bypass roles should validate: %v", err)
+	}
+	if err := validateRoleList("admin,superuser"); err == nil {
+		t.Fatal("unknown role should be rejected")
+	}
+}
+
+// ============================================================================
+// IDENTITY AND CHECKACCESS (mock transaction context)
+// ============================================================================
+
+// denialReason returns the Reason of an *AccessDeniedError, failing the test for any other error
+func denialReason(t *testing.T, err error) string {
+	t.Helper()
+	var denied *AccessDeniedError
+	if !errors.As(err, &denied) {
+		t.Fatalf("expected *AccessDeniedError, got %v", err)
+	}
+	return denied.Reason
+}
+
+func TestGetClientIdentityDerivesRoleDefaults(t *testing.T) {
+	ctx := newMockContext("Org1MSP", "role=employer", "state=Karnataka", "idHash=emp-1")
+
+	identity, err := GetClientIdentity(ctx)
+	if err != nil {
+		t.Fatalf("GetClientIdentity: %v", err)
+	}
+	if identity.MSPID != "Org1MSP" || identity.Role != "employer" {
+		t.Fatalf("unexpected identity %+v", identity)
+	}
+	if identity.ClearanceLevel != 6 {
+		t.Fatalf("expected default employer clearance 6, got %d", identity.ClearanceLevel)
+	}
+	if !identity.Permissions["canRecordWage"] || !identity.Permissions["canBatchProcess"] {
+		t.Fatalf("employer should be granted wage permissions, got %v", identity.Permissions)
+	}
+	if identity.Permissions["canManageUsers"] {
+		t.Fatal("employer must not be granted canManageUsers")
+	}
+	if identity.State != "Karnataka" || identity.Attributes["idHash"] != "emp-1" {
+		t.Fatalf("state/idHash attributes not read: %+v", identity)
+	}
+	if identity.Department != "org1" {
+		t.Fatalf("expected department from certificate OU, got %q", identity.Department)
+	}
+}
+
+func TestGetClientIdentityExplicitAttributesOverrideDefaults(t *testing.T) {
+	ctx := newMockContext("Org1MSP", "role=employer", "clearanceLevel=3", "canRecordWage=false")
+
+	identity, err := GetClientIdentity(ctx)
+	if err != nil {
+		t.Fatalf("GetClientIdentity: %v", err)
+	}
+	if identity.ClearanceLevel != 3 {
+		t.Fatalf("expected explicit clearance 3, got %d", identity.ClearanceLevel)
+	}
+	if identity.Permissions["canRecordWage"] {
+		t.Fatal("explicit canRecordWage=false should override the role default")
+	}
+}
+
+func TestGetClientIdentityDetectsAdminOU(t *testing.T) {
+	identity, err := GetClientIdentity(newMockAdminContext("Org1MSP"))
+	if err != nil {
+		t.Fatalf("GetClientIdentity: %v", err)
+	}
+	if identity.Role != "admin" || identity.ClearanceLevel != 10 {
+		t.Fatalf("admin OU certificate should map to admin/10, got %q/%d", identity.Role, identity.ClearanceLevel)
+	}
+	if !identity.Permissions["canManageUsers"] || !identity.Permissions["canExport"] {
+		t.Fatalf("admin should hold every permission, got %v", identity.Permissions)
+	}
+	if identity.Department != "" {
+		t.Fatalf("admin node OU is not a department, got %q", identity.Department)
+	}
+}
+
+func TestGetClientIdentityAdminOUDoesNotOverrideRoleAttribute(t *testing.T) {
+	ctx := newMockAdminContext("Org1MSP")
+	ctx.identity.attrs["role"] = "auditor"
+
+	identity, err := GetClientIdentity(ctx)
+	if err != nil {
+		t.Fatalf("GetClientIdentity: %v", err)
+	}
+	if identity.Role != "auditor" {
+		t.Fatalf("explicit role attribute should win over the admin OU, got %q", identity.Role)
+	}
+}
+
+func TestGetClientIdentityPropagatesErrors(t *testing.T) {
+	ctx := newMockContext("Org1MSP", "role=employer")
+	ctx.identity.err = fmt.Errorf("no creator")
+
+	if _, err := GetClientIdentity(ctx); err == nil {
+		t.Fatal("expected error when the creator cannot be read")
+	}
+}
+
+func TestCheckAccessDenials(t *testing.T) {
+	tests := []struct {
+		name     string
+		ctx      *mockTransactionContext
+		function string
+		reason   string
+	}{
+		{"unknown function", newMockContext("Org1MSP", "role=admin"), "NoSuchFunction", "No access rule defined"},
+		{"foreign MSP", newMockContext("Org3MSP", "role=employer"), "RecordWage", "MSP 'Org3MSP' not allowed"},
+		{"missing role", newMockContext("Org1MSP"), "RecordWage", "No role attribute"},
+		{"role not allowed", newMockContext("Org1MSP", "role=worker"), "RecordWage", "Role 'worker' not allowed"},
+		{"clearance too low", newMockContext("Org1MSP", "role=employer", "clearanceLevel=3"), "RecordWage", "Clearance level 3 below required 5"},
+		{"permission revoked", newMockContext("Org1MSP", "role=employer", "canRecordWage=false"), "RecordWage", "Missing required permission: canRecordWage"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			identity, err := CheckAccess(tt.ctx, tt.function)
+			if identity != nil {
+				t.Fatalf("denied call returned identity %+v", identity)
+			}
+			if reason := denialReason(t, err); !strings.Contains(reason, tt.reason) {
+				t.Fatalf("expected reason containing %q, got %q", tt.reason, reason)
+			}
+		})
+	}
+}
+
+func TestCheckAccessGrants(t *testing.T) {
+	tests := []struct {
+		name     string
+		ctx      *mockTransactionContext
+		function string
+	}{
+		{"employer records wage", newMockContext("Org1MSP", "role=employer"), "RecordWage"},
+		{"employer from Org2", newMockContext("Org2MSP", "role=employer"), "RecordWage"},
+		{"admin OU records wage", newMockAdminContext("Org1MSP"), "RecordWage"},
+		{"worker reads wages", newMockContext("Org1MSP", "role=worker"), "QueryWagesByWorker"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			identity, err := CheckAccess(tt.ctx, tt.function)
+			if err != nil {
+				t.Fatalf("expected access, got %v", err)
+			}
+			if identity == nil {
+				t.Fatal("granted call returned nil identity")
+			}
+		})
+	}
+}
+
+func TestCheckAccessIdentityError(t *testing.T) {
+	ctx := newMockContext("Org1MSP", "role=employer")
+	ctx.identity.err = fmt.Errorf("no creator")
+
+	_, err := CheckAccess(ctx, "RecordWage")
+	if err == nil {
+		t.Fatal("expected error")
+	}
+	var denied *AccessDeniedError
+	if errors.As(err, &denied) {
+		t.Fatal("identity failures should not be reported as a policy denial")
+	}
+}
+
+func TestCheckClearanceBand(t *testing.T) {
+	rule := AccessRule{MinClearanceLevel: 3, MaxClearanceLevel: 5}
+	tests := []struct {
+		name      string
+		clearance int
+		reason    string
+	}{
+		{"below min", 2, "below required 3"},
+		{"at min", 3, ""},
+		{"in band", 4, ""},
+		{"at max", 5, ""},
+		{"above max", 6, "above allowed maximum 5"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkClearance(&ClientIdentity{ID: "user", ClearanceLevel: tt.clearance}, "TrainingFunction", rule)
+			if tt.reason == "" {
+				if err != nil {
+					t.Fatalf("expected access, got %v", err)
+				}
+				return
+			}
+			if reason := denialReason(t, err); !strings.Contains(reason, tt.reason) {
+				t.Fatalf("expected reason containing %q, got %q", tt.reason, reason)
+			}
+		})
+	}
+
+	if err := checkClearance(&ClientIdentity{ClearanceLevel: 10}, "RecordWage", AccessRule{MinClearanceLevel: 5}); err != nil {
+		t.Fatalf("a zero MaxClearanceLevel must mean no ceiling: %v", err)
+	}
+}
+
+func TestCheckSelfAccessUsesConfiguredBypassRoles(t *testing.T) {
+	ctx := newMockContext("Org1MSP", "role=bank_officer", "idHash=bank-1")
+	identity, err := CheckAccess(ctx, "QueryWagesByWorker")
+	if err != nil {
+		t.Fatalf("CheckAccess: %v", err)
+	}
+
+	if err := CheckSelfAccess(ctx, identity, "QueryWagesByWorker", "worker-2"); err == nil {
+		t.Fatal("bank_officer is held to self-access under the default config")
+	}
+
+	if err := putConfigValue(ctx, ConfigSelfAccessBypassRoles, "admin,bank_officer", "test"); err != nil {
+		t.Fatalf("putConfigValue: %v", err)
+	}
+	if err := CheckSelfAccess(ctx, identity, "QueryWagesByWorker", "worker-2"); err != nil {
+		t.Fatalf("configured bank_officer should bypass self-access: %v", err)
+	}
+}
+
+func TestCheckSelfAccessOwnData(t *testing.T) {
+	ctx := newMockContext("Org1MSP", "role=worker", "idHash=worker-1")
+	identity, err := CheckAccess(ctx, "QueryWagesByWorker")
+	if err != nil {
+		t.Fatalf("CheckAccess: %v", err)
+	}
+
+	if err := CheckSelfAccess(ctx, identity, "QueryWagesByWorker", "worker-1"); err != nil {
+		t.Fatalf("worker should read own wages: %v", err)
+	}
+	if reason := denialReason(t, CheckSelfAccess(ctx, identity, "QueryWagesByWorker", "worker-2")); reason != "Can only access own data" {
+		t.Fatalf("unexpected reason %q", reason)
+	}
+}
+
+func TestCheckEmployerIdentity(t *testing.T) {
+	if err := CheckEmployerIdentity(identityWithHash("employer", "employer-1"), "RecordWage", "employer-1"); err != nil {
+		t.Fatalf("employer should record own wages: %v", err)
+	}
+	if reason := denialReason(t, CheckEmployerIdentity(identityWithHash("employer", "employer-1"), "RecordWage", "employer-2")); reason != "Employers can only record wages under their own employerIDHash" {
+		t.Fatalf("unexpected reason %q", reason)
+	}
+	if err := CheckEmployerIdentity(identityWithHash("employer", ""), "RecordWage", "employer-2"); err == nil {
+		t.Fatal("employer without idHash must be denied")
+	}
+	if err := CheckEmployerIdentity(identityWithHash("admin", ""), "RecordWage", "employer-2"); err != nil {
+		t.Fatalf("admin should record for any employer: %v", err)
+	}
+}
+
+func TestGetCallerScope(t *testing.T) {
+	scope, err := GetCallerScope(newMockContext("Org1MSP", "role=government_official", "state=KA", "department=labour"))
+	if err != nil {
+		t.Fatalf("GetCallerScope: %v", err)
+	}
+	if scope.State != "KA" || scope.Department != "labour" || scope.National {
+		t.Fatalf("unexpected scope %+v", scope)
+	}
+	if !scope.AllowsState("KA") || scope.AllowsState("MH") {
+		t.Fatal("state official must be limited to own state")
+	}
+
+	scope, err = GetCallerScope(newMockContext("Org1MSP", "role=government_official"))
+	if err != nil {
+		t.Fatalf("GetCallerScope: %v", err)
+	}
+	if !scope.National || !scope.AllowsState("MH") {
+		t.Fatalf("caller without state attribute should be national: %+v", scope)
+	}
+}
+
+func TestCheckRequiredAttributes(t *testing.T) {
+	required := map[string]string{"program": "MGNREGA", "district": "Mysuru"}
+
+	ctx := newMockContext("Org1MSP", "role=government_official", "program=MGNREGA", "district=Mysuru")
+	identity, err := GetClientIdentity(ctx)
+	if err != nil {
+		t.Fatalf("GetClientIdentity: %v", err)
+	}
+	if err := checkRequiredAttributes(ctx, identity, "Example", required); err != nil {
+		t.Fatalf("matching attributes should pass: %v", err)
+	}
+
+	ctx = newMockContext("Org1MSP", "role=government_official", "program=PMKVY", "district=Mysuru")
+	if reason := denialReason(t, checkRequiredAttributes(ctx, identity, "Example", required)); reason != "Attribute program is 'PMKVY', required 'MGNREGA'" {
+		t.Fatalf("unexpected reason %q", reason)
+	}
+
+	ctx = newMockContext("Org1MSP", "role=government_official", "program=MGNREGA")
+	if reason := denialReason(t, checkRequiredAttributes(ctx, identity, "Example", required)); reason != "Missing required attribute: district" {
+		t.Fatalf("unexpected reason %q", reason)
+	}
+
+	if err := checkRequiredAttributes(ctx, identity, "Example", nil); err != nil {
+		t.Fatalf("no required attributes should pass: %v", err)
+	}
+}
+
+func TestCheckMSPRoles(t *testing.T) {
+	allowed := map[string][]string{
+		"Org1MSP": {"admin", "government_official"},
+		"Org2MSP": {"auditor"},
+	}
+
+	for _, tc := range []struct{ msp, role string }{
+		{"Org1MSP", "admin"},
+		{"Org1MSP", "government_official"},
+		{"Org2MSP", "auditor"},
+	} {
+		identity := &ClientIdentity{ID: "user1", MSPID: tc.msp, Role: tc.role}
+		if err := checkMSPRoles(identity, "Example", allowed); err != nil {
+			t.Errorf("%s/%s should be allowed: %v", tc.msp, tc.role, err)
+		}
+	}
+
+	tests := []struct{ msp, role, reason string }{
+		{"Org2MSP", "admin", "Role 'admin' not allowed for MSP 'Org2MSP'"},
+		{"Org1MSP", "auditor", "Role 'auditor' not allowed for MSP 'Org1MSP'"},
+		{"Org1MSP", "", "Role '' not allowed for MSP 'Org1MSP'"},
+		{"Org3MSP", "admin", "MSP 'Org3MSP' not allowed"},
+	}
+	for _, tc := range tests {
+		identity := &ClientIdentity{ID: "user1", MSPID: tc.msp, Role: tc.role}
+		if reason := denialReason(t, checkMSPRoles(identity, "Example", allowed)); reason != tc.reason {
+			t.Errorf("%s/%s: unexpected reason %q", tc.msp, tc.role, reason)
+		}
+	}
+}
+
+func TestCheckSelfAccessClearanceBypass(t *testing.T) {
+	ctx := newMockContext("Org1MSP", "role=bank_officer", "idHash=bank-1", "clearanceLevel=8")
+	identity, err := GetClientIdentity(ctx)
+	if err != nil {
+		t.Fatalf("GetClientIdentity: %v", err)
+	}
+
+	if err := CheckSelfAccess(ctx, identity, "QueryWagesByWorker", "worker-2"); err == nil {
+		t.Fatal("clearance bypass must be off by default")
+	}
+
+	if err := putConfigValue(ctx, ConfigSelfAccessBypassClearance, "8", "test"); err != nil {
+		t.Fatalf("putConfigValue: %v", err)
+	}
+	if err := CheckSelfAccess(ctx, identity, "QueryWagesByWorker", "worker-2"); err != nil {
+		t.Fatalf("clearance 8 should bypass self-access: %v", err)
+	}
+
+	identity.ClearanceLevel = 7
+	if err := CheckSelfAccess(ctx, identity, "QueryWagesByWorker", "worker-2"); err == nil {
+		t.Fatal("clearance below the threshold must be held to self-access")
+	}
+
+	if err := validateClearanceThreshold("11"); err == nil {
+		t.Fatal("expected out-of-range threshold to be rejected")
+	}
+}
+
+// auditEntries counts the audit logs in the mock world state
+func auditEntries(ctx *mockTransactionContext) int {
+	count := 0
+	for key := range ctx.stub.state {
+		if strings.HasPrefix(key, "AUDIT_") {
+			count++
+		}
+	}
+	return count
+}
+
+func TestTransactionHooks(t *testing.T) {
+	s := &SmartContract{}
+
+	ctx := newMockContext("Org1MSP", "role=worker", "idHash=worker-1")
+	ctx.stub.txID = "hooktx-denied"
+	ctx.stub.function = "tracient:GetAuditLogs"
+	if err := s.beforeTransaction(ctx); err == nil {
+		t.Fatal("worker must be denied GetAuditLogs")
+	}
+	if auditEntries(ctx) != 1 {
+		t.Fatalf("expected the denial to be audited, got %d entries", auditEntries(ctx))
+	}
+
+	ctx = newMockContext("Org1MSP", "role=worker", "idHash=worker-1")
+	ctx.stub.txID = "hooktx-log"
+	ctx.stub.function = "LogAccess"
+	if err := s.beforeTransaction(ctx); err == nil {
+		t.Fatal("functions without an access rule must be denied")
+	}
+
+	ctx = newMockContext("Org1MSP", "role=worker", "idHash=worker-1")
+	ctx.stub.txID = "hooktx-health"
+	ctx.stub.function = "GetHealth"
+	if err := s.beforeTransaction(ctx); err != nil {
+		t.Fatalf("GetHealth is public: %v", err)
+	}
+	if err := s.afterTransaction(ctx); err != nil || auditEntries(ctx) != 0 {
+		t.Fatalf("public transactions are not audited: err=%v entries=%d", err, auditEntries(ctx))
+	}
+
+	ctx = newMockContext("Org1MSP", "role=auditor", "clearanceLevel=8")
+	ctx.stub.txID = "hooktx-granted"
+	ctx.stub.function = "GetAuditLogs"
+	if err := s.beforeTransaction(ctx); err != nil {
+		t.Fatalf("auditor may call GetAuditLogs: %v", err)
+	}
+	if err := s.afterTransaction(ctx); err != nil || auditEntries(ctx) != 1 {
+		t.Fatalf("expected one ACCESS_GRANTED entry: err=%v entries=%d", err, auditEntries(ctx))
+	}
+
+	// A function that audited itself does not get a second entry
+	ctx = newMockContext("Org1MSP", "role=auditor", "clearanceLevel=8")
+	ctx.stub.txID = "hooktx-self-audited"
+	ctx.stub.function = "GetAuditLogs"
+	s.LogDataRead(ctx, "GetAuditLogs", "", "wage")
+	if err := s.afterTransaction(ctx); err != nil || auditEntries(ctx) != 1 {
+		t.Fatalf("expected no extra entry: err=%v entries=%d", err, auditEntries(ctx))
+	}
+
+	// Re-running that transaction on a fresh context does not inherit the earlier flag
+	ctx = newMockContext("Org1MSP", "role=auditor", "clearanceLevel=8")
+	ctx.stub.txID = "hooktx-self-audited"
+	ctx.stub.function = "GetAuditLogs"
+	if err := s.afterTransaction(ctx); err != nil || auditEntries(ctx) != 1 {
+		t.Fatalf("expected one ACCESS_GRANTED entry: err=%v entries=%d", err, auditEntries(ctx))
+	}
+
+	ctx = newMockContext("Org1MSP", "role=auditor", "clearanceLevel=8")
+	ctx.stub.txID = "hooktx-paginated"
+	ctx.stub.function = "GetAccessDenialsPage"
+	if err := s.afterTransaction(ctx); err != nil || auditEntries(ctx) != 0 {
+		t.Fatalf("paginated transactions must not write: err=%v entries=%d", err, auditEntries(ctx))
+	}
+}
+
+func TestHighRiskEventLevel(t *testing.T) {
+	ctx := newMockContext("Org1MSP", "role=worker", "idHash=worker-1")
+	s := &SmartContract{}
+	emitted := func(function string) bool {
+		delete(ctx.stub.events, "HighRiskActivity")
+		s.LogAccessDenied(ctx, function, "", "wage", "denied")
+		_, ok := ctx.stub.events["HighRiskActivity"]
+		return ok
+	}
+
+	// Default: every high and critical entry emits
+	for i := 0; i < 3; i++ {
+		if !emitted("ReadWage") {
+			t.Fatalf("denial %d: expected an event by default", i)
+		}
+	}
+	for key := range ctx.stub.state {
+		if strings.HasPrefix(key, "COUNTER_") {
+			t.Fatalf("event emission must not write counters, found %s", key)
+		}
+	}
+
+	if err := putConfigValue(ctx, ConfigHighRiskEventLevel, EventLevelCritical, "test"); err != nil {
+		t.Fatal(err)
+	}
+	if emitted("ReadWage") {
+		t.Fatal("high-risk events must not emit at CRITICAL level")
+	}
+	if !emitted("SetConfig") {
+		t.Fatal("critical events must emit at CRITICAL level")
+	}
+}
+
+func TestValidateAccessRules(t *testing.T) {
+	if problems := validateAccessRules(GetAccessRules()); len(problems) != 0 {
+		t.Fatalf("compiled access rules are misconfigured:\n%s", strings.Join(problems, "\n"))
+	}
+
+	problems := validateAccessRules(map[string]AccessRule{
+		"ReadWage":      {AllowedRoles: []string{"worker", "auditer"}, MinClearanceLevel: 50, AllowedMSPs: []string{"Org1MSP"}},
+		"NoSuchFn":      {AllowedRoles: []string{"admin"}, MinClearanceLevel: 1, AllowedMSPs: []string{"Org1MSP"}},
+		"GetHealth":     {AllowedMSPRoles: map[string][]string{"Org1MSP": {}}, MinClearanceLevel: 1},
+		"ListFunctions": {AllowedRoles: []string{"admin"}, MinClearanceLevel: 5, MaxClearanceLevel: 3},
+	})
+	want := []string{
+		"GetHealth: AllowedMSPRoles[Org1MSP] allows no roles",
+		"ListFunctions: AllowedMSPs is empty",
+		"ListFunctions: MaxClearanceLevel 3 outside 5-10",
+		"NoSuchFn: no such transaction function",
+		"ReadWage: MinClearanceLevel 50 outside 1-10",
+		`ReadWage: unknown role "auditer"`,
+	}
+	if strings.Join(problems, "\n") != strings.Join(want, "\n") {
+		t.Fatalf("problems:\n%s\nwant:\n%s", strings.Join(problems, "\n"), strings.Join(want, "\n"))
+	}
+}
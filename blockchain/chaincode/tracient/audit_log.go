@@ -3,6 +3,7 @@ package main
 import (
 	"encoding/json"
 	"fmt"
+	"sort"
 	"time"
 
 	"github.com/hyperledger/fabric-contract-api-go/v2/contractapi"
@@ -29,6 +30,10 @@ type AuditLog struct {
 	TxID         string `json:"txId"`         // Fabric transaction ID
 	IPAddress    string `json:"ipAddress"`    // If available from client
 	RiskLevel    string `json:"riskLevel"`    // low, medium, high, critical
+
+	// CallerAttributes holds the caller's certificate attributes named in the
+	// auditCaptureAttributes config; attributes outside that whitelist are never stored
+	CallerAttributes map[string]string `json:"callerAttributes,omitempty"`
 }
 
 // AuditQuery represents query parameters for audit log retrieval
@@ -41,6 +46,23 @@ type AuditQuery struct {
 	Status      string   `json:"status"`
 	RiskLevel   string   `json:"riskLevel"`
 	Limit       int      `json:"limit"`
+
+	// UntilLogID bounds GetAuditLogsChunk to logs up to and including this ID
+	UntilLogID string `json:"untilLogId,omitempty"`
+
+	// CallerAttributes matches logs whose captured caller attributes have these values;
+	// an empty value matches any log where the attribute was present
+	CallerAttributes map[string]string `json:"callerAttributes,omitempty"`
+}
+
+// AuditChunk is one bounded slice of an audit log export
+type AuditChunk struct {
+	ChunkIndex    int         `json:"chunkIndex"`
+	ChunkSize     int         `json:"chunkSize"`
+	TotalChunks   int         `json:"totalChunks"` // Hint; may grow if untilLogId is not pinned
+	TotalMatches  int         `json:"totalMatches"`
+	SnapshotLogID string      `json:"snapshotLogId"` // Pass as untilLogId for consistent later chunks
+	Logs          []*AuditLog `json:"logs"`
 }
 
 // AuditSummary represents aggregated audit statistics
@@ -55,6 +77,35 @@ type AuditSummary struct {
 	Period            string         `json:"period"`
 }
 
+// TimeBucket is one interval of an activity time series: audit events (GetAuditTimeSeries)
+// or user registrations (GetUserRegistrationTrend)
+type TimeBucket struct {
+	Start        string         `json:"start"` // First day in the bucket (YYYY-MM-DD)
+	End          string         `json:"end"`   // Last day in the bucket, inclusive
+	TotalEvents  int            `json:"totalEvents"`
+	DeniedCount  int            `json:"deniedCount"`
+	EventsByType map[string]int `json:"eventsByType"`
+}
+
+// SuspiciousPattern describes a caller with an unusual burst of access denials
+type SuspiciousPattern struct {
+	CallerID    string   `json:"callerId"`
+	CallerMSP   string   `json:"callerMsp"`
+	DenialCount int      `json:"denialCount"`
+	Threshold   int      `json:"threshold"`
+	FirstSeen   string   `json:"firstSeen"`
+	LastSeen    string   `json:"lastSeen"`
+	Functions   []string `json:"functions"` // Distinct functions the caller was denied on
+}
+
+// MSPStats aggregates audit activity for one organization MSP
+type MSPStats struct {
+	Writes  int `json:"writes"`
+	Reads   int `json:"reads"`
+	Denials int `json:"denials"`
+	Total   int `json:"total"`
+}
+
 // ============================================================================
 // EVENT TYPES
 // ============================================================================
@@ -83,9 +134,15 @@ const (
 	EventThresholdChanged = "THRESHOLD_CHANGED"
 	EventReportGenerated = "REPORT_GENERATED"
 
+	// Governance Events
+	EventRoleDrift        = "ROLE_DRIFT"
+	EventBlockedValue     = "BLOCKED_VALUE"     // A write used a deny-listed currency or job type
+	EventQuarantinedWrite = "QUARANTINED_WRITE" // A write targeted a quarantined state
+
 	// System Events
 	EventLedgerInitialized = "LEDGER_INITIALIZED"
 	EventConfigChanged = "CONFIG_CHANGED"
+	EventTransactionPanic  = "TRANSACTION_PANIC"
 )
 
 // ============================================================================
@@ -107,6 +164,11 @@ func DetermineRiskLevel(eventType string, function string, status string) string
 		"UpdateUserStatus":    true,
 		"RegisterUser":        true,
 		"InitLedger":          true,
+		"ForceInitLedger":     true,
+		"SetConfig":           true,
+		"AddWorkerAlias":      true,
+		"QuarantineState":     true,
+		"LiftQuarantine":      true,
 	}
 
 	// Medium-risk functions
@@ -127,6 +189,11 @@ func DetermineRiskLevel(eventType string, function string, status string) string
 		return RiskHigh
 	}
 
+	// A certificate role that disagrees with the registry is a governance finding
+	if eventType == EventRoleDrift || eventType == EventBlockedValue || eventType == EventQuarantinedWrite {
+		return RiskHigh
+	}
+
 	// Check by function
 	if highRiskFunctions[function] {
 		return RiskHigh
@@ -142,6 +209,44 @@ func DetermineRiskLevel(eventType string, function string, status string) string
 // AUDIT LOGGING FUNCTIONS
 // ============================================================================
 
+// shouldPersistAuditEvent applies the auditLevel config. Denials and high-risk events
+// are always persisted; an unreadable config falls back to persisting everything.
+func shouldPersistAuditEvent(ctx contractapi.TransactionContextInterface, eventType string, status string, riskLevel string) bool {
+	if status == "denied" || eventType == EventAccessDenied || riskLevel == RiskHigh || riskLevel == RiskCritical {
+		return true
+	}
+
+	level, err := getConfigValue(ctx, ConfigAuditLevel)
+	if err != nil {
+		return true
+	}
+
+	switch level {
+	case AuditLevelWritesOnly:
+		return eventType != EventDataRead
+	case AuditLevelDenialsOnly:
+		return false
+	default:
+		return true
+	}
+}
+
+// shouldEmitHighRiskEvent decides whether LogAccess emits the HighRiskActivity event for an
+// entry: for high and critical entries by default, or only critical ones when the
+// highRiskEventLevel config is CRITICAL. Rate limiting belongs in the off-chain event
+// listener; an on-chain counter would be a hot key and is never committed for the denied
+// transactions it is meant to catch.
+func shouldEmitHighRiskEvent(ctx contractapi.TransactionContextInterface, riskLevel string) bool {
+	switch riskLevel {
+	case RiskCritical:
+		return true
+	case RiskHigh:
+		level, err := getConfigValue(ctx, ConfigHighRiskEventLevel)
+		return err != nil || level != EventLevelCritical
+	}
+	return false
+}
+
 // LogAccess creates an audit log entry for an access event
 func (s *SmartContract) LogAccess(ctx contractapi.TransactionContextInterface, eventType string, function string, targetID string, targetType string, status string, details string) error {
 	// Get caller identity
@@ -159,8 +264,12 @@ func (s *SmartContract) LogAccess(ctx contractapi.TransactionContextInterface, e
 	// Determine risk level
 	riskLevel := DetermineRiskLevel(eventType, function, status)
 
-	// Generate unique log ID using deterministic transaction timestamp
-	// This ensures all peers produce the same log entry
+	// Respect the configured audit verbosity
+	if !shouldPersistAuditEvent(ctx, eventType, status, riskLevel) {
+		return nil
+	}
+
+	// Log IDs start with the transaction timestamp so AUDIT_ range scans run in time order
 	txTimestamp, err := ctx.GetStub().GetTxTimestamp()
 	var timestamp time.Time
 	if err != nil || txTimestamp == nil {
@@ -170,7 +279,7 @@ func (s *SmartContract) LogAccess(ctx contractapi.TransactionContextInterface, e
 		timestamp = time.Unix(txTimestamp.GetSeconds(), int64(txTimestamp.GetNanos())).UTC()
 	}
 	txID := ctx.GetStub().GetTxID()
-	logID := fmt.Sprintf("AUDIT_%s_%s", timestamp.Format("20060102150405"), txID[:8])
+	logID := generateDeterministicID(ctx, "AUDIT_"+timestamp.Format("20060102150405"))
 
 	auditLog := AuditLog{
 		DocType:    "audit_log",
@@ -187,9 +296,11 @@ func (s *SmartContract) LogAccess(ctx contractapi.TransactionContextInterface, e
 		Details:    details,
 		TxID:       txID,
 		RiskLevel:  riskLevel,
+
+		CallerAttributes: captureCallerAttributes(ctx),
 	}
 
-	payload, err := json.Marshal(auditLog)
+	payload, err := marshalState(auditLog)
 	if err != nil {
 		return fmt.Errorf("marshal audit log: %w", err)
 	}
@@ -198,10 +309,22 @@ func (s *SmartContract) LogAccess(ctx contractapi.TransactionContextInterface, e
 	if err := ctx.GetStub().PutState(logID, payload); err != nil {
 		return fmt.Errorf("store audit log: %w", err)
 	}
+	txStateOf(ctx).audited = true
+
+	// Index by target so GetAuditTrailForTarget avoids scanning the whole audit space
+	if targetID != "" {
+		indexKey, err := ctx.GetStub().CreateCompositeKey("audit~target", []string{targetID, logID})
+		if err != nil {
+			return fmt.Errorf("create audit target index: %w", err)
+		}
+		if err := ctx.GetStub().PutState(indexKey, []byte{0x00}); err != nil {
+			return fmt.Errorf("store audit target index: %w", err)
+		}
+	}
 
 	// Emit event for high-risk activities
-	if riskLevel == RiskHigh || riskLevel == RiskCritical {
-		eventData, _ := json.Marshal(map[string]string{
+	if shouldEmitHighRiskEvent(ctx, riskLevel) {
+		eventData, _ := marshalState(map[string]string{
 			"logId":     logID,
 			"eventType": eventType,
 			"riskLevel": riskLevel,
@@ -214,6 +337,28 @@ func (s *SmartContract) LogAccess(ctx contractapi.TransactionContextInterface, e
 	return nil
 }
 
+// captureCallerAttributes returns the caller's values for the attributes whitelisted in
+// the auditCaptureAttributes config, or nil when none are configured or present
+func captureCallerAttributes(ctx contractapi.TransactionContextInterface) map[string]string {
+	names, err := getConfigList(ctx, ConfigAuditCaptureAttributes)
+	if err != nil || len(names) == 0 {
+		return nil
+	}
+
+	var captured map[string]string
+	for _, name := range names {
+		value, found, err := ctx.GetClientIdentity().GetAttributeValue(name)
+		if err != nil || !found {
+			continue
+		}
+		if captured == nil {
+			captured = make(map[string]string)
+		}
+		captured[name] = value
+	}
+	return captured
+}
+
 // LogAccessGranted logs a successful access
 func (s *SmartContract) LogAccessGranted(ctx contractapi.TransactionContextInterface, function string, targetID string, targetType string) error {
 	return s.LogAccess(ctx, EventAccessGranted, function, targetID, targetType, "success", "Access granted")
@@ -238,15 +383,34 @@ func (s *SmartContract) LogDataWrite(ctx contractapi.TransactionContextInterface
 // AUDIT QUERY FUNCTIONS
 // ============================================================================
 
-// GetAuditLogs retrieves audit logs based on query parameters
+// GetAuditLogs retrieves audit logs based on query parameters, oldest first
 func (s *SmartContract) GetAuditLogs(ctx contractapi.TransactionContextInterface, queryJSON string) ([]*AuditLog, error) {
-	// Check access - only admins and auditors can view audit logs
-	identity, err := CheckAccess(ctx, "GetFlaggedWages") // Using similar permission level
+	// Check access - only admins, auditors, and government officials can view audit logs
+	identity, err := CheckAccess(ctx, "GetAuditLogs")
 	if err != nil {
 		s.LogAccessDenied(ctx, "GetAuditLogs", "", "audit_log", err.Error())
 		return nil, err
 	}
 
+	logs, err := queryAuditLogs(ctx, queryJSON)
+	if err != nil {
+		return nil, err
+	}
+
+	// Log this access
+	s.LogDataRead(ctx, "GetAuditLogs", fmt.Sprintf("count:%d", len(logs)), "audit_log")
+
+	// Also log who accessed audit logs
+	fmt.Printf("[AUDIT ACCESS] User %s (role: %s) accessed %d audit log entries\n",
+		identity.ID, identity.Role, len(logs))
+
+	return logs, nil
+}
+
+// queryAuditLogs returns the audit logs matching queryJSON without access checks, oldest
+// first. The scan stops at the query limit, so later matches are cut off.
+// Callers are responsible for having authorized the query.
+func queryAuditLogs(ctx contractapi.TransactionContextInterface, queryJSON string) ([]*AuditLog, error) {
 	var query AuditQuery
 	if queryJSON != "" {
 		if err := json.Unmarshal([]byte(queryJSON), &query); err != nil {
@@ -279,68 +443,164 @@ func (s *SmartContract) GetAuditLogs(ctx contractapi.TransactionContextInterface
 		}
 
 		// Apply filters
-		if query.CallerID != "" && log.CallerID != query.CallerID {
+		if !matchesAuditQuery(&log, &query) {
 			continue
 		}
-		if query.TargetID != "" && log.TargetID != query.TargetID {
-			continue
-		}
-		if query.Status != "" && log.Status != query.Status {
-			continue
+
+		logs = append(logs, &log)
+
+		// Apply limit
+		if len(logs) >= query.Limit {
+			break
 		}
-		if query.RiskLevel != "" && log.RiskLevel != query.RiskLevel {
-			continue
+	}
+
+	sortAuditLogsChronologically(logs)
+
+	return logs, nil
+}
+
+// sortAuditLogsChronologically orders logs oldest first by timestamp, then log ID. Log IDs
+// begin with the timestamp, so this matches AUDIT_ key order for current entries while
+// keeping a defined order for index lookups and older ID formats.
+func sortAuditLogsChronologically(logs []*AuditLog) {
+	sort.Slice(logs, func(i, j int) bool {
+		return chronologicallyBefore(logs[i].Timestamp, logs[i].LogID, logs[j].Timestamp, logs[j].LogID)
+	})
+}
+
+// matchesAuditQuery reports whether a log entry passes the AuditQuery filters
+func matchesAuditQuery(log *AuditLog, query *AuditQuery) bool {
+	if query.CallerID != "" && log.CallerID != query.CallerID {
+		return false
+	}
+	if query.TargetID != "" && log.TargetID != query.TargetID {
+		return false
+	}
+	if query.Status != "" && log.Status != query.Status {
+		return false
+	}
+	if query.RiskLevel != "" && log.RiskLevel != query.RiskLevel {
+		return false
+	}
+	for name, want := range query.CallerAttributes {
+		value, present := log.CallerAttributes[name]
+		if !present || (want != "" && value != want) {
+			return false
 		}
+	}
 
-		// Date range filter
-		if query.StartDate != "" && query.EndDate != "" {
-			logTime, err := time.Parse(time.RFC3339, log.Timestamp)
-			if err != nil {
-				continue
-			}
-			start, _ := time.Parse("2006-01-02", query.StartDate)
-			end, _ := time.Parse("2006-01-02", query.EndDate)
-			if logTime.Before(start) || logTime.After(end.Add(24*time.Hour)) {
-				continue
-			}
+	// Date range filter
+	if query.StartDate != "" && query.EndDate != "" {
+		logTime, err := time.Parse(time.RFC3339, log.Timestamp)
+		if err != nil {
+			return false
 		}
+		start, _ := time.Parse("2006-01-02", query.StartDate)
+		end, _ := time.Parse("2006-01-02", query.EndDate)
+		if logTime.Before(start) || logTime.After(end.Add(24*time.Hour)) {
+			return false
+		}
+	}
 
-		// Event type filter
-		if len(query.EventTypes) > 0 {
-			found := false
-			for _, et := range query.EventTypes {
-				if log.EventType == et {
-					found = true
-					break
-				}
-			}
-			if !found {
-				continue
+	// Event type filter
+	if len(query.EventTypes) > 0 {
+		found := false
+		for _, et := range query.EventTypes {
+			if log.EventType == et {
+				found = true
+				break
 			}
 		}
+		if !found {
+			return false
+		}
+	}
 
-		logs = append(logs, &log)
+	return true
+}
 
-		// Apply limit
-		if len(logs) >= query.Limit {
-			break
+// GetAuditLogsChunk returns one bounded chunk of the audit logs matching queryJSON (the
+// AuditQuery filters, with limit as the chunk size), so a full export can be assembled
+// across calls without exceeding Fabric's response size limit. Chunks are in log ID
+// (i.e. chronological) order; chunkIndex starts at 0.
+// Consistency: the log keeps growing between calls. Pass the SnapshotLogID returned with
+// chunk 0 as untilLogId in the query for later chunks, so entries added meanwhile are
+// excluded and chunk boundaries do not shift. Fetch chunks with evaluate (query) calls;
+// submitted calls append their own audit entries.
+func (s *SmartContract) GetAuditLogsChunk(ctx contractapi.TransactionContextInterface, queryJSON string, chunkIndex int) (*AuditChunk, error) {
+	// Check access - only auditors, government officials, and admins can export audit logs
+	_, err := CheckAccess(ctx, "GetAuditLogsChunk")
+	if err != nil {
+		s.LogAccessDenied(ctx, "GetAuditLogsChunk", "", "audit_log", err.Error())
+		return nil, fmt.Errorf("access denied: %w", err)
+	}
+
+	if chunkIndex < 0 {
+		return nil, fmt.Errorf("chunkIndex must not be negative")
+	}
+
+	var query AuditQuery
+	if queryJSON != "" {
+		if err := json.Unmarshal([]byte(queryJSON), &query); err != nil {
+			return nil, fmt.Errorf("invalid query parameters: %w", err)
 		}
 	}
+	if query.Limit <= 0 || query.Limit > 1000 {
+		query.Limit = 100
+	}
 
-	// Log this access
-	s.LogDataRead(ctx, "GetAuditLogs", fmt.Sprintf("count:%d", len(logs)), "audit_log")
+	// The range end is exclusive, so step just past untilLogId to include it
+	endKey := "AUDIT_~"
+	if query.UntilLogID != "" {
+		endKey = query.UntilLogID + "\x00"
+	}
+	iterator, err := ctx.GetStub().GetStateByRange("AUDIT_", endKey)
+	if err != nil {
+		return nil, fmt.Errorf("get audit logs: %w", err)
+	}
+	defer iterator.Close()
 
-	// Also log who accessed audit logs
-	fmt.Printf("[AUDIT ACCESS] User %s (role: %s) accessed %d audit log entries\n",
-		identity.ID, identity.Role, len(logs))
+	chunk := &AuditChunk{
+		ChunkIndex:    chunkIndex,
+		ChunkSize:     query.Limit,
+		Logs:          []*AuditLog{},
+		SnapshotLogID: query.UntilLogID,
+	}
+	first := chunkIndex * query.Limit
+	for iterator.HasNext() {
+		queryResponse, err := iterator.Next()
+		if err != nil {
+			return nil, fmt.Errorf("iterate: %w", err)
+		}
+		if query.UntilLogID == "" {
+			chunk.SnapshotLogID = queryResponse.Key
+		}
 
-	return logs, nil
+		var log AuditLog
+		if err := json.Unmarshal(queryResponse.Value, &log); err != nil {
+			continue
+		}
+		if !matchesAuditQuery(&log, &query) {
+			continue
+		}
+
+		if chunk.TotalMatches >= first && len(chunk.Logs) < query.Limit {
+			chunk.Logs = append(chunk.Logs, &log)
+		}
+		chunk.TotalMatches++
+	}
+	chunk.TotalChunks = (chunk.TotalMatches + query.Limit - 1) / query.Limit
+
+	s.LogDataRead(ctx, "GetAuditLogsChunk", fmt.Sprintf("chunk:%d", chunkIndex), "audit_log")
+
+	return chunk, nil
 }
 
 // GetAuditSummary generates an aggregated summary of audit logs
 func (s *SmartContract) GetAuditSummary(ctx contractapi.TransactionContextInterface, startDate string, endDate string) (*AuditSummary, error) {
 	// Check access
-	_, err := CheckAccess(ctx, "GenerateComplianceReport")
+	_, err := CheckAccess(ctx, "GetAuditSummary")
 	if err != nil {
 		s.LogAccessDenied(ctx, "GetAuditSummary", "", "audit_log", err.Error())
 		return nil, err
@@ -404,28 +664,154 @@ func (s *SmartContract) GetAuditSummary(ctx contractapi.TransactionContextInterf
 	return summary, nil
 }
 
+// MaxAuditSeriesRangeDays caps the date span GetAuditTimeSeries covers in one call
+const MaxAuditSeriesRangeDays = 366
+
+// timeSeries is an empty series of day or week buckets over an inclusive UTC date range
+type timeSeries struct {
+	start        time.Time
+	endExclusive time.Time
+	bucketDays   int
+	buckets      []TimeBucket
+}
+
+// newTimeSeries validates a startDate/endDate/granularity request and builds its buckets.
+// Weeks are 7-day buckets starting at startDate, the last one cut short at endDate. The
+// range may span at most MaxAuditSeriesRangeDays days.
+func newTimeSeries(startDate string, endDate string, granularity string) (*timeSeries, error) {
+	var bucketDays int
+	switch granularity {
+	case "day":
+		bucketDays = 1
+	case "week":
+		bucketDays = 7
+	default:
+		return nil, fmt.Errorf("invalid granularity %q: expected day or week", granularity)
+	}
+
+	start, err := time.Parse("2006-01-02", startDate)
+	if err != nil {
+		return nil, fmt.Errorf("invalid startDate %q: expected YYYY-MM-DD", startDate)
+	}
+	end, err := time.Parse("2006-01-02", endDate)
+	if err != nil {
+		return nil, fmt.Errorf("invalid endDate %q: expected YYYY-MM-DD", endDate)
+	}
+	if end.Before(start) {
+		return nil, fmt.Errorf("endDate must not be before startDate")
+	}
+	if end.Sub(start) > (MaxAuditSeriesRangeDays-1)*24*time.Hour {
+		return nil, fmt.Errorf("date range exceeds %d days", MaxAuditSeriesRangeDays)
+	}
+
+	series := &timeSeries{start: start, endExclusive: end.AddDate(0, 0, 1), bucketDays: bucketDays}
+	for day := start; day.Before(series.endExclusive); day = day.AddDate(0, 0, bucketDays) {
+		last := day.AddDate(0, 0, bucketDays-1)
+		if last.After(end) {
+			last = end
+		}
+		series.buckets = append(series.buckets, TimeBucket{
+			Start:        day.Format("2006-01-02"),
+			End:          last.Format("2006-01-02"),
+			EventsByType: make(map[string]int),
+		})
+	}
+	return series, nil
+}
+
+// bucket returns the bucket containing t, or nil if t is outside the series
+func (ts *timeSeries) bucket(t time.Time) *TimeBucket {
+	t = t.UTC()
+	if t.Before(ts.start) || !t.Before(ts.endExclusive) {
+		return nil
+	}
+	return &ts.buckets[int(t.Sub(ts.start)/(24*time.Hour))/ts.bucketDays]
+}
+
+// GetAuditTimeSeries counts audit events per "day" or "week" bucket between startDate and
+// endDate (inclusive, YYYY-MM-DD, UTC) for trend charts (see newTimeSeries for the buckets).
+// Every bucket is returned, including empty ones.
+// Cost: log IDs start with their timestamp, so only the AUDIT_ keys inside the range are read,
+// but that is every log in the period; keep ranges short on busy networks.
+func (s *SmartContract) GetAuditTimeSeries(ctx contractapi.TransactionContextInterface, startDate string, endDate string, granularity string) ([]TimeBucket, error) {
+	// Check access - only auditors and admins
+	_, err := CheckAccess(ctx, "GetAuditTimeSeries")
+	if err != nil {
+		s.LogAccessDenied(ctx, "GetAuditTimeSeries", "", "audit_log", err.Error())
+		return nil, err
+	}
+
+	series, err := newTimeSeries(startDate, endDate, granularity)
+	if err != nil {
+		return nil, err
+	}
+	iterator, err := ctx.GetStub().GetStateByRange("AUDIT_"+series.start.Format("20060102"), "AUDIT_"+series.endExclusive.Format("20060102"))
+	if err != nil {
+		return nil, fmt.Errorf("get audit logs: %w", err)
+	}
+	defer iterator.Close()
+
+	for iterator.HasNext() {
+		queryResponse, err := iterator.Next()
+		if err != nil {
+			return nil, fmt.Errorf("iterate: %w", err)
+		}
+
+		var log AuditLog
+		if err := json.Unmarshal(queryResponse.Value, &log); err != nil {
+			continue
+		}
+		logTime, err := time.Parse(time.RFC3339, log.Timestamp)
+		if err != nil {
+			continue
+		}
+		bucket := series.bucket(logTime)
+		if bucket == nil {
+			continue
+		}
+		bucket.TotalEvents++
+		if log.Status == "denied" {
+			bucket.DeniedCount++
+		}
+		bucket.EventsByType[log.EventType]++
+	}
+
+	s.LogDataRead(ctx, "GetAuditTimeSeries", fmt.Sprintf("period:%s to %s,granularity:%s", startDate, endDate, granularity), "audit_summary")
+
+	return series.buckets, nil
+}
+
 // GetUserActivityLog retrieves all audit logs for a specific user
 func (s *SmartContract) GetUserActivityLog(ctx contractapi.TransactionContextInterface, userIDHash string) ([]*AuditLog, error) {
 	// Check access - user can see their own activity, admins/auditors can see all
-	identity, err := CheckAccess(ctx, "GetUserProfile")
+	identity, err := CheckAccess(ctx, "GetUserActivityLog")
 	if err != nil {
+		s.LogAccessDenied(ctx, "GetUserActivityLog", userIDHash, "user_activity", err.Error())
 		return nil, err
 	}
 
 	// Check self-access
-	if err := CheckSelfAccess(identity, "GetUserActivityLog", userIDHash); err != nil {
+	if err := CheckSelfAccess(ctx, identity, "GetUserActivityLog", userIDHash); err != nil {
 		s.LogAccessDenied(ctx, "GetUserActivityLog", userIDHash, "user_activity", err.Error())
 		return nil, err
 	}
 
 	queryJSON := fmt.Sprintf(`{"callerId":"%s","limit":500}`, userIDHash)
-	return s.GetAuditLogs(ctx, queryJSON)
+	logs, err := queryAuditLogs(ctx, queryJSON)
+	if err != nil {
+		return nil, err
+	}
+
+	s.LogDataRead(ctx, "GetUserActivityLog", userIDHash, "user_activity")
+
+	return logs, nil
 }
 
-// GetHighRiskEvents retrieves all high-risk and critical audit events
+// GetHighRiskEvents retrieves the earliest high-risk and critical audit events, up to limit,
+// oldest first
 func (s *SmartContract) GetHighRiskEvents(ctx contractapi.TransactionContextInterface, limit int) ([]*AuditLog, error) {
-	// Check access - only admins and government officials
-	identity, err := CheckAccess(ctx, "GenerateComplianceReport")
+	// Check access - only admins, auditors, and government officials
+	identity, err := CheckAccess(ctx, "GetHighRiskEvents")
 	if err != nil {
 		s.LogAccessDenied(ctx, "GetHighRiskEvents", "", "audit_log", err.Error())
 		return nil, err
@@ -462,6 +848,8 @@ func (s *SmartContract) GetHighRiskEvents(ctx contractapi.TransactionContextInte
 		}
 	}
 
+	sortAuditLogsChronologically(logs)
+
 	s.LogDataRead(ctx, "GetHighRiskEvents", fmt.Sprintf("count:%d", len(logs)), "audit_log")
 
 	fmt.Printf("[SECURITY AUDIT] User %s accessed %d high-risk events\n", identity.ID, len(logs))
@@ -469,15 +857,59 @@ func (s *SmartContract) GetHighRiskEvents(ctx contractapi.TransactionContextInte
 	return logs, nil
 }
 
-// GetAccessDenials retrieves all access denial events (security monitoring)
+// AuditLogPage is one page of a paginated audit log query.
+type AuditLogPage struct {
+	Records  []*AuditLog `json:"records"`
+	Count    int32       `json:"count"`
+	Bookmark string      `json:"bookmark"` // Empty when there are no more pages
+}
+
+// auditKeyRange converts an optional YYYY-MM-DD date range into AUDIT_ key bounds. Both
+// dates must be given or neither; an empty range covers the whole audit log.
+func auditKeyRange(startDate string, endDate string) (string, string, error) {
+	if startDate == "" && endDate == "" {
+		return "AUDIT_", "AUDIT_~", nil
+	}
+	if startDate == "" || endDate == "" {
+		return "", "", fmt.Errorf("startDate and endDate must be given together")
+	}
+	start, err := time.Parse("2006-01-02", startDate)
+	if err != nil {
+		return "", "", fmt.Errorf("invalid startDate %q: expected YYYY-MM-DD", startDate)
+	}
+	end, err := time.Parse("2006-01-02", endDate)
+	if err != nil {
+		return "", "", fmt.Errorf("invalid endDate %q: expected YYYY-MM-DD", endDate)
+	}
+	if end.Before(start) {
+		return "", "", fmt.Errorf("endDate must not be before startDate")
+	}
+	return "AUDIT_" + start.Format("20060102"), "AUDIT_" + end.AddDate(0, 0, 1).Format("20060102"), nil
+}
+
+// isAccessDenial reports whether an audit log records an access denial
+func isAccessDenial(log *AuditLog) bool {
+	return log.EventType == EventAccessDenied || log.Status == "denied"
+}
+
+// GetAccessDenials retrieves all access denial events (security monitoring), oldest first.
+// The date range is optional, but both dates must be valid YYYY-MM-DD values when given.
+// NOTE: The result is unbounded; prefer GetAccessDenialsPage or GetAccessDenialCounts on
+// large ledgers.
 func (s *SmartContract) GetAccessDenials(ctx contractapi.TransactionContextInterface, startDate string, endDate string) ([]*AuditLog, error) {
-	// Check access - only admins
-	identity, err := CheckAccess(ctx, "UpdateUserStatus")
+	// Check access - only admins and government officials
+	identity, err := CheckAccess(ctx, "GetAccessDenials")
 	if err != nil {
+		s.LogAccessDenied(ctx, "GetAccessDenials", "", "audit_log", err.Error())
 		return nil, err
 	}
 
-	iterator, err := ctx.GetStub().GetStateByRange("AUDIT_", "AUDIT_~")
+	startKey, endKey, err := auditKeyRange(startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+
+	iterator, err := ctx.GetStub().GetStateByRange(startKey, endKey)
 	if err != nil {
 		return nil, fmt.Errorf("get audit logs: %w", err)
 	}
@@ -495,29 +927,408 @@ func (s *SmartContract) GetAccessDenials(ctx contractapi.TransactionContextInter
 			continue
 		}
 
-		if log.EventType != EventAccessDenied && log.Status != "denied" {
+		if !isAccessDenial(&log) {
 			continue
 		}
 
-		// Date range filter
-		if startDate != "" && endDate != "" {
-			logTime, err := time.Parse(time.RFC3339, log.Timestamp)
-			if err != nil {
-				continue
-			}
-			start, _ := time.Parse("2006-01-02", startDate)
-			end, _ := time.Parse("2006-01-02", endDate)
-			if logTime.Before(start) || logTime.After(end.Add(24*time.Hour)) {
-				continue
-			}
-		}
-
 		logs = append(logs, &log)
 	}
 
+	sortAuditLogsChronologically(logs)
+
 	s.LogDataRead(ctx, "GetAccessDenials", fmt.Sprintf("count:%d", len(logs)), "audit_log")
 
 	fmt.Printf("[SECURITY AUDIT] User %s retrieved %d access denial records\n", identity.ID, len(logs))
 
 	return logs, nil
 }
+
+// GetAccessDenialsPage retrieves access denial events one page at a time, oldest first.
+// Each page scans up to pageSize audit logs and returns the denials among them, so a page
+// may hold fewer than pageSize records (or none) while the bookmark is still non-empty;
+// keep fetching until the bookmark is empty. Fabric forbids state writes in a transaction
+// that uses pagination, so successful reads are not written to the audit log.
+func (s *SmartContract) GetAccessDenialsPage(ctx contractapi.TransactionContextInterface, startDate string, endDate string, pageSize int32, bookmark string) (*AuditLogPage, error) {
+	// Check access - same as GetAccessDenials
+	if _, err := CheckAccess(ctx, "GetAccessDenialsPage"); err != nil {
+		s.LogAccessDenied(ctx, "GetAccessDenialsPage", "", "audit_log", err.Error())
+		return nil, err
+	}
+
+	startKey, endKey, err := auditKeyRange(startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+
+	iterator, metadata, err := ctx.GetStub().GetStateByRangeWithPagination(startKey, endKey, clampPageSize(pageSize), bookmark)
+	if err != nil {
+		return nil, fmt.Errorf("get audit logs: %w", err)
+	}
+	defer iterator.Close()
+
+	page := &AuditLogPage{Records: []*AuditLog{}}
+	for iterator.HasNext() {
+		queryResponse, err := iterator.Next()
+		if err != nil {
+			return nil, fmt.Errorf("iterate: %w", err)
+		}
+
+		var log AuditLog
+		if err := json.Unmarshal(queryResponse.Value, &log); err != nil || !isAccessDenial(&log) {
+			continue
+		}
+		page.Records = append(page.Records, &log)
+	}
+
+	page.Count = int32(len(page.Records))
+	if metadata != nil && metadata.FetchedRecordsCount >= clampPageSize(pageSize) {
+		page.Bookmark = metadata.Bookmark
+	}
+
+	return page, nil
+}
+
+// GetAccessDenialCounts counts access denial events per caller or per function, for
+// spotting a misbehaving identity or a misconfigured rule without paging raw records.
+// groupBy is "caller" (enrollment ID) or "function".
+// NOTE: This scans the AUDIT_ key range of the date window (the whole audit log when no
+// dates are given); keep windows narrow on large ledgers.
+func (s *SmartContract) GetAccessDenialCounts(ctx contractapi.TransactionContextInterface, startDate string, endDate string, groupBy string) (map[string]int, error) {
+	// Check access - same as GetAccessDenials
+	identity, err := CheckAccess(ctx, "GetAccessDenialCounts")
+	if err != nil {
+		s.LogAccessDenied(ctx, "GetAccessDenialCounts", "", "audit_log", err.Error())
+		return nil, err
+	}
+
+	if groupBy != "caller" && groupBy != "function" {
+		return nil, fmt.Errorf("invalid groupBy %q: expected caller or function", groupBy)
+	}
+
+	startKey, endKey, err := auditKeyRange(startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+
+	iterator, err := ctx.GetStub().GetStateByRange(startKey, endKey)
+	if err != nil {
+		return nil, fmt.Errorf("get audit logs: %w", err)
+	}
+	defer iterator.Close()
+
+	counts := make(map[string]int)
+	for iterator.HasNext() {
+		queryResponse, err := iterator.Next()
+		if err != nil {
+			return nil, fmt.Errorf("iterate: %w", err)
+		}
+
+		var log AuditLog
+		if err := json.Unmarshal(queryResponse.Value, &log); err != nil || !isAccessDenial(&log) {
+			continue
+		}
+
+		if groupBy == "caller" {
+			counts[log.CallerID]++
+		} else {
+			counts[log.Function]++
+		}
+	}
+
+	s.LogDataRead(ctx, "GetAccessDenialCounts", groupBy, "audit_log")
+
+	fmt.Printf("[SECURITY AUDIT] User %s counted access denials by %s\n", identity.ID, groupBy)
+
+	return counts, nil
+}
+
+// GetCallerActivityCount counts audit events per event type for a single caller in a date window.
+// Supports abuse monitoring, e.g. one identity suddenly generating many denials or writes.
+// The date range is optional, but both dates must be valid YYYY-MM-DD values when given.
+// NOTE: This scans the AUDIT_ key range of the date window (the whole audit log when no
+// dates are given), so cost grows with the window rather than the caller's activity.
+func (s *SmartContract) GetCallerActivityCount(ctx contractapi.TransactionContextInterface, callerID string, startDate string, endDate string) (map[string]int, error) {
+	if callerID == "" {
+		return nil, fmt.Errorf("callerID is required")
+	}
+
+	// Check access - only admins and auditors
+	identity, err := CheckAccess(ctx, "GetCallerActivityCount")
+	if err != nil {
+		s.LogAccessDenied(ctx, "GetCallerActivityCount", callerID, "audit_log", err.Error())
+		return nil, err
+	}
+
+	startKey, endKey, err := auditKeyRange(startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+
+	iterator, err := ctx.GetStub().GetStateByRange(startKey, endKey)
+	if err != nil {
+		return nil, fmt.Errorf("get audit logs: %w", err)
+	}
+	defer iterator.Close()
+
+	counts := make(map[string]int)
+	for iterator.HasNext() {
+		queryResponse, err := iterator.Next()
+		if err != nil {
+			return nil, fmt.Errorf("iterate: %w", err)
+		}
+
+		var log AuditLog
+		if err := json.Unmarshal(queryResponse.Value, &log); err != nil || log.CallerID != callerID {
+			continue
+		}
+
+		counts[log.EventType]++
+	}
+
+	s.LogDataRead(ctx, "GetCallerActivityCount", callerID, "audit_log")
+
+	fmt.Printf("[SECURITY AUDIT] User %s counted activity for caller %s\n", identity.ID, callerID)
+
+	return counts, nil
+}
+
+// GetAuditTrailForTarget returns every audit event touching a record (e.g. a wageID or
+// userIDHash) in chronological order, answering who read or changed it and when.
+// Uses the audit~target index written by LogAccess; events logged before the index
+// existed are not returned.
+func (s *SmartContract) GetAuditTrailForTarget(ctx contractapi.TransactionContextInterface, targetID string) ([]*AuditLog, error) {
+	if targetID == "" {
+		return nil, fmt.Errorf("targetID is required")
+	}
+
+	// Check access - only auditors and admins
+	identity, err := CheckAccess(ctx, "GetAuditTrailForTarget")
+	if err != nil {
+		s.LogAccessDenied(ctx, "GetAuditTrailForTarget", targetID, "audit_log", err.Error())
+		return nil, err
+	}
+
+	iterator, err := ctx.GetStub().GetStateByPartialCompositeKey("audit~target", []string{targetID})
+	if err != nil {
+		return nil, fmt.Errorf("get audit target index: %w", err)
+	}
+	defer iterator.Close()
+
+	logs := []*AuditLog{}
+	for iterator.HasNext() {
+		queryResponse, err := iterator.Next()
+		if err != nil {
+			continue
+		}
+
+		_, parts, err := ctx.GetStub().SplitCompositeKey(queryResponse.Key)
+		if err != nil || len(parts) != 2 {
+			continue
+		}
+
+		payload, err := ctx.GetStub().GetState(parts[1])
+		if err != nil || payload == nil {
+			continue
+		}
+
+		var log AuditLog
+		if err := json.Unmarshal(payload, &log); err != nil {
+			continue
+		}
+
+		// A later entry in the same transaction may have reused the log ID
+		if log.TargetID != targetID {
+			continue
+		}
+
+		logs = append(logs, &log)
+	}
+
+	sortAuditLogsChronologically(logs)
+
+	s.LogDataRead(ctx, "GetAuditTrailForTarget", targetID, "audit_log")
+
+	fmt.Printf("[SECURITY AUDIT] User %s retrieved %d audit events for target %s\n", identity.ID, len(logs), targetID)
+
+	return logs, nil
+}
+
+// DetectBruteForcePatterns flags callers whose access denials within the last windowMinutes
+// (measured back from the transaction timestamp) exceed the bruteForceDenialThreshold config.
+// Emits a BruteForcePatternDetected event when any caller is flagged.
+// Only the AUDIT_ keys whose timestamp prefix falls inside the window are scanned.
+func (s *SmartContract) DetectBruteForcePatterns(ctx contractapi.TransactionContextInterface, windowMinutes int) ([]SuspiciousPattern, error) {
+	if windowMinutes <= 0 {
+		return nil, fmt.Errorf("windowMinutes must be positive")
+	}
+
+	// Check access - only admins
+	identity, err := CheckAccess(ctx, "DetectBruteForcePatterns")
+	if err != nil {
+		s.LogAccessDenied(ctx, "DetectBruteForcePatterns", "", "audit_log", err.Error())
+		return nil, err
+	}
+
+	threshold, err := getConfigInt(ctx, ConfigBruteForceDenialThreshold)
+	if err != nil {
+		return nil, err
+	}
+
+	windowEnd, err := time.Parse(time.RFC3339, GetTxTimestampRFC3339(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("parse tx timestamp: %w", err)
+	}
+	windowStart := windowEnd.Add(-time.Duration(windowMinutes) * time.Minute)
+
+	// Audit keys are AUDIT_<yyyymmddhhmmss>_..., so the window maps onto a key range;
+	// the end bound covers every key stamped in the final second of the window.
+	startKey := "AUDIT_" + windowStart.Format("20060102150405")
+	endKey := "AUDIT_" + windowEnd.Add(time.Second).Format("20060102150405")
+	iterator, err := ctx.GetStub().GetStateByRange(startKey, endKey)
+	if err != nil {
+		return nil, fmt.Errorf("get audit logs: %w", err)
+	}
+	defer iterator.Close()
+
+	byCaller := make(map[string]*SuspiciousPattern)
+	functionsSeen := make(map[string]map[string]bool)
+	for iterator.HasNext() {
+		queryResponse, err := iterator.Next()
+		if err != nil {
+			return nil, fmt.Errorf("iterate: %w", err)
+		}
+
+		var log AuditLog
+		if err := json.Unmarshal(queryResponse.Value, &log); err != nil {
+			continue
+		}
+
+		if log.EventType != EventAccessDenied && log.Status != "denied" {
+			continue
+		}
+
+		logTime, err := time.Parse(time.RFC3339, log.Timestamp)
+		if err != nil || logTime.Before(windowStart) || logTime.After(windowEnd) {
+			continue
+		}
+
+		pattern, exists := byCaller[log.CallerID]
+		if !exists {
+			pattern = &SuspiciousPattern{
+				CallerID:  log.CallerID,
+				CallerMSP: log.CallerMSP,
+				Threshold: threshold,
+				FirstSeen: log.Timestamp,
+				LastSeen:  log.Timestamp,
+			}
+			byCaller[log.CallerID] = pattern
+			functionsSeen[log.CallerID] = make(map[string]bool)
+		}
+
+		pattern.DenialCount++
+		if log.Timestamp < pattern.FirstSeen {
+			pattern.FirstSeen = log.Timestamp
+		}
+		if log.Timestamp > pattern.LastSeen {
+			pattern.LastSeen = log.Timestamp
+		}
+		if !functionsSeen[log.CallerID][log.Function] {
+			functionsSeen[log.CallerID][log.Function] = true
+			pattern.Functions = append(pattern.Functions, log.Function)
+		}
+	}
+
+	patterns := []SuspiciousPattern{}
+	for _, pattern := range byCaller {
+		if pattern.DenialCount > threshold {
+			sort.Strings(pattern.Functions)
+			patterns = append(patterns, *pattern)
+		}
+	}
+	sort.Slice(patterns, func(i, j int) bool {
+		if patterns[i].DenialCount != patterns[j].DenialCount {
+			return patterns[i].DenialCount > patterns[j].DenialCount
+		}
+		return patterns[i].CallerID < patterns[j].CallerID
+	})
+
+	s.LogDataRead(ctx, "DetectBruteForcePatterns", fmt.Sprintf("window:%dm", windowMinutes), "audit_log")
+
+	fmt.Printf("[SECURITY AUDIT] User %s detected %d brute-force patterns in the last %d minutes\n", identity.ID, len(patterns), windowMinutes)
+
+	if len(patterns) > 0 {
+		callers := make([]string, len(patterns))
+		for i, pattern := range patterns {
+			callers[i] = pattern.CallerID
+		}
+		eventData, _ := marshalState(map[string]interface{}{
+			"riskLevel":     RiskHigh,
+			"windowMinutes": windowMinutes,
+			"callers":       callers,
+		})
+		if err := ctx.GetStub().SetEvent("BruteForcePatternDetected", eventData); err != nil {
+			fmt.Printf("warning: failed to emit event: %v\n", err)
+		}
+	}
+
+	return patterns, nil
+}
+
+// GetMSPActivitySummary returns per-MSP counts of writes, reads, and denials in a date
+// window, so governance can spot one organization behaving anomalously.
+// Denials are events with status "denied"; reads are DATA_READ events; every other
+// successful event (grants on write paths, registrations, config changes, etc.) counts
+// as a write. The date range is optional, but both dates must be valid YYYY-MM-DD values
+// when given.
+// NOTE: This scans the AUDIT_ key range of the date window (the whole audit log when no
+// dates are given); schedule wide windows off-peak on large ledgers.
+func (s *SmartContract) GetMSPActivitySummary(ctx contractapi.TransactionContextInterface, startDate string, endDate string) (map[string]MSPStats, error) {
+	// Check access - only admins
+	identity, err := CheckAccess(ctx, "GetMSPActivitySummary")
+	if err != nil {
+		s.LogAccessDenied(ctx, "GetMSPActivitySummary", "", "audit_log", err.Error())
+		return nil, err
+	}
+
+	startKey, endKey, err := auditKeyRange(startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+
+	iterator, err := ctx.GetStub().GetStateByRange(startKey, endKey)
+	if err != nil {
+		return nil, fmt.Errorf("get audit logs: %w", err)
+	}
+	defer iterator.Close()
+
+	summary := make(map[string]MSPStats)
+	for iterator.HasNext() {
+		queryResponse, err := iterator.Next()
+		if err != nil {
+			return nil, fmt.Errorf("iterate: %w", err)
+		}
+
+		var log AuditLog
+		if err := json.Unmarshal(queryResponse.Value, &log); err != nil {
+			continue
+		}
+
+		stats := summary[log.CallerMSP]
+		switch {
+		case log.Status == "denied" || log.EventType == EventAccessDenied:
+			stats.Denials++
+		case log.EventType == EventDataRead:
+			stats.Reads++
+		default:
+			stats.Writes++
+		}
+		stats.Total++
+		summary[log.CallerMSP] = stats
+	}
+
+	s.LogDataRead(ctx, "GetMSPActivitySummary", fmt.Sprintf("period:%s to %s", startDate, endDate), "audit_summary")
+
+	fmt.Printf("[SECURITY AUDIT] User %s summarized activity for %d MSPs\n", identity.ID, len(summary))
+
+	return summary, nil
+}
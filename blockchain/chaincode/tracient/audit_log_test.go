@@ -0,0 +1,101 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestNewTimeSeries(t *testing.T) {
+	series, err := newTimeSeries("2025-12-01", "2025-12-10", "week")
+	if err != nil {
+		t.Fatalf("newTimeSeries: %v", err)
+	}
+	if len(series.buckets) != 2 || series.buckets[1].Start != "2025-12-08" || series.buckets[1].End != "2025-12-10" {
+		t.Fatalf("buckets = %+v", series.buckets)
+	}
+	if b := series.bucket(time.Date(2025, 12, 9, 23, 0, 0, 0, time.UTC)); b != &series.buckets[1] {
+		t.Fatal("expected the second week")
+	}
+	if series.bucket(time.Date(2025, 12, 11, 0, 0, 0, 0, time.UTC)) != nil {
+		t.Fatal("expected nil after endDate")
+	}
+
+	for _, args := range [][3]string{
+		{"2025-12-01", "2025-12-10", "month"},
+		{"2025-12-10", "2025-12-01", "day"},
+		{"2025-12-01", "2027-01-01", "day"},
+		{"12/01/2025", "2025-12-10", "day"},
+	} {
+		if _, err := newTimeSeries(args[0], args[1], args[2]); err == nil {
+			t.Errorf("newTimeSeries%v: expected error", args)
+		}
+	}
+}
+
+func TestGetCallerActivityCountUsesDateRange(t *testing.T) {
+	ctx := newMockContext("Org1MSP", "role=admin", "clearanceLevel=10")
+	ctx.stub.state["AUDIT_20251130235959_a"] = []byte(`{"eventType":"DATA_READ","callerId":"user-1","timestamp":"2025-11-30T23:59:59Z"}`)
+	ctx.stub.state["AUDIT_20251201080000_b"] = []byte(`{"eventType":"DATA_READ","callerId":"user-1","timestamp":"2025-12-01T08:00:00Z"}`)
+	ctx.stub.state["AUDIT_20251201090000_c"] = []byte(`{"eventType":"ACCESS_DENIED","callerId":"user-1","timestamp":"2025-12-01T09:00:00Z"}`)
+	ctx.stub.state["AUDIT_20251201090000_d"] = []byte(`{"eventType":"DATA_READ","callerId":"user-2","timestamp":"2025-12-01T09:00:00Z"}`)
+	s := &SmartContract{}
+
+	counts, err := s.GetCallerActivityCount(ctx, "user-1", "2025-12-01", "2025-12-01")
+	if err != nil {
+		t.Fatalf("GetCallerActivityCount: %v", err)
+	}
+	if counts[EventDataRead] != 1 || counts[EventAccessDenied] != 1 {
+		t.Fatalf("counts = %v", counts)
+	}
+
+	if _, err := s.GetCallerActivityCount(ctx, "user-1", "2025-12-01", "12/31/2025"); err == nil || !strings.Contains(err.Error(), "invalid endDate") {
+		t.Fatalf("expected a malformed date to be rejected, got %v", err)
+	}
+}
+
+func TestGetMSPActivitySummaryUsesDateRange(t *testing.T) {
+	ctx := newMockContext("Org1MSP", "role=admin", "clearanceLevel=10")
+	ctx.stub.state["AUDIT_20251130120000_a"] = []byte(`{"eventType":"DATA_READ","callerMsp":"Org2MSP","status":"success","timestamp":"2025-11-30T12:00:00Z"}`)
+	ctx.stub.state["AUDIT_20251201080000_b"] = []byte(`{"eventType":"DATA_READ","callerMsp":"Org2MSP","status":"success","timestamp":"2025-12-01T08:00:00Z"}`)
+	ctx.stub.state["AUDIT_20251201090000_c"] = []byte(`{"eventType":"ACCESS_DENIED","callerMsp":"Org2MSP","status":"denied","timestamp":"2025-12-01T09:00:00Z"}`)
+	s := &SmartContract{}
+
+	summary, err := s.GetMSPActivitySummary(ctx, "2025-12-01", "2025-12-01")
+	if err != nil {
+		t.Fatalf("GetMSPActivitySummary: %v", err)
+	}
+	if stats := summary["Org2MSP"]; stats.Reads != 1 || stats.Denials != 1 || stats.Total != 2 {
+		t.Fatalf("Org2MSP stats = %+v", stats)
+	}
+
+	if _, err := s.GetMSPActivitySummary(ctx, "2025-12-01", ""); err == nil {
+		t.Fatal("expected a one-sided date range to be rejected")
+	}
+}
+
+func TestDetectBruteForcePatternsScansWindow(t *testing.T) {
+	ctx := newMockContext("Org1MSP", "role=admin", "clearanceLevel=10")
+	denial := `{"eventType":"ACCESS_DENIED","callerId":"user-1","callerMsp":"Org2MSP","function":"ReadWage","status":"denied","timestamp":"%s"}`
+	ctx.stub.state["AUDIT_20251201094000_old"] = []byte(fmt.Sprintf(denial, "2025-12-01T09:40:00Z"))
+	for i, ts := range []string{"095500", "095600", "095700", "095800", "095900", "100000"} {
+		key := fmt.Sprintf("AUDIT_20251201%s_%d", ts, i)
+		ctx.stub.state[key] = []byte(fmt.Sprintf(denial, "2025-12-01T"+ts[:2]+":"+ts[2:4]+":"+ts[4:]+"Z"))
+	}
+	s := &SmartContract{}
+
+	patterns, err := s.DetectBruteForcePatterns(ctx, 10)
+	if err != nil {
+		t.Fatalf("DetectBruteForcePatterns: %v", err)
+	}
+	if len(patterns) != 1 || patterns[0].CallerID != "user-1" || patterns[0].DenialCount != 6 {
+		t.Fatalf("patterns = %+v", patterns)
+	}
+	if patterns[0].LastSeen != "2025-12-01T10:00:00Z" {
+		t.Fatalf("LastSeen = %s, want the denial stamped at the window end", patterns[0].LastSeen)
+	}
+	if _, ok := ctx.stub.events["BruteForcePatternDetected"]; !ok {
+		t.Fatal("expected a BruteForcePatternDetected event")
+	}
+}
@@ -1,20 +1,30 @@
 package main
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
+	"math"
 	"sort"
 	"strconv"
 	"strings"
 	"time"
 
 	"github.com/hyperledger/fabric-chaincode-go/v2/pkg/cid"
+	"github.com/hyperledger/fabric-chaincode-go/v2/pkg/statebased"
 	"github.com/hyperledger/fabric-contract-api-go/v2/contractapi"
 )
 
 // IAMEnabled controls whether IAM checks are enforced (set to false for testing without certificates)
 const IAMEnabled = true
 
+// ChaincodeVersion is the version reported by GetHealth; keep in sync with the deployed package version
+const ChaincodeVersion = "2.1"
+
+// CurrentPolicyVersion is the wage policy version applied to seeded records
+const CurrentPolicyVersion = "2025-Q4"
+
 // Ensure cid package is used (for compiler)
 var _ = cid.GetID
 
@@ -29,15 +39,42 @@ type SmartContract struct {
 
 // WageRecord models a single wage transaction stored on ledger.
 type WageRecord struct {
-	DocType        string  `json:"docType"`
-	WageID         string  `json:"wageId"`
-	WorkerIDHash   string  `json:"workerIdHash"`
-	EmployerIDHash string  `json:"employerIdHash"`
-	Amount         float64 `json:"amount"`
-	Currency       string  `json:"currency"`
-	JobType        string  `json:"jobType,omitempty"`
-	Timestamp      string  `json:"timestamp"`
-	PolicyVersion  string  `json:"policyVersion"`
+	DocType        string   `json:"docType"`
+	WageID         string   `json:"wageId"`
+	WorkerIDHash   string   `json:"workerIdHash"`
+	EmployerIDHash string   `json:"employerIdHash"`
+	Amount         float64  `json:"amount"`
+	Currency       string   `json:"currency"`
+	JobType        string   `json:"jobType,omitempty"`
+	Timestamp      string   `json:"timestamp"`
+	PolicyVersion  string   `json:"policyVersion"`
+	Program        string   `json:"program,omitempty"` // Welfare/employment scheme the wage is paid under
+	Tags           []string `json:"tags,omitempty"`
+	State          string   `json:"state,omitempty"`        // State/region where the work was performed
+	DocumentHash   string   `json:"documentHash,omitempty"` // Hex SHA-256 of a supporting off-chain document
+	DocumentType   string   `json:"documentType,omitempty"` // e.g. contract, muster_roll
+	CreatedBy      string   `json:"createdBy,omitempty"`    // Client ID of the recording caller; empty on older records
+	Status         string   `json:"status,omitempty"`       // Approval status (WageStatusPending, ...); empty on older records
+}
+
+// Wage approval statuses. New wages start pending until an approver decides them with
+// UpdateWageStatus.
+const (
+	WageStatusPending  = "pending"
+	WageStatusApproved = "approved"
+	WageStatusRejected = "rejected"
+)
+
+// WageOptions carries optional RecordWage fields, passed as a JSON object so new
+// attributes can be added without changing the transaction's argument list.
+type WageOptions struct {
+	Program string   `json:"program,omitempty"`
+	Tags    []string `json:"tags,omitempty"`
+	Force   bool     `json:"force,omitempty"` // Allow a second wage with the same worker, employer and timestamp
+	State   string   `json:"state,omitempty"`
+
+	DocumentHash string `json:"documentHash,omitempty"`
+	DocumentType string `json:"documentType,omitempty"`
 }
 
 // UPITransaction models a UPI payment transaction for mock integration.
@@ -53,6 +90,32 @@ type UPITransaction struct {
 	Timestamp        string  `json:"timestamp"`
 	PaymentMethod    string  `json:"paymentMethod"` // "UPI"
 	OnChainReference string  `json:"onChainReference,omitempty"`
+	EmployerIDHash   string  `json:"employerIdHash,omitempty"` // Paying employer, for structured reconciliation
+}
+
+// BatchWagesRecordedEvent is the payload of the "BatchWagesRecorded" chaincode event.
+type BatchWagesRecordedEvent struct {
+	WageIDs []string `json:"wageIds"`
+	Count   int      `json:"count"`
+}
+
+// UPIRecordedEvent is the payload of the "UPITransactionRecorded" chaincode event, so
+// listeners can react to a payment without querying the chaincode for it.
+type UPIRecordedEvent struct {
+	TxID           string  `json:"txId"`
+	WorkerIDHash   string  `json:"workerIdHash"`
+	Amount         float64 `json:"amount"`
+	Currency       string  `json:"currency"`
+	Timestamp      string  `json:"timestamp"`
+	LinkedWageID   string  `json:"linkedWageId,omitempty"`
+	EmployerIDHash string  `json:"employerIdHash,omitempty"`
+}
+
+// DaySummary totals the UPI transactions settled on one day (UTC).
+type DaySummary struct {
+	Date   string             `json:"date"`
+	Count  int                `json:"count"`
+	Totals map[string]float64 `json:"totals"` // amount per currency
 }
 
 // User represents a registered user in the system with role-based access.
@@ -69,6 +132,26 @@ type User struct {
 	UpdatedAt   string `json:"updatedAt"`
 }
 
+// RoleDrift compares the role in a caller's certificate with the Role stored in their
+// User record. Drifted is true when they differ.
+type RoleDrift struct {
+	UserIDHash   string `json:"userIdHash"`
+	CertRole     string `json:"certRole"`
+	StoredRole   string `json:"storedRole"`
+	StoredStatus string `json:"storedStatus"`
+	Drifted      bool   `json:"drifted"`
+	CheckedAt    string `json:"checkedAt"`
+}
+
+// WorkerAlias maps a retired worker idHash (e.g. from re-enrollment) to the worker's canonical hash.
+type WorkerAlias struct {
+	DocType       string `json:"docType"`
+	AliasHash     string `json:"aliasHash"`
+	CanonicalHash string `json:"canonicalHash"`
+	AddedBy       string `json:"addedBy"`
+	AddedAt       string `json:"addedAt"`
+}
+
 // PovertyThreshold represents BPL/APL thresholds by state.
 type PovertyThreshold struct {
 	DocType   string  `json:"docType"`
@@ -79,6 +162,45 @@ type PovertyThreshold struct {
 	UpdatedAt string  `json:"updatedAt"`
 }
 
+// ExchangeRate is the stored conversion rate from one currency to another.
+type ExchangeRate struct {
+	DocType   string  `json:"docType"`
+	From      string  `json:"from"`
+	To        string  `json:"to"`
+	Rate      float64 `json:"rate"` // Units of To per unit of From
+	SetBy     string  `json:"setBy"`
+	UpdatedAt string  `json:"updatedAt"`
+}
+
+// ThresholdHistoryEntry represents one historical version of a poverty threshold.
+type ThresholdHistoryEntry struct {
+	TxID      string  `json:"txId"`
+	Timestamp string  `json:"timestamp"`
+	State     string  `json:"state"`
+	Category  string  `json:"category"`
+	Amount    float64 `json:"amount"`
+	SetBy     string  `json:"setBy"`
+	IsDelete  bool    `json:"isDelete"`
+}
+
+// FieldChange describes one field that differs between two versions of a record.
+// Values are JSON-encoded; an empty string means the field was absent.
+type FieldChange struct {
+	Field string `json:"field"`
+	From  string `json:"from"`
+	To    string `json:"to"`
+}
+
+// RecordDiff is a field-by-field comparison of two versions of a wage record.
+type RecordDiff struct {
+	WageID        string        `json:"wageId"`
+	FromTxID      string        `json:"fromTxId"`
+	ToTxID        string        `json:"toTxId"`
+	FromTimestamp string        `json:"fromTimestamp"`
+	ToTimestamp   string        `json:"toTimestamp"`
+	Changes       []FieldChange `json:"changes"`
+}
+
 // Anomaly represents a flagged suspicious wage record.
 type Anomaly struct {
 	DocType      string  `json:"docType"`
@@ -88,13 +210,194 @@ type Anomaly struct {
 	FlaggedBy    string  `json:"flaggedBy"`
 	Status       string  `json:"status"` // pending, reviewed, dismissed
 	Timestamp    string  `json:"timestamp"`
+	AnomalyID    string  `json:"anomalyId,omitempty"` // Key suffix; equals WageID for the first anomaly on a wage
+	Category     string  `json:"category,omitempty"`  // Normalized reason category, see anomalyReasonCategory
+	ReviewNotes  string  `json:"reviewNotes,omitempty"`
+}
+
+// ReviewItem represents a wage awaiting auditor attention in the review worklist.
+type ReviewItem struct {
+	WageID    string      `json:"wageId"`
+	Source    string      `json:"source"` // anomaly
+	Status    string      `json:"status"`
+	Severity  float64     `json:"severity"`
+	Reason    string      `json:"reason"`
+	FlaggedAt string      `json:"flaggedAt"`
+	Wage      *WageRecord `json:"wage,omitempty"`
+}
+
+// ResolutionStats measures how quickly anomalies raised in a period were closed. An anomaly
+// is resolved when confirmed and dismissed when dismissed; pending and reviewed ones form
+// the backlog.
+type ResolutionStats struct {
+	Period                 string         `json:"period"`
+	Raised                 int            `json:"raised"`
+	ResolvedCount          int            `json:"resolvedCount"`
+	DismissedCount         int            `json:"dismissedCount"`
+	OpenCount              int            `json:"openCount"`
+	AverageResolutionHours float64        `json:"averageResolutionHours"` // Over resolved and dismissed
+	BacklogAge             map[string]int `json:"backlogAge"`             // Open anomalies by age bucket
+}
+
+// FlaggedWageDetail joins an open anomaly with the wage it flags. Orphaned is set when
+// the referenced wage no longer exists on the ledger.
+type FlaggedWageDetail struct {
+	Anomaly  *Anomaly    `json:"anomaly"`
+	Wage     *WageRecord `json:"wage,omitempty"`
+	Orphaned bool        `json:"orphaned"`
+}
+
+// AnomalyBatchResult reports the outcome of BatchUpdateAnomalyStatus per anomaly.
+type AnomalyBatchResult struct {
+	Updated []string          `json:"updated"`
+	Failed  map[string]string `json:"failed"` // anomalyID -> reason
+}
+
+// EmployerSummary aggregates the wages one employer has paid a worker.
+type EmployerSummary struct {
+	EmployerIDHash string  `json:"employerIdHash"`
+	WageCount      int     `json:"wageCount"`
+	TotalPaid      float64 `json:"totalPaid"`
+}
+
+// WorkerSummary aggregates the wages one employer has paid a worker.
+type WorkerSummary struct {
+	WorkerIDHash string  `json:"workerIdHash"`
+	WageCount    int     `json:"wageCount"`
+	TotalPaid    float64 `json:"totalPaid"`
+}
+
+// RiskScore is a composite 0-100 vulnerability score for a worker with its components.
+type RiskScore struct {
+	WorkerIDHash           string  `json:"workerIdHash"`
+	Score                  float64 `json:"score"`
+	StabilityComponent     float64 `json:"stabilityComponent"`  // 0-40
+	PaymentGapComponent    float64 `json:"paymentGapComponent"` // 0-30
+	PovertyComponent       float64 `json:"povertyComponent"`    // 0-30
+	MonthsObserved         int     `json:"monthsObserved"`
+	CoefficientOfVariation float64 `json:"coefficientOfVariation"`
+	LongestGapDays         float64 `json:"longestGapDays"`
+	AnnualIncome           float64 `json:"annualIncome"`
+	Currency               string  `json:"currency,omitempty"` // Currency of the worker's wages
+	PovertyStatus          string  `json:"povertyStatus"`
+}
+
+// CreditProfile bundles the derived income metrics used for bank credit assessment. It
+// carries no per-transaction data.
+type CreditProfile struct {
+	WorkerIDHash           string  `json:"workerIdHash"`
+	TotalIncome            float64 `json:"totalIncome"`  // All recorded wages
+	AnnualIncome           float64 `json:"annualIncome"` // Last 365 days
+	AverageMonthlyIncome   float64 `json:"averageMonthlyIncome"`
+	Currency               string  `json:"currency,omitempty"` // All amounts are in this currency
+	WageCount              int     `json:"wageCount"`
+	MonthsObserved         int     `json:"monthsObserved"`
+	CoefficientOfVariation float64 `json:"coefficientOfVariation"` // Income stability; lower is steadier
+	LongestGapDays         float64 `json:"longestGapDays"`         // Payment regularity
+	PovertyStatus          string  `json:"povertyStatus"`          // BPL or APL
+	RiskScore              float64 `json:"riskScore"`
+	GeneratedAt            string  `json:"generatedAt"`
+}
+
+// PaymentSpan is the period over which a worker has been paid, from wages and UPI payments.
+type PaymentSpan struct {
+	WorkerIDHash string `json:"workerIdHash"`
+	FirstPayment string `json:"firstPayment,omitempty"`
+	LastPayment  string `json:"lastPayment,omitempty"`
+	SpanDays     int    `json:"spanDays"` // 0 for a single payment or none
+	WageCount    int    `json:"wageCount"`
+	UPICount     int    `json:"upiCount"`
+	PaymentCount int    `json:"paymentCount"` // WageCount + UPICount
+}
+
+// DayPayments lists the payments a worker received on one day (UTC).
+type DayPayments struct {
+	Date            string             `json:"date"`
+	Wages           []*WageRecord      `json:"wages"`
+	UPITransactions []*UPITransaction  `json:"upiTransactions"`
+	WageTotals      map[string]float64 `json:"wageTotals"` // amount per currency
+	UPITotals       map[string]float64 `json:"upiTotals"`  // amount per currency
+}
+
+// PartyInfo holds display information for a worker or employer on a receipt.
+type PartyInfo struct {
+	IDHash     string `json:"idHash"`
+	Name       string `json:"name,omitempty"`
+	Role       string `json:"role,omitempty"`
+	Registered bool   `json:"registered"`
+}
+
+// WageDetail is the one-shot view of a wage for record-detail pages. Sections that could not
+// be loaded are left empty and explained in Warnings.
+type WageDetail struct {
+	Wage            *WageRecord       `json:"wage"`
+	HistoryCount    int               `json:"historyCount"` // Versions of the record on the ledger
+	UPITransactions []*UPITransaction `json:"upiTransactions"`
+	Anomalies       []*Anomaly        `json:"anomalies"` // Only for roles that may review anomalies
+	Warnings        []string          `json:"warnings,omitempty"`
+}
+
+// CaseFile is the case-management view of one worker for officials: profile, income,
+// poverty status, open anomalies on their wages and recent payments. Sections that could
+// not be read are left empty and explained in Warnings.
+type CaseFile struct {
+	WorkerIDHash   string            `json:"workerIdHash"`
+	Profile        *User             `json:"profile,omitempty"`
+	TotalIncome    float64           `json:"totalIncome"`   // All recorded wages; 0 with a warning when they span currencies
+	AnnualIncome   float64           `json:"annualIncome"`  // Wages in the 365 days before the transaction
+	MonthlyIncome  []*MonthlyIncome  `json:"monthlyIncome"` // Latest CaseFileMonths months with wages, per currency, newest first, then UnknownMonth
+	PovertyStatus  string            `json:"povertyStatus"` // BPL or APL against the DEFAULT BPL threshold
+	OpenAnomalies  []*Anomaly        `json:"openAnomalies"`
+	RecentPayments []*UPITransaction `json:"recentPayments"` // Latest CaseFilePayments UPI payments, newest first
+	GeneratedAt    string            `json:"generatedAt"`
+	Warnings       []string          `json:"warnings,omitempty"`
+}
+
+// ReceiptData bundles everything an off-chain service needs to render a wage receipt.
+type ReceiptData struct {
+	Wage            *WageRecord       `json:"wage"`
+	Worker          PartyInfo         `json:"worker"`
+	Employer        PartyInfo         `json:"employer"`
+	UPITransactions []*UPITransaction `json:"upiTransactions"`
+	RecordHash      string            `json:"recordHash"` // SHA-256 of the stored wage bytes
+	HashAlgorithm   string            `json:"hashAlgorithm"`
+	GeneratedAt     string            `json:"generatedAt"`
+	Display         *DisplayAmount    `json:"display,omitempty"` // Set when a display currency is requested
+}
+
+// DisplayAmount presents a wage amount converted to a display currency alongside the original.
+type DisplayAmount struct {
+	OriginalAmount   float64 `json:"originalAmount"`
+	OriginalCurrency string  `json:"originalCurrency"`
+	Amount           float64 `json:"amount"` // Rounded to 2 decimal places
+	Currency         string  `json:"currency"`
+	Rate             float64 `json:"rate"`
+	RateUpdatedAt    string  `json:"rateUpdatedAt,omitempty"`
+}
+
+// StateSummary aggregates wages recorded for one state/region.
+type StateSummary struct {
+	State       string  `json:"state"`
+	WageCount   int     `json:"wageCount"`
+	WorkerCount int     `json:"workerCount"`
+	TotalAmount float64 `json:"totalAmount"`
+	AverageWage float64 `json:"averageWage"`
+}
+
+// WagePage is one page of a paginated wage query.
+type WagePage struct {
+	Records  []*WageRecord `json:"records"`
+	Count    int32         `json:"count"`
+	Bookmark string        `json:"bookmark"` // Empty when there are no more pages
 }
 
 // MonthlyIncome represents income breakdown for a month.
 type MonthlyIncome struct {
-	Month       string  `json:"month"` // Format: YYYY-MM
-	TotalIncome float64 `json:"totalIncome"`
-	WageCount   int     `json:"wageCount"`
+	Month        string  `json:"month"`              // Format: YYYY-MM, or "unknown" (UnknownMonth)
+	Currency     string  `json:"currency,omitempty"` // TotalIncome is only ever summed within one currency
+	TotalIncome  float64 `json:"totalIncome"`
+	WageCount    int     `json:"wageCount"`
+	PaymentCount int     `json:"paymentCount,omitempty"` // Unlinked UPI payments included in TotalIncome
 }
 
 // PovertyStatusResult represents the result of poverty status check.
@@ -115,6 +418,9 @@ type ComplianceReport struct {
 	TotalRecords  int         `json:"totalRecords"`
 	TotalAmount   float64     `json:"totalAmount"`
 	Data          interface{} `json:"data"`
+
+	// CurrencyBreakdown totals wage amounts per currency, since TotalAmount mixes currencies
+	CurrencyBreakdown map[string]float64 `json:"currencyBreakdown,omitempty"`
 }
 
 // ============================================================================
@@ -134,22 +440,198 @@ func GetTxTimestampRFC3339(ctx contractapi.TransactionContextInterface) string {
 	return time.Unix(timestamp.GetSeconds(), int64(timestamp.GetNanos())).UTC().Format(time.RFC3339)
 }
 
+// generateDeterministicID returns "<prefix>_<txID[:16]>_<seq>", where seq counts the IDs
+// generated so far in this transaction (000, 001, ...). Every endorser executes the same
+// calls in the same order, so all of them mint identical IDs, and the txID makes IDs
+// unique across transactions. The count is kept on the transaction context (see txState),
+// so a re-simulated transaction mints the same IDs again. Use this for every generated key;
+// never derive IDs from time.Now or random values, which cause endorsement mismatches.
+func generateDeterministicID(ctx contractapi.TransactionContextInterface, prefix string) string {
+	txID := ctx.GetStub().GetTxID()
+
+	state := txStateOf(ctx)
+	seq := state.idSequence
+	state.idSequence++
+
+	shortTxID := txID
+	if len(shortTxID) > 16 {
+		shortTxID = shortTxID[:16]
+	}
+	return fmt.Sprintf("%s_%s_%03d", prefix, shortTxID, seq)
+}
+
+// chronologicallyBefore orders two records by RFC3339 timestamp, then by ID. Timestamps
+// that do not parse sort before all others, so the order is total and deterministic.
+func chronologicallyBefore(timestampA string, idA string, timestampB string, idB string) bool {
+	timeA, errA := time.Parse(time.RFC3339, timestampA)
+	timeB, errB := time.Parse(time.RFC3339, timestampB)
+	if errA != nil {
+		timeA = time.Time{}
+	}
+	if errB != nil {
+		timeB = time.Time{}
+	}
+	if !timeA.Equal(timeB) {
+		return timeA.Before(timeB)
+	}
+	return idA < idB
+}
+
+// sortWagesChronologically orders wages oldest first. Range scans return key (wageID) order,
+// which clients otherwise mistake for time order.
+func sortWagesChronologically(wages []*WageRecord) {
+	sort.Slice(wages, func(i, j int) bool {
+		return chronologicallyBefore(wages[i].Timestamp, wages[i].WageID, wages[j].Timestamp, wages[j].WageID)
+	})
+}
+
+// sortWagesNewestFirst orders wages newest first, the reverse of sortWagesChronologically
+func sortWagesNewestFirst(wages []*WageRecord) {
+	sort.Slice(wages, func(i, j int) bool {
+		return chronologicallyBefore(wages[j].Timestamp, wages[j].WageID, wages[i].Timestamp, wages[i].WageID)
+	})
+}
+
+// sortUPIChronologically orders UPI transactions oldest first
+func sortUPIChronologically(transactions []*UPITransaction) {
+	sort.Slice(transactions, func(i, j int) bool {
+		return chronologicallyBefore(transactions[i].Timestamp, transactions[i].TxID, transactions[j].Timestamp, transactions[j].TxID)
+	})
+}
+
+// sortAnomaliesChronologically orders anomalies oldest first
+func sortAnomaliesChronologically(anomalies []*Anomaly) {
+	sort.Slice(anomalies, func(i, j int) bool {
+		return chronologicallyBefore(anomalies[i].Timestamp, anomalyKeyID(anomalies[i]), anomalies[j].Timestamp, anomalyKeyID(anomalies[j]))
+	})
+}
+
+// marshalState serializes a value that is written to state or emitted as an event.
+// Every endorsing peer must produce byte-identical payloads, so all such writes go
+// through this single helper. encoding/json emits struct fields in declaration order
+// and sorts map keys (including nested maps such as AuditSummary counters and
+// permission flags), which makes its output canonical for the types stored here.
+// Do not replace this with a streaming encoder or a custom map walk without
+// preserving sorted-key output.
+func marshalState(v interface{}) ([]byte, error) {
+	return json.Marshal(v)
+}
+
+// maxExactPaise bounds aggregated totals: every integer paise value up to 2^53 is
+// exactly representable as a float64 (about 90 trillion rupees)
+const maxExactPaise int64 = 1 << 53
+
+// toPaise converts an amount to integer paise (minor units), rounding to the nearest paisa.
+// It reports false for negative, NaN, infinite, or out-of-range amounts.
+func toPaise(amount float64) (int64, bool) {
+	if amount < 0 || math.IsNaN(amount) || math.IsInf(amount, 0) {
+		return 0, false
+	}
+	paise := math.Round(amount * 100)
+	if paise > float64(maxExactPaise) {
+		return 0, false
+	}
+	return int64(paise), true
+}
+
+// addAmount adds a wage amount to a running total and returns the new total.
+// Both are summed in integer paise, so totals are exact to the paisa no matter how many
+// records are aggregated, as long as they stay below maxExactPaise. Negative or invalid
+// amounts, and additions that would exceed maxExactPaise, are skipped with a warning
+// rather than distorting the total.
+func addAmount(total float64, wageID string, amount float64) float64 {
+	amountPaise, ok := toPaise(amount)
+	if !ok {
+		fmt.Printf("warning: skipping wage %s with invalid amount %v in aggregation\n", wageID, amount)
+		return total
+	}
+	totalPaise, ok := toPaise(total)
+	if !ok || totalPaise > maxExactPaise-amountPaise {
+		fmt.Printf("warning: skipping wage %s: total would exceed exact range\n", wageID)
+		return total
+	}
+	return float64(totalPaise+amountPaise) / 100
+}
+
+// currencyDecimals lists the ISO 4217 minor-unit digits of currencies that do not use 2
+var currencyDecimals = map[string]int{
+	"JPY": 0, "KRW": 0, "VND": 0,
+	"BHD": 3, "JOD": 3, "KWD": 3, "OMR": 3, "TND": 3,
+}
+
+// roundMoney rounds a monetary output to the minor unit of its currency: 2 decimal places
+// unless listed in currencyDecimals, including for mixed-currency totals (currency "").
+// Halves round away from zero (math.Round) on the float64 value, so every peer returns the
+// same figure regardless of summation order. Sums built with addAmount are already exact to
+// the paisa; this is the final step for derived values (averages, conversions, differences)
+// and per-currency totals before they are returned.
+func roundMoney(amount float64, currency string) float64 {
+	decimals, ok := currencyDecimals[currency]
+	if !ok {
+		decimals = 2
+	}
+	scale := math.Pow10(decimals)
+	return math.Round(amount*scale) / scale
+}
+
+// roundMoneyTotals applies roundMoney to a map of totals keyed by currency
+func roundMoneyTotals(totals map[string]float64) {
+	for currency, amount := range totals {
+		totals[currency] = roundMoney(amount, currency)
+	}
+}
+
 // ============================================================================
 // INITIALIZATION FUNCTIONS
 // ============================================================================
 
+// LedgerInitializedKey is the sentinel written by the first successful InitLedger.
+const LedgerInitializedKey = "LEDGER_INITIALIZED"
+
+// LedgerSentinel records when and by whom the ledger was first initialized.
+type LedgerSentinel struct {
+	DocType       string `json:"docType"`
+	InitializedAt string `json:"initializedAt"`
+	InitializedBy string `json:"initializedBy"`
+}
+
 // InitLedger seeds the ledger with sample wage records for smoke tests.
+// It refuses to run again once the ledger has been initialized; use ForceInitLedger
+// to deliberately re-seed.
 // SECURITY: Only admin users from Org1MSP can initialize the ledger.
 func (s *SmartContract) InitLedger(ctx contractapi.TransactionContextInterface) error {
+	return s.initLedger(ctx, "InitLedger", false)
+}
+
+// ForceInitLedger re-seeds the ledger even if it was already initialized,
+// overwriting the sample records and default thresholds.
+// SECURITY: Only admin users from Org1MSP can force re-initialization.
+func (s *SmartContract) ForceInitLedger(ctx contractapi.TransactionContextInterface) error {
+	return s.initLedger(ctx, "ForceInitLedger", true)
+}
+
+// initLedger implements InitLedger and ForceInitLedger.
+func (s *SmartContract) initLedger(ctx contractapi.TransactionContextInterface, function string, force bool) error {
 	// IAM Check: Only admins can initialize ledger
+	initializedBy := "system"
 	if IAMEnabled {
-		identity, err := CheckAccess(ctx, "InitLedger")
+		identity, err := CheckAccess(ctx, function)
 		if err != nil {
-			s.LogAccessDenied(ctx, "InitLedger", "ledger", "system", err.Error())
+			s.LogAccessDenied(ctx, function, "ledger", "system", err.Error())
 			return fmt.Errorf("access denied: %w", err)
 		}
-		s.LogAccessGranted(ctx, "InitLedger", "ledger", "system")
-		fmt.Printf("[IAM] InitLedger called by %s (role: %s, MSP: %s)\n", identity.ID, identity.Role, identity.MSPID)
+		s.LogAccessGranted(ctx, function, "ledger", "system")
+		fmt.Printf("[IAM] %s called by %s (role: %s, MSP: %s)\n", function, identity.ID, identity.Role, identity.MSPID)
+		initializedBy = identity.ID
+	}
+
+	sentinel, err := ctx.GetStub().GetState(LedgerInitializedKey)
+	if err != nil {
+		return fmt.Errorf("get state: %w", err)
+	}
+	alreadyInitialized := sentinel != nil
+	if alreadyInitialized && !force {
+		return fmt.Errorf("ledger already initialized; use ForceInitLedger to re-seed")
 	}
 
 	records := []WageRecord{
@@ -162,18 +644,21 @@ func (s *SmartContract) InitLedger(ctx contractapi.TransactionContextInterface)
 			Currency:       "INR",
 			JobType:        "construction",
 			Timestamp:      time.Now().UTC().Format(time.RFC3339),
-			PolicyVersion:  "2025-Q4",
+			PolicyVersion:  CurrentPolicyVersion,
 		},
 	}
 
 	for _, record := range records {
-		payload, err := json.Marshal(record)
+		payload, err := marshalState(record)
 		if err != nil {
 			return fmt.Errorf("marshal wage record: %w", err)
 		}
 		if err := ctx.GetStub().PutState(record.WageID, payload); err != nil {
 			return fmt.Errorf("put state: %w", err)
 		}
+		if err := putWageEmployerIndex(ctx, record.EmployerIDHash, record.WageID); err != nil {
+			return err
+		}
 	}
 
 	// Initialize default poverty thresholds for common states
@@ -184,7 +669,7 @@ func (s *SmartContract) InitLedger(ctx contractapi.TransactionContextInterface)
 
 	for _, threshold := range defaultThresholds {
 		key := fmt.Sprintf("THRESHOLD_%s_%s", threshold.State, threshold.Category)
-		payload, err := json.Marshal(threshold)
+		payload, err := marshalState(threshold)
 		if err != nil {
 			return fmt.Errorf("marshal threshold: %w", err)
 		}
@@ -193,16 +678,203 @@ func (s *SmartContract) InitLedger(ctx contractapi.TransactionContextInterface)
 		}
 	}
 
+	// Seed the currency allow-list without overriding an admin-tuned value
+	currencies, err := ctx.GetStub().GetState(configKey(ConfigAllowedCurrencies))
+	if err != nil {
+		return fmt.Errorf("get state: %w", err)
+	}
+	if currencies == nil {
+		if err := putConfigValue(ctx, ConfigAllowedCurrencies, "INR", initializedBy); err != nil {
+			return err
+		}
+	}
+
+	if alreadyInitialized {
+		s.LogDataWrite(ctx, function, "ledger", "system", "ledger re-seeded (forced)")
+		return nil
+	}
+
+	payload, err := marshalState(LedgerSentinel{
+		DocType:       "sentinel",
+		InitializedAt: GetTxTimestampRFC3339(ctx),
+		InitializedBy: initializedBy,
+	})
+	if err != nil {
+		return fmt.Errorf("marshal sentinel: %w", err)
+	}
+	if err := ctx.GetStub().PutState(LedgerInitializedKey, payload); err != nil {
+		return fmt.Errorf("put sentinel: %w", err)
+	}
+
+	s.LogAccess(ctx, EventLedgerInitialized, function, "ledger", "system", "success", "ledger initialized")
+
 	return nil
 }
 
+// HealthStatus is the result of the GetHealth probe.
+type HealthStatus struct {
+	Status            string `json:"status"` // Always "OK" when the chaincode responds
+	ChaincodeVersion  string `json:"chaincodeVersion"`
+	PolicyVersion     string `json:"policyVersion"`
+	LedgerInitialized bool   `json:"ledgerInitialized"`
+	InitializedAt     string `json:"initializedAt,omitempty"`
+	Timestamp         string `json:"timestamp"`
+}
+
+// GetHealth is a cheap liveness/readiness probe for operators and gateways.
+// It reads only the ledger sentinel and never writes state, so it does not log
+// to the audit trail. LedgerInitialized reports readiness.
+// SECURITY: Requires a valid client identity only.
+func (s *SmartContract) GetHealth(ctx contractapi.TransactionContextInterface) (*HealthStatus, error) {
+	if IAMEnabled {
+		if _, err := GetClientIdentity(ctx); err != nil {
+			return nil, fmt.Errorf("access denied: %w", err)
+		}
+	}
+
+	health := &HealthStatus{
+		Status:           "OK",
+		ChaincodeVersion: ChaincodeVersion,
+		PolicyVersion:    CurrentPolicyVersion,
+		Timestamp:        GetTxTimestampRFC3339(ctx),
+	}
+
+	payload, err := ctx.GetStub().GetState(LedgerInitializedKey)
+	if err != nil {
+		return nil, fmt.Errorf("get state: %w", err)
+	}
+	if payload != nil {
+		var sentinel LedgerSentinel
+		if err := json.Unmarshal(payload, &sentinel); err == nil {
+			health.LedgerInitialized = true
+			health.InitializedAt = sentinel.InitializedAt
+		}
+	}
+
+	return health, nil
+}
+
+// FunctionInfo describes one callable function and the access rule guarding it.
+type FunctionInfo struct {
+	Name                string   `json:"name"`
+	Description         string   `json:"description"`
+	AllowedRoles        []string `json:"allowedRoles"`
+	RequiredPermissions []string `json:"requiredPermissions,omitempty"`
+	MinClearanceLevel   int      `json:"minClearanceLevel"`
+	AllowedMSPs         []string `json:"allowedMSPs"`
+	AllowSelf           bool     `json:"allowSelf"` // Callers outside the bypass roles see only their own data
+
+	RequiredAttributes map[string]string   `json:"requiredAttributes,omitempty"`
+	AllowedMSPRoles    map[string][]string `json:"allowedMSPRoles,omitempty"` // Replaces allowedMSPs/allowedRoles when set
+}
+
+// ListFunctions returns every function that has an access rule, sorted by name, so clients
+// and gateways can build their function catalog from the same config that CheckAccess
+// enforces. Like GetHealth it never writes state.
+// SECURITY: Requires a valid client identity only.
+func (s *SmartContract) ListFunctions(ctx contractapi.TransactionContextInterface) ([]FunctionInfo, error) {
+	if IAMEnabled {
+		if _, err := GetClientIdentity(ctx); err != nil {
+			return nil, fmt.Errorf("access denied: %w", err)
+		}
+	}
+
+	rules := GetAccessRules()
+	functions := make([]FunctionInfo, 0, len(rules))
+	for name, rule := range rules {
+		functions = append(functions, FunctionInfo{
+			Name:                name,
+			Description:         rule.Description,
+			AllowedRoles:        rule.AllowedRoles,
+			RequiredPermissions: rule.RequiredPermissions,
+			MinClearanceLevel:   rule.MinClearanceLevel,
+			AllowedMSPs:         rule.AllowedMSPs,
+			AllowSelf:           rule.AllowSelf,
+			RequiredAttributes:  rule.RequiredAttributes,
+			AllowedMSPRoles:     rule.AllowedMSPRoles,
+		})
+	}
+
+	sort.Slice(functions, func(i, j int) bool {
+		return functions[i].Name < functions[j].Name
+	})
+
+	return functions, nil
+}
+
 // ============================================================================
 // WAGE RECORD FUNCTIONS
 // ============================================================================
 
 // RecordWage writes a new wage transaction onto the ledger.
+// optionsJSON is an optional WageOptions object (e.g. {"program":"MGNREGA","tags":["rural"]});
+// pass an empty string when no options apply.
+// A wage with the same worker, employer and timestamp as an existing one is rejected
+// via the wage~unique index unless options set "force": true. The index only covers
+// wages recorded after it was introduced; older records are not checked. Duplicates
+// within a single BatchRecordWages call are not caught either, because reads in a
+// transaction do not see that transaction's own writes.
+// Wages whose options name a quarantined state are rejected (see QuarantineState).
 // SECURITY: Only employers and admins with 'canRecordWage' permission can record wages.
-func (s *SmartContract) RecordWage(ctx contractapi.TransactionContextInterface, wageID string, workerIDHash string, employerIDHash string, amount float64, currency string, jobType string, timestamp string, policyVersion string) error {
+func (s *SmartContract) RecordWage(ctx contractapi.TransactionContextInterface, wageID string, workerIDHash string, employerIDHash string, amount float64, currency string, jobType string, timestamp string, policyVersion string, optionsJSON string) error {
+	opts, err := parseWageOptions(optionsJSON)
+	if err != nil {
+		return err
+	}
+	return s.recordWage(ctx, wageID, workerIDHash, employerIDHash, amount, currency, jobType, timestamp, policyVersion, opts, nil)
+}
+
+// parseWageOptions decodes RecordWage options, rejecting unknown fields so typos are not silently dropped
+func parseWageOptions(optionsJSON string) (WageOptions, error) {
+	var opts WageOptions
+	if strings.TrimSpace(optionsJSON) == "" {
+		return opts, nil
+	}
+	decoder := json.NewDecoder(strings.NewReader(optionsJSON))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&opts); err != nil {
+		return opts, fmt.Errorf("invalid wage options: %w", err)
+	}
+	return opts, nil
+}
+
+// reservedKeyPrefixes are the world-state namespaces of other record types. A wageID starting
+// with one of these would be read back as (or overwrite) a record of that type.
+var reservedKeyPrefixes = []string{"UPI_", "AUDIT_", "USER_", "ANOMALY_", "THRESHOLD_", "CONFIG_", "ALIAS_", "FXRATE_", "PRIVWAGE_", "COUNTER_", "NOTIFY_", "QUARANTINE_", "CONSENT_"}
+
+// reservedKeys are singleton keys that a wageID must never equal
+var reservedKeys = []string{LedgerInitializedKey}
+
+// validateWageID rejects empty wageIDs and those that collide with reserved keys, reserved
+// key prefixes (built in plus the reservedWageIDPrefixes config), or the composite key space.
+func validateWageID(ctx contractapi.TransactionContextInterface, wageID string) error {
+	if wageID == "" {
+		return fmt.Errorf("wageID is required")
+	}
+	if strings.HasPrefix(wageID, "\x00") {
+		return fmt.Errorf("invalid wageID %q: must not start with U+0000 (composite key namespace)", wageID)
+	}
+	for _, key := range reservedKeys {
+		if wageID == key {
+			return fmt.Errorf("invalid wageID %q: reserved ledger key", wageID)
+		}
+	}
+
+	extra, err := getConfigList(ctx, ConfigReservedWageIDPrefixes)
+	if err != nil {
+		return err
+	}
+	for _, prefix := range append(append([]string{}, reservedKeyPrefixes...), extra...) {
+		if strings.HasPrefix(wageID, prefix) {
+			return fmt.Errorf("invalid wageID %q: prefix %s is reserved for other records", wageID, prefix)
+		}
+	}
+	return nil
+}
+
+// recordWage implements RecordWage and BatchRecordWages. capTotals carries the monthly
+// income cap totals across a batch; pass nil for a single wage.
+func (s *SmartContract) recordWage(ctx contractapi.TransactionContextInterface, wageID string, workerIDHash string, employerIDHash string, amount float64, currency string, jobType string, timestamp string, policyVersion string, opts WageOptions, capTotals *monthlyIncomeTotals) error {
 	// IAM Check
 	if IAMEnabled {
 		identity, err := CheckAccess(ctx, "RecordWage")
@@ -211,6 +883,12 @@ func (s *SmartContract) RecordWage(ctx contractapi.TransactionContextInterface,
 			return fmt.Errorf("access denied: %w", err)
 		}
 
+		// Employers may only record wages they paid themselves
+		if err := CheckEmployerIdentity(identity, "RecordWage", employerIDHash); err != nil {
+			s.LogAccessDenied(ctx, "RecordWage", wageID, "wage", err.Error())
+			return fmt.Errorf("access denied: %w", err)
+		}
+
 		// Validate wage amount against employer's limit
 		if err := ValidateWageAmountLimit(ctx, amount); err != nil {
 			s.LogAccessDenied(ctx, "RecordWage", wageID, "wage", err.Error())
@@ -222,8 +900,8 @@ func (s *SmartContract) RecordWage(ctx contractapi.TransactionContextInterface,
 		fmt.Printf("[IAM] RecordWage by %s for worker %s, amount %.2f\n", identity.ID, workerIDHash, amount)
 	}
 
-	if wageID == "" {
-		return fmt.Errorf("wageID is required")
+	if err := validateWageID(ctx, wageID); err != nil {
+		return err
 	}
 	if workerIDHash == "" {
 		return fmt.Errorf("workerIDHash is required")
@@ -234,6 +912,27 @@ func (s *SmartContract) RecordWage(ctx contractapi.TransactionContextInterface,
 	if amount <= 0 {
 		return fmt.Errorf("amount must be positive")
 	}
+	if err := checkBlockedWageValues(ctx, currency, jobType); err != nil {
+		s.LogAccess(ctx, EventBlockedValue, "RecordWage", wageID, "wage", "denied", err.Error())
+		return err
+	}
+	if err := checkStateQuarantine(ctx, strings.TrimSpace(opts.State)); err != nil {
+		s.LogAccess(ctx, EventQuarantinedWrite, "RecordWage", wageID, "wage", "denied", err.Error())
+		return err
+	}
+	if err := ValidateCurrency(ctx, currency); err != nil {
+		return err
+	}
+	if err := ValidateWageTags(ctx, opts.Tags); err != nil {
+		return err
+	}
+	documentHash, err := normalizeDocumentHash(opts.DocumentHash)
+	if err != nil {
+		return err
+	}
+	if documentHash == "" && strings.TrimSpace(opts.DocumentType) != "" {
+		return fmt.Errorf("documentType requires documentHash")
+	}
 
 	exists, err := s.WageExists(ctx, wageID)
 	if err != nil {
@@ -247,6 +946,29 @@ func (s *SmartContract) RecordWage(ctx contractapi.TransactionContextInterface,
 		timestamp = time.Now().UTC().Format(time.RFC3339)
 	}
 
+	// Reject accidental double-recording of the same payment under a new wageID
+	uniqueKey, err := ctx.GetStub().CreateCompositeKey("wage~unique", []string{workerIDHash, employerIDHash, timestamp})
+	if err != nil {
+		return fmt.Errorf("create composite key: %w", err)
+	}
+	existingWageID, err := ctx.GetStub().GetState(uniqueKey)
+	if err != nil {
+		return fmt.Errorf("get state: %w", err)
+	}
+	if existingWageID != nil && !opts.Force {
+		return fmt.Errorf("duplicate wage: %s already records a payment from this employer to this worker at %s (set force to override)", string(existingWageID), timestamp)
+	}
+
+	capReason, err := checkMonthlyIncomeCap(ctx, capTotals, wageID, workerIDHash, amount, timestamp)
+	if err != nil {
+		return err
+	}
+
+	createdBy, err := ctx.GetClientIdentity().GetID()
+	if err != nil {
+		return fmt.Errorf("get client ID: %w", err)
+	}
+
 	record := WageRecord{
 		DocType:        "wage",
 		WageID:         wageID,
@@ -257,9 +979,16 @@ func (s *SmartContract) RecordWage(ctx contractapi.TransactionContextInterface,
 		JobType:        jobType,
 		Timestamp:      timestamp,
 		PolicyVersion:  policyVersion,
+		Program:        strings.TrimSpace(opts.Program),
+		Tags:           opts.Tags,
+		State:          strings.TrimSpace(opts.State),
+		DocumentHash:   documentHash,
+		DocumentType:   strings.TrimSpace(opts.DocumentType),
+		CreatedBy:      createdBy,
+		Status:         WageStatusPending,
 	}
 
-	payload, err := json.Marshal(record)
+	payload, err := marshalState(record)
 	if err != nil {
 		return fmt.Errorf("marshal wage record: %w", err)
 	}
@@ -269,522 +998,3874 @@ func (s *SmartContract) RecordWage(ctx contractapi.TransactionContextInterface,
 		fmt.Printf("warning: failed to emit WageRecorded event: %v\n", err)
 	}
 
-	return ctx.GetStub().PutState(wageID, payload)
-}
+	if err := ctx.GetStub().PutState(wageID, payload); err != nil {
+		return fmt.Errorf("put state: %w", err)
+	}
 
-// ReadWage retrieves a wage record by its ID.
-// SECURITY: All authenticated users can read wages.
-func (s *SmartContract) ReadWage(ctx contractapi.TransactionContextInterface, wageID string) (*WageRecord, error) {
-	// IAM Check
-	if IAMEnabled {
-		_, err := CheckAccess(ctx, "ReadWage")
-		if err != nil {
-			s.LogAccessDenied(ctx, "ReadWage", wageID, "wage", err.Error())
-			return nil, fmt.Errorf("access denied: %w", err)
+	if err := applyHighValueEndorsementPolicy(ctx, wageID, amount); err != nil {
+		return err
+	}
+
+	// Keep the index pointing at the first wage when a duplicate is forced through
+	if existingWageID == nil {
+		if err := ctx.GetStub().PutState(uniqueKey, []byte(wageID)); err != nil {
+			return fmt.Errorf("put unique index: %w", err)
 		}
-		s.LogDataRead(ctx, "ReadWage", wageID, "wage")
 	}
 
-	payload, err := ctx.GetStub().GetState(wageID)
+	if err := putWageEmployerIndex(ctx, employerIDHash, wageID); err != nil {
+		return err
+	}
+
+	currencyIndexKey, err := ctx.GetStub().CreateCompositeKey("wage~currency", []string{currency, wageID})
 	if err != nil {
-		return nil, fmt.Errorf("get state: %w", err)
+		return fmt.Errorf("create composite key: %w", err)
 	}
-	if payload == nil {
+	if err := ctx.GetStub().PutState(currencyIndexKey, []byte{0x00}); err != nil {
+		return fmt.Errorf("put currency index: %w", err)
+	}
+
+	if err := putWageStatusIndex(ctx, record.Status, wageID); err != nil {
+		return err
+	}
+
+	if capReason != "" {
+		if err := s.flagMonthlyIncomeCap(ctx, wageID, capReason); err != nil {
+			return err
+		}
+	}
+
+	if err := putNotification(ctx, workerIDHash, NotificationWageRecorded, wageID); err != nil {
+		return err
+	}
+
+	creatorIndexKey, err := ctx.GetStub().CreateCompositeKey("wage~creator", []string{createdBy, wageID})
+	if err != nil {
+		return fmt.Errorf("create composite key: %w", err)
+	}
+	if err := ctx.GetStub().PutState(creatorIndexKey, []byte{0x00}); err != nil {
+		return fmt.Errorf("put creator index: %w", err)
+	}
+
+	if day, ok := wageIndexDate(timestamp); ok {
+		dateIndexKey, err := ctx.GetStub().CreateCompositeKey("wage~date", []string{day, wageID})
+		if err != nil {
+			return fmt.Errorf("create composite key: %w", err)
+		}
+		if err := ctx.GetStub().PutState(dateIndexKey, []byte{0x00}); err != nil {
+			return fmt.Errorf("put date index: %w", err)
+		}
+	}
+
+	if record.State != "" {
+		stateIndexKey, err := ctx.GetStub().CreateCompositeKey("wage~state", []string{record.State, wageID})
+		if err != nil {
+			return fmt.Errorf("create composite key: %w", err)
+		}
+		if err := ctx.GetStub().PutState(stateIndexKey, []byte{0x00}); err != nil {
+			return fmt.Errorf("put state index: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// monthlyIncomeTotals holds each worker's wage total per UTC calendar month for the monthly
+// income cap: the wages on the ledger plus those recorded earlier in the same transaction,
+// which the transaction's own reads cannot see. Workers are keyed by canonical hash so
+// aliases share one total.
+type monthlyIncomeTotals struct {
+	canonical map[string]string             // Any known worker hash -> canonical hash
+	totals    map[string]map[string]float64 // Canonical hash -> "2006-01" -> total
+}
+
+// loadMonthlyIncomeTotals builds the totals of the given workers, and their aliases, from a
+// single wage scan.
+func loadMonthlyIncomeTotals(ctx contractapi.TransactionContextInterface, workerIDHashes []string) (*monthlyIncomeTotals, error) {
+	t := &monthlyIncomeTotals{
+		canonical: make(map[string]string),
+		totals:    make(map[string]map[string]float64),
+	}
+
+	var allHashes []string
+	for _, workerIDHash := range workerIDHashes {
+		if _, known := t.canonical[workerIDHash]; known {
+			continue
+		}
+		workerHashes, err := resolveWorkerHashes(ctx, workerIDHash)
+		if err != nil {
+			return nil, err
+		}
+		t.canonical[workerIDHash] = workerHashes[0]
+		if t.totals[workerHashes[0]] != nil {
+			continue
+		}
+		t.totals[workerHashes[0]] = make(map[string]float64)
+		for _, hash := range workerHashes {
+			t.canonical[hash] = workerHashes[0]
+		}
+		allHashes = append(allHashes, workerHashes...)
+	}
+
+	wages, err := queryWagesForWorkers(ctx, allHashes)
+	if err != nil {
+		return nil, fmt.Errorf("query wages: %w", err)
+	}
+	for _, wage := range wages {
+		if wageTime, err := time.Parse(time.RFC3339, wage.Timestamp); err == nil {
+			t.add(wage.WorkerIDHash, wageTime.UTC().Format("2006-01"), wage.WageID, wage.Amount)
+		}
+	}
+	return t, nil
+}
+
+// total returns a worker's wages in a month
+func (t *monthlyIncomeTotals) total(workerIDHash string, month string) float64 {
+	return t.totals[t.canonical[workerIDHash]][month]
+}
+
+// add counts a wage towards a worker's month
+func (t *monthlyIncomeTotals) add(workerIDHash string, month string, wageID string, amount float64) {
+	canonical, ok := t.canonical[workerIDHash]
+	if !ok {
+		canonical = workerIDHash
+		t.canonical[workerIDHash] = canonical
+	}
+	if t.totals[canonical] == nil {
+		t.totals[canonical] = make(map[string]float64)
+	}
+	t.totals[canonical][month] = addAmount(t.totals[canonical][month], wageID, amount)
+}
+
+// checkMonthlyIncomeCap applies the monthlyIncomeCap config to a new wage. The cap covers
+// the worker's wages (across aliases) in the UTC calendar month of the new wage's own
+// timestamp, or of the transaction when that does not parse. totals carries the running
+// totals of a batch and is updated with the wage when it is accepted; when nil, the
+// worker's totals are loaded for this wage alone. Under the REJECT action an over-cap wage
+// returns an error stating the remaining cap; under FLAG it returns the anomaly reason for
+// the caller to record.
+func checkMonthlyIncomeCap(ctx contractapi.TransactionContextInterface, totals *monthlyIncomeTotals, wageID string, workerIDHash string, amount float64, timestamp string) (string, error) {
+	limit, err := getConfigFloat(ctx, ConfigMonthlyIncomeCap)
+	if err != nil {
+		return "", err
+	}
+	if limit <= 0 {
+		return "", nil
+	}
+
+	wageTime, err := time.Parse(time.RFC3339, timestamp)
+	if err != nil {
+		txTimestamp, err := ctx.GetStub().GetTxTimestamp()
+		if err != nil {
+			return "", fmt.Errorf("get tx timestamp: %w", err)
+		}
+		wageTime = txTimestamp.AsTime()
+	}
+	month := wageTime.UTC().Format("2006-01")
+
+	if totals == nil {
+		if totals, err = loadMonthlyIncomeTotals(ctx, []string{workerIDHash}); err != nil {
+			return "", err
+		}
+	}
+	monthTotal := totals.total(workerIDHash, month)
+
+	if addAmount(monthTotal, wageID, amount) <= limit {
+		totals.add(workerIDHash, month, wageID, amount)
+		return "", nil
+	}
+
+	remaining := math.Max(limit-monthTotal, 0)
+	action, err := getConfigValue(ctx, ConfigMonthlyIncomeCapAction)
+	if err != nil {
+		return "", err
+	}
+	if action == IncomeCapActionFlag {
+		totals.add(workerIDHash, month, wageID, amount)
+		return fmt.Sprintf("Monthly income cap exceeded: %s already paid %.2f in %s, cap %.2f, wage %.2f",
+			workerIDHash, monthTotal, month, limit, amount), nil
+	}
+	return "", fmt.Errorf("monthly income cap exceeded: wage %.2f exceeds the remaining cap of %.2f for %s (cap %.2f)",
+		amount, remaining, month, limit)
+}
+
+// flagMonthlyIncomeCap raises a system anomaly on a wage recorded over the monthly income cap
+func (s *SmartContract) flagMonthlyIncomeCap(ctx contractapi.TransactionContextInterface, wageID string, reason string) error {
+	anomaly := Anomaly{
+		DocType:      "anomaly",
+		WageID:       wageID,
+		AnomalyScore: 1.0,
+		Reason:       reason,
+		Category:     anomalyReasonCategory(reason),
+		FlaggedBy:    "system",
+		Status:       "pending",
+		Timestamp:    GetTxTimestampRFC3339(ctx),
+	}
+	if err := putAnomaly(ctx, &anomaly); err != nil {
+		return err
+	}
+
+	s.LogAccess(ctx, EventAnomalyFlagged, "RecordWage", wageID, "anomaly", "success", reason)
+	return nil
+}
+
+// applyHighValueEndorsementPolicy attaches a key-level (state-based) endorsement policy to a
+// wage record whose amount exceeds the highValueWageThreshold config. The policy requires a
+// peer of every MSP in highValueEndorsingOrgs to endorse any later write or delete of this
+// key, overriding the chaincode-level policy for that key only. Clients updating such a
+// record must therefore collect endorsements from all listed orgs, or the transaction is
+// invalidated at commit. Changing the config does not alter policies already attached.
+func applyHighValueEndorsementPolicy(ctx contractapi.TransactionContextInterface, wageID string, amount float64) error {
+	threshold, err := getConfigFloat(ctx, ConfigHighValueWageThreshold)
+	if err != nil {
+		return err
+	}
+	if threshold <= 0 || amount <= threshold {
+		return nil
+	}
+
+	orgs, err := getConfigList(ctx, ConfigHighValueEndorsingOrgs)
+	if err != nil {
+		return err
+	}
+
+	endorsementPolicy, err := statebased.NewStateEP(nil)
+	if err != nil {
+		return fmt.Errorf("create endorsement policy: %w", err)
+	}
+	if err := endorsementPolicy.AddOrgs(statebased.RoleTypePeer, orgs...); err != nil {
+		return fmt.Errorf("add endorsing orgs: %w", err)
+	}
+	policy, err := endorsementPolicy.Policy()
+	if err != nil {
+		return fmt.Errorf("serialize endorsement policy: %w", err)
+	}
+	if err := ctx.GetStub().SetStateValidationParameter(wageID, policy); err != nil {
+		return fmt.Errorf("set endorsement policy: %w", err)
+	}
+
+	fmt.Printf("[POLICY] Wage %s (amount %.2f) requires endorsement from %s\n", wageID, amount, strings.Join(orgs, ", "))
+	return nil
+}
+
+// putWageEmployerIndex writes the wage~employer composite index entry used by
+// QueryWagesByEmployerPaginated.
+func putWageEmployerIndex(ctx contractapi.TransactionContextInterface, employerIDHash string, wageID string) error {
+	indexKey, err := ctx.GetStub().CreateCompositeKey("wage~employer", []string{employerIDHash, wageID})
+	if err != nil {
+		return fmt.Errorf("create composite key: %w", err)
+	}
+	if err := ctx.GetStub().PutState(indexKey, []byte{0x00}); err != nil {
+		return fmt.Errorf("put employer index: %w", err)
+	}
+	return nil
+}
+
+// putWageStatusIndex writes the wage~status composite index entry used by
+// QueryWagesByStatus.
+func putWageStatusIndex(ctx contractapi.TransactionContextInterface, status string, wageID string) error {
+	indexKey, err := ctx.GetStub().CreateCompositeKey("wage~status", []string{status, wageID})
+	if err != nil {
+		return fmt.Errorf("create composite key: %w", err)
+	}
+	if err := ctx.GetStub().PutState(indexKey, []byte{0x00}); err != nil {
+		return fmt.Errorf("put status index: %w", err)
+	}
+	return nil
+}
+
+// ReadWage retrieves a wage record by its ID.
+// SECURITY: Workers can only read their own wages; other allowed roles can read any wage.
+func (s *SmartContract) ReadWage(ctx contractapi.TransactionContextInterface, wageID string) (*WageRecord, error) {
+	// IAM Check
+	var identity *ClientIdentity
+	if IAMEnabled {
+		var err error
+		identity, err = CheckAccess(ctx, "ReadWage")
+		if err != nil {
+			s.LogAccessDenied(ctx, "ReadWage", wageID, "wage", err.Error())
+			return nil, fmt.Errorf("access denied: %w", err)
+		}
+	}
+
+	record, err := readWageRecord(ctx, wageID)
+	if err != nil {
+		return nil, err
+	}
+
+	if IAMEnabled {
+		// Self-access is checked after the read since the owner is only known from the record
+		if identity.Role == "worker" {
+			if err := CheckSelfAccess(ctx, identity, "ReadWage", record.WorkerIDHash); err != nil {
+				s.LogAccessDenied(ctx, "ReadWage", wageID, "wage", err.Error())
+				return nil, fmt.Errorf("access denied: %w", err)
+			}
+		}
+		s.LogDataRead(ctx, "ReadWage", wageID, "wage")
+	}
+
+	return record, nil
+}
+
+// MaxWagesPerRead caps how many wage IDs ReadWages and WagesExist accept in one call
+const MaxWagesPerRead = 100
+
+// WageBatch is the result of ReadWages. Every requested ID appears in exactly one of
+// Wages, NotFound or Denied.
+type WageBatch struct {
+	Wages    map[string]*WageRecord `json:"wages"`
+	NotFound []string               `json:"notFound"`
+	Denied   []string               `json:"denied"`
+}
+
+// ReadWages retrieves several wage records by ID in one call (e.g. for a UI list), instead
+// of one ReadWage call each. Missing IDs are reported in NotFound rather than failing the
+// whole call. Duplicate IDs are read once.
+// SECURITY: Same as ReadWage, applied per record; a worker's wages of other workers are
+// reported in Denied.
+func (s *SmartContract) ReadWages(ctx contractapi.TransactionContextInterface, wageIDs []string) (*WageBatch, error) {
+	// IAM Check
+	var identity *ClientIdentity
+	if IAMEnabled {
+		var err error
+		identity, err = CheckAccess(ctx, "ReadWages")
+		if err != nil {
+			s.LogAccessDenied(ctx, "ReadWages", "batch", "wage", err.Error())
+			return nil, fmt.Errorf("access denied: %w", err)
+		}
+	}
+
+	if len(wageIDs) == 0 {
+		return nil, fmt.Errorf("at least one wageID is required")
+	}
+	if len(wageIDs) > MaxWagesPerRead {
+		return nil, fmt.Errorf("too many wage IDs: %d (max %d)", len(wageIDs), MaxWagesPerRead)
+	}
+
+	result := &WageBatch{
+		Wages:    make(map[string]*WageRecord, len(wageIDs)),
+		NotFound: []string{},
+		Denied:   []string{},
+	}
+	seen := make(map[string]bool, len(wageIDs))
+	for _, wageID := range wageIDs {
+		if wageID == "" {
+			return nil, fmt.Errorf("wageID must not be empty")
+		}
+		if seen[wageID] {
+			continue
+		}
+		seen[wageID] = true
+
+		payload, err := ctx.GetStub().GetState(wageID)
+		if err != nil {
+			return nil, fmt.Errorf("get state: %w", err)
+		}
+		record := new(WageRecord)
+		if payload == nil || json.Unmarshal(payload, record) != nil || record.DocType != "wage" {
+			result.NotFound = append(result.NotFound, wageID)
+			continue
+		}
+
+		if IAMEnabled {
+			if identity.Role == "worker" {
+				if err := CheckSelfAccess(ctx, identity, "ReadWages", record.WorkerIDHash); err != nil {
+					s.LogAccessDenied(ctx, "ReadWages", wageID, "wage", err.Error())
+					result.Denied = append(result.Denied, wageID)
+					continue
+				}
+			}
+			s.LogDataRead(ctx, "ReadWages", wageID, "wage")
+		}
+		result.Wages[wageID] = record
+	}
+
+	return result, nil
+}
+
+// GetWageRecordRaw returns the wage record exactly as stored in world state, for verifying
+// ledger state against block hashes off-chain. Unlike ReadWage the bytes are not re-marshaled,
+// so field order and formatting match what was written.
+// SECURITY: Same as ReadWage; workers can only read their own wages.
+func (s *SmartContract) GetWageRecordRaw(ctx contractapi.TransactionContextInterface, wageID string) ([]byte, error) {
+	// IAM Check
+	var identity *ClientIdentity
+	if IAMEnabled {
+		var err error
+		identity, err = CheckAccess(ctx, "GetWageRecordRaw")
+		if err != nil {
+			s.LogAccessDenied(ctx, "GetWageRecordRaw", wageID, "wage", err.Error())
+			return nil, fmt.Errorf("access denied: %w", err)
+		}
+	}
+
+	payload, err := ctx.GetStub().GetState(wageID)
+	if err != nil {
+		return nil, fmt.Errorf("get state: %w", err)
+	}
+	if payload == nil {
+		return nil, fmt.Errorf("wage record %s not found", wageID)
+	}
+
+	// Decode only to confirm the key holds a wage and to find its owner
+	var record WageRecord
+	if err := json.Unmarshal(payload, &record); err != nil || record.DocType != "wage" {
+		return nil, fmt.Errorf("wage record %s not found", wageID)
+	}
+
+	if IAMEnabled {
+		if identity.Role == "worker" {
+			if err := CheckSelfAccess(ctx, identity, "GetWageRecordRaw", record.WorkerIDHash); err != nil {
+				s.LogAccessDenied(ctx, "GetWageRecordRaw", wageID, "wage", err.Error())
+				return nil, fmt.Errorf("access denied: %w", err)
+			}
+		}
+		s.LogDataRead(ctx, "GetWageRecordRaw", wageID, "wage")
+	}
+
+	return payload, nil
+}
+
+// normalizeDocumentHash validates a supporting document hash as hex SHA-256 and returns it
+// lower-cased. An empty hash is allowed and returned as is.
+func normalizeDocumentHash(hash string) (string, error) {
+	hash = strings.ToLower(strings.TrimSpace(hash))
+	if hash == "" {
+		return "", nil
+	}
+	if decoded, err := hex.DecodeString(hash); err != nil || len(decoded) != sha256.Size {
+		return "", fmt.Errorf("documentHash must be a hex-encoded SHA-256 digest")
+	}
+	return hash, nil
+}
+
+// VerifyWageDocument reports whether providedHash (hex SHA-256, case-insensitive) matches
+// the supporting document hash recorded with a wage. It returns an error if the wage has
+// no document hash, so "no document" is not mistaken for a mismatch.
+// SECURITY: Workers can only verify their own wages; other allowed roles can verify any wage.
+func (s *SmartContract) VerifyWageDocument(ctx contractapi.TransactionContextInterface, wageID string, providedHash string) (bool, error) {
+	if wageID == "" {
+		return false, fmt.Errorf("wageID is required")
+	}
+
+	// IAM Check
+	var identity *ClientIdentity
+	if IAMEnabled {
+		var err error
+		identity, err = CheckAccess(ctx, "VerifyWageDocument")
+		if err != nil {
+			s.LogAccessDenied(ctx, "VerifyWageDocument", wageID, "wage", err.Error())
+			return false, fmt.Errorf("access denied: %w", err)
+		}
+	}
+
+	record, err := readWageRecord(ctx, wageID)
+	if err != nil {
+		return false, err
+	}
+
+	if IAMEnabled {
+		// Self-access is checked after the read since the owner is only known from the record
+		if identity.Role == "worker" {
+			if err := CheckSelfAccess(ctx, identity, "VerifyWageDocument", record.WorkerIDHash); err != nil {
+				s.LogAccessDenied(ctx, "VerifyWageDocument", wageID, "wage", err.Error())
+				return false, fmt.Errorf("access denied: %w", err)
+			}
+		}
+		s.LogDataRead(ctx, "VerifyWageDocument", wageID, "wage")
+	}
+
+	if record.DocumentHash == "" {
+		return false, fmt.Errorf("wage record %s has no document hash", wageID)
+	}
+	provided, err := normalizeDocumentHash(providedHash)
+	if err != nil || provided == "" {
+		return false, fmt.Errorf("providedHash must be a hex-encoded SHA-256 digest")
+	}
+
+	return provided == record.DocumentHash, nil
+}
+
+// readWageRecord loads a wage record directly from state without access checks.
+// Callers are responsible for having authorized the surrounding operation.
+func readWageRecord(ctx contractapi.TransactionContextInterface, wageID string) (*WageRecord, error) {
+	payload, err := ctx.GetStub().GetState(wageID)
+	if err != nil {
+		return nil, fmt.Errorf("get state: %w", err)
+	}
+	if payload == nil {
+		return nil, fmt.Errorf("wage record %s not found", wageID)
+	}
+
+	record := new(WageRecord)
+	if err := json.Unmarshal(payload, record); err != nil || record.DocType != "wage" {
+		return nil, fmt.Errorf("wage record %s not found", wageID)
+	}
+
+	return record, nil
+}
+
+// GetWageReceiptData returns a stable data bundle for generating a wage receipt off-chain:
+// the wage, worker and employer display info, linked UPI payments, and a hash of the
+// stored record bytes that can be verified against the ledger.
+// If displayCurrency is set and differs from the wage currency, the amount is also converted
+// using the stored exchange rate (see SetExchangeRate); a missing rate is an error.
+// SECURITY: The wage's worker or employer can fetch their own receipt; privileged roles can fetch any.
+func (s *SmartContract) GetWageReceiptData(ctx contractapi.TransactionContextInterface, wageID string, displayCurrency string) (*ReceiptData, error) {
+	if wageID == "" {
+		return nil, fmt.Errorf("wageID is required")
+	}
+
+	payload, err := ctx.GetStub().GetState(wageID)
+	if err != nil {
+		return nil, fmt.Errorf("get state: %w", err)
+	}
+	if payload == nil {
+		return nil, fmt.Errorf("wage record %s not found", wageID)
+	}
+
+	wage := new(WageRecord)
+	if err := json.Unmarshal(payload, wage); err != nil {
+		return nil, fmt.Errorf("unmarshal wage record: %w", err)
+	}
+	if wage.DocType != "wage" {
 		return nil, fmt.Errorf("wage record %s not found", wageID)
 	}
 
-	record := new(WageRecord)
-	if err := json.Unmarshal(payload, record); err != nil {
-		return nil, fmt.Errorf("unmarshal wage record: %w", err)
+	// IAM Check with self-access validation against either party of the wage
+	if IAMEnabled {
+		identity, err := CheckAccess(ctx, "GetWageReceiptData")
+		if err != nil {
+			s.LogAccessDenied(ctx, "GetWageReceiptData", wageID, "wage", err.Error())
+			return nil, fmt.Errorf("access denied: %w", err)
+		}
+
+		if err := CheckSelfAccess(ctx, identity, "GetWageReceiptData", wage.WorkerIDHash); err != nil {
+			if err := CheckSelfAccess(ctx, identity, "GetWageReceiptData", wage.EmployerIDHash); err != nil {
+				s.LogAccessDenied(ctx, "GetWageReceiptData", wageID, "wage", err.Error())
+				return nil, fmt.Errorf("access denied: %w", err)
+			}
+		}
+		s.LogDataRead(ctx, "GetWageReceiptData", wageID, "wage")
+	}
+
+	digest := sha256.Sum256(payload)
+
+	receipt := &ReceiptData{
+		Wage:          wage,
+		Worker:        resolvePartyInfo(ctx, wage.WorkerIDHash),
+		Employer:      resolvePartyInfo(ctx, wage.EmployerIDHash),
+		RecordHash:    hex.EncodeToString(digest[:]),
+		HashAlgorithm: "sha256",
+		GeneratedAt:   GetTxTimestampRFC3339(ctx),
+	}
+
+	// Collect UPI payments linked to this wage
+	if receipt.UPITransactions, err = queryUPIForWage(ctx, wageID); err != nil {
+		return nil, err
+	}
+
+	if displayCurrency != "" {
+		display := &DisplayAmount{
+			OriginalAmount:   wage.Amount,
+			OriginalCurrency: wage.Currency,
+			Amount:           wage.Amount,
+			Currency:         displayCurrency,
+			Rate:             1,
+		}
+		if displayCurrency != wage.Currency {
+			rate, err := readExchangeRate(ctx, wage.Currency, displayCurrency)
+			if err != nil {
+				return nil, err
+			}
+			display.Rate = rate.Rate
+			display.RateUpdatedAt = rate.UpdatedAt
+			display.Amount = roundMoney(wage.Amount*rate.Rate, displayCurrency)
+		}
+		receipt.Display = display
+	}
+
+	return receipt, nil
+}
+
+// resolvePartyInfo looks up display information for a registered user without access
+// checks. Unregistered hashes are returned with only the hash populated.
+func resolvePartyInfo(ctx contractapi.TransactionContextInterface, idHash string) PartyInfo {
+	info := PartyInfo{IDHash: idHash}
+
+	payload, err := ctx.GetStub().GetState(fmt.Sprintf("USER_%s", idHash))
+	if err != nil || payload == nil {
+		return info
+	}
+
+	var user User
+	if err := json.Unmarshal(payload, &user); err != nil {
+		return info
+	}
+
+	info.Name = user.Name
+	info.Role = user.Role
+	info.Registered = true
+	return info
+}
+
+// GetWageDetail returns a wage together with its history count, the UPI payments linked to it,
+// and its anomalies, in one call. Only the wage itself is required: if a related lookup fails,
+// the rest is still returned with a warning. Anomalies are included only for roles allowed to
+// call GetFlaggedWages, so workers and employers are not tipped off about open reviews.
+// NOTE: Linked UPI payments are found by scanning all UPI_ keys.
+// SECURITY: Workers can only view their own wages; other allowed roles can view any wage.
+func (s *SmartContract) GetWageDetail(ctx contractapi.TransactionContextInterface, wageID string) (*WageDetail, error) {
+	if wageID == "" {
+		return nil, fmt.Errorf("wageID is required")
+	}
+
+	// IAM Check
+	var identity *ClientIdentity
+	if IAMEnabled {
+		var err error
+		identity, err = CheckAccess(ctx, "GetWageDetail")
+		if err != nil {
+			s.LogAccessDenied(ctx, "GetWageDetail", wageID, "wage", err.Error())
+			return nil, fmt.Errorf("access denied: %w", err)
+		}
+	}
+
+	record, err := readWageRecord(ctx, wageID)
+	if err != nil {
+		return nil, err
+	}
+
+	showAnomalies := true
+	if IAMEnabled {
+		// Self-access is checked after the read since the owner is only known from the record
+		if identity.Role == "worker" {
+			if err := CheckSelfAccess(ctx, identity, "GetWageDetail", record.WorkerIDHash); err != nil {
+				s.LogAccessDenied(ctx, "GetWageDetail", wageID, "wage", err.Error())
+				return nil, fmt.Errorf("access denied: %w", err)
+			}
+		}
+		showAnomalies = false
+		for _, role := range GetAccessRules()["GetFlaggedWages"].AllowedRoles {
+			if identity.Role == role {
+				showAnomalies = true
+				break
+			}
+		}
+		s.LogDataRead(ctx, "GetWageDetail", wageID, "wage")
+	}
+
+	detail := &WageDetail{Wage: record, UPITransactions: []*UPITransaction{}, Anomalies: []*Anomaly{}}
+
+	if count, err := countWageHistory(ctx, wageID); err != nil {
+		detail.Warnings = append(detail.Warnings, fmt.Sprintf("history unavailable: %v", err))
+	} else {
+		detail.HistoryCount = count
+	}
+
+	if transactions, err := queryUPIForWage(ctx, wageID); err != nil {
+		detail.Warnings = append(detail.Warnings, fmt.Sprintf("UPI transactions unavailable: %v", err))
+	} else {
+		detail.UPITransactions = transactions
+	}
+
+	if showAnomalies {
+		if anomalies, err := queryAnomaliesForWage(ctx, wageID); err != nil {
+			detail.Warnings = append(detail.Warnings, fmt.Sprintf("anomalies unavailable: %v", err))
+		} else {
+			detail.Anomalies = anomalies
+		}
+	}
+
+	return detail, nil
+}
+
+// countWageHistory returns how many versions of a wage record the ledger holds
+func countWageHistory(ctx contractapi.TransactionContextInterface, wageID string) (int, error) {
+	historyIter, err := ctx.GetStub().GetHistoryForKey(wageID)
+	if err != nil {
+		return 0, fmt.Errorf("get history: %w", err)
+	}
+	defer historyIter.Close()
+
+	count := 0
+	for historyIter.HasNext() {
+		record, err := historyIter.Next()
+		if err != nil {
+			return 0, fmt.Errorf("iterate history: %w", err)
+		}
+		if record.Value != nil {
+			count++
+		}
+	}
+	return count, nil
+}
+
+// queryUPIForWage returns the UPI transactions whose OnChainReference is wageID, oldest first
+func queryUPIForWage(ctx contractapi.TransactionContextInterface, wageID string) ([]*UPITransaction, error) {
+	iterator, err := ctx.GetStub().GetStateByRange("UPI_", "UPI_~")
+	if err != nil {
+		return nil, fmt.Errorf("get state range: %w", err)
+	}
+	defer iterator.Close()
+
+	transactions := []*UPITransaction{}
+	for iterator.HasNext() {
+		queryResponse, err := iterator.Next()
+		if err != nil {
+			return nil, fmt.Errorf("iterate: %w", err)
+		}
+
+		var tx UPITransaction
+		if err := json.Unmarshal(queryResponse.Value, &tx); err != nil {
+			continue
+		}
+		if tx.OnChainReference == wageID {
+			transactions = append(transactions, &tx)
+		}
+	}
+
+	sortUPIChronologically(transactions)
+
+	return transactions, nil
+}
+
+// queryAnomaliesForWage returns every anomaly raised on a wage, in any status, oldest first.
+// It reads the primary ANOMALY_<wageID> record plus any additional anomalies listed in the
+// anomaly~wage~status index.
+func queryAnomaliesForWage(ctx contractapi.TransactionContextInterface, wageID string) ([]*Anomaly, error) {
+	ids := []string{wageID}
+	iterator, err := ctx.GetStub().GetStateByPartialCompositeKey("anomaly~wage~status", []string{wageID})
+	if err != nil {
+		return nil, fmt.Errorf("get anomaly status index: %w", err)
+	}
+	defer iterator.Close()
+
+	seen := map[string]bool{wageID: true}
+	for iterator.HasNext() {
+		queryResponse, err := iterator.Next()
+		if err != nil {
+			return nil, fmt.Errorf("iterate: %w", err)
+		}
+		_, parts, err := ctx.GetStub().SplitCompositeKey(queryResponse.Key)
+		if err == nil && len(parts) == 3 && !seen[parts[2]] {
+			seen[parts[2]] = true
+			ids = append(ids, parts[2])
+		}
+	}
+
+	anomalies := []*Anomaly{}
+	for _, id := range ids {
+		payload, err := ctx.GetStub().GetState(fmt.Sprintf("ANOMALY_%s", id))
+		if err != nil {
+			return nil, fmt.Errorf("get state: %w", err)
+		}
+		if payload == nil {
+			continue
+		}
+		var anomaly Anomaly
+		if err := json.Unmarshal(payload, &anomaly); err != nil || anomaly.WageID != wageID {
+			continue // Unreadable record or stale index entry
+		}
+		anomalies = append(anomalies, &anomaly)
+	}
+
+	sortAnomaliesChronologically(anomalies)
+
+	return anomalies, nil
+}
+
+// WageExists checks whether a wage record is already stored.
+// SECURITY: All authenticated users can check if a wage exists.
+func (s *SmartContract) WageExists(ctx contractapi.TransactionContextInterface, wageID string) (bool, error) {
+	// IAM Check
+	if IAMEnabled {
+		_, err := CheckAccess(ctx, "WageExists")
+		if err != nil {
+			s.LogAccessDenied(ctx, "WageExists", wageID, "wage", err.Error())
+			return false, fmt.Errorf("access denied: %w", err)
+		}
+	}
+	
+	payload, err := ctx.GetStub().GetState(wageID)
+	if err != nil {
+		return false, fmt.Errorf("get state: %w", err)
+	}
+	return payload != nil, nil
+}
+
+// WagesExist checks several wage IDs in one call, e.g. for a bulk importer to find
+// collisions before BatchRecordWages. Every requested ID is a key of the result. As with
+// WageExists, an ID counts as taken if any record is stored under it.
+// SECURITY: All authenticated users can check if wages exist.
+func (s *SmartContract) WagesExist(ctx contractapi.TransactionContextInterface, wageIDs []string) (map[string]bool, error) {
+	// IAM Check
+	if IAMEnabled {
+		_, err := CheckAccess(ctx, "WagesExist")
+		if err != nil {
+			s.LogAccessDenied(ctx, "WagesExist", "batch", "wage", err.Error())
+			return nil, fmt.Errorf("access denied: %w", err)
+		}
+	}
+
+	if len(wageIDs) == 0 {
+		return nil, fmt.Errorf("at least one wageID is required")
+	}
+	if len(wageIDs) > MaxWagesPerRead {
+		return nil, fmt.Errorf("too many wage IDs: %d (max %d)", len(wageIDs), MaxWagesPerRead)
+	}
+
+	exists := make(map[string]bool, len(wageIDs))
+	for _, wageID := range wageIDs {
+		if wageID == "" {
+			return nil, fmt.Errorf("wageID must not be empty")
+		}
+		if _, seen := exists[wageID]; seen {
+			continue
+		}
+		payload, err := ctx.GetStub().GetState(wageID)
+		if err != nil {
+			return nil, fmt.Errorf("get state: %w", err)
+		}
+		exists[wageID] = payload != nil
+	}
+	return exists, nil
+}
+
+// QueryWageHistory streams the state history for a given wage record.
+// SECURITY: Authenticated users with clearance level 2+ can query history.
+func (s *SmartContract) QueryWageHistory(ctx contractapi.TransactionContextInterface, wageID string) ([]*WageRecord, error) {
+	// IAM Check
+	if IAMEnabled {
+		_, err := CheckAccess(ctx, "QueryWageHistory")
+		if err != nil {
+			s.LogAccessDenied(ctx, "QueryWageHistory", wageID, "wage", err.Error())
+			return nil, fmt.Errorf("access denied: %w", err)
+		}
+		s.LogDataRead(ctx, "QueryWageHistory", wageID, "wage")
+	}
+
+	historyIter, err := ctx.GetStub().GetHistoryForKey(wageID)
+	if err != nil {
+		return nil, fmt.Errorf("get history: %w", err)
+	}
+	defer historyIter.Close()
+
+	var history []*WageRecord
+	for historyIter.HasNext() {
+		record, err := historyIter.Next()
+		if err != nil {
+			return nil, fmt.Errorf("iterate history: %w", err)
+		}
+
+		if record.Value == nil {
+			continue
+		}
+
+		entry := new(WageRecord)
+		if err := json.Unmarshal(record.Value, entry); err != nil {
+			return nil, fmt.Errorf("unmarshal history record: %w", err)
+		}
+		history = append(history, entry)
+	}
+
+	return history, nil
+}
+
+// GetWageRecordDiff compares the versions of a wage record written by two transactions
+// and returns only the fields that changed, for targeted dispute investigations.
+// SECURITY: Only auditors, government officials, and admins.
+func (s *SmartContract) GetWageRecordDiff(ctx contractapi.TransactionContextInterface, wageID string, fromTxID string, toTxID string) (*RecordDiff, error) {
+	if wageID == "" || fromTxID == "" || toTxID == "" {
+		return nil, fmt.Errorf("wageID, fromTxID and toTxID are required")
+	}
+
+	// IAM Check
+	if IAMEnabled {
+		_, err := CheckAccess(ctx, "GetWageRecordDiff")
+		if err != nil {
+			s.LogAccessDenied(ctx, "GetWageRecordDiff", wageID, "wage", err.Error())
+			return nil, fmt.Errorf("access denied: %w", err)
+		}
+		s.LogDataRead(ctx, "GetWageRecordDiff", wageID, "wage")
+	}
+
+	historyIter, err := ctx.GetStub().GetHistoryForKey(wageID)
+	if err != nil {
+		return nil, fmt.Errorf("get history: %w", err)
+	}
+	defer historyIter.Close()
+
+	diff := &RecordDiff{WageID: wageID, FromTxID: fromTxID, ToTxID: toTxID, Changes: []FieldChange{}}
+	var fromFields, toFields map[string]json.RawMessage
+	for historyIter.HasNext() {
+		modification, err := historyIter.Next()
+		if err != nil {
+			return nil, fmt.Errorf("iterate history: %w", err)
+		}
+
+		txID := modification.GetTxId()
+		if txID != fromTxID && txID != toTxID {
+			continue
+		}
+		if modification.GetIsDelete() {
+			return nil, fmt.Errorf("wage record %s was deleted in transaction %s", wageID, txID)
+		}
+
+		fields := make(map[string]json.RawMessage)
+		if err := json.Unmarshal(modification.GetValue(), &fields); err != nil {
+			return nil, fmt.Errorf("unmarshal history record: %w", err)
+		}
+		at := time.Unix(modification.GetTimestamp().GetSeconds(), int64(modification.GetTimestamp().GetNanos())).UTC().Format(time.RFC3339)
+
+		if txID == fromTxID {
+			fromFields = fields
+			diff.FromTimestamp = at
+		}
+		if txID == toTxID {
+			toFields = fields
+			diff.ToTimestamp = at
+		}
+	}
+
+	if fromFields == nil {
+		return nil, fmt.Errorf("transaction %s did not write wage record %s", fromTxID, wageID)
+	}
+	if toFields == nil {
+		return nil, fmt.Errorf("transaction %s did not write wage record %s", toTxID, wageID)
+	}
+
+	names := make(map[string]bool)
+	for name := range fromFields {
+		names[name] = true
+	}
+	for name := range toFields {
+		names[name] = true
+	}
+	sortedNames := make([]string, 0, len(names))
+	for name := range names {
+		sortedNames = append(sortedNames, name)
+	}
+	sort.Strings(sortedNames)
+
+	for _, name := range sortedNames {
+		from, to := string(fromFields[name]), string(toFields[name])
+		if from != to {
+			diff.Changes = append(diff.Changes, FieldChange{Field: name, From: from, To: to})
+		}
+	}
+
+	return diff, nil
+}
+
+// QueryWagesByWorker retrieves all wage records for a specific worker (LevelDB compatible).
+// Results are ordered oldest first (by timestamp, then ID).
+// SECURITY: Workers can only query their own wages; privileged roles can query any worker.
+func (s *SmartContract) QueryWagesByWorker(ctx contractapi.TransactionContextInterface, workerIDHash string) ([]*WageRecord, error) {
+	if workerIDHash == "" {
+		return nil, fmt.Errorf("workerIDHash is required")
+	}
+
+	// IAM Check with self-access validation
+	if IAMEnabled {
+		identity, err := CheckAccess(ctx, "QueryWagesByWorker")
+		if err != nil {
+			s.LogAccessDenied(ctx, "QueryWagesByWorker", workerIDHash, "wage", err.Error())
+			return nil, fmt.Errorf("access denied: %w", err)
+		}
+
+		// Check self-access for workers
+		if err := CheckSelfAccess(ctx, identity, "QueryWagesByWorker", workerIDHash); err != nil {
+			s.LogAccessDenied(ctx, "QueryWagesByWorker", workerIDHash, "wage", err.Error())
+			return nil, fmt.Errorf("access denied: %w", err)
+		}
+		s.LogDataRead(ctx, "QueryWagesByWorker", workerIDHash, "wage")
+	}
+
+	return queryWagesForWorkers(ctx, []string{workerIDHash})
+}
+
+// MaxWorkersPerQuery caps how many workers QueryWagesByWorkers accepts in one call
+const MaxWorkersPerQuery = 50
+
+// QueryWagesByWorkers retrieves the wages of several workers (e.g. a team view) with a single
+// scan, keyed by worker hash. Every requested hash is present in the result, with an empty
+// list if the worker has no wages. Aliases are not resolved; see QueryWagesByWorkerFull.
+// Each worker's wages are ordered oldest first.
+// SECURITY: Privileged roles only, since self-access cannot apply to a multi-worker read.
+func (s *SmartContract) QueryWagesByWorkers(ctx contractapi.TransactionContextInterface, workerIDHashes []string) (map[string][]*WageRecord, error) {
+	// IAM Check
+	if IAMEnabled {
+		_, err := CheckAccess(ctx, "QueryWagesByWorkers")
+		if err != nil {
+			s.LogAccessDenied(ctx, "QueryWagesByWorkers", "batch", "wage", err.Error())
+			return nil, fmt.Errorf("access denied: %w", err)
+		}
+	}
+
+	if len(workerIDHashes) == 0 {
+		return nil, fmt.Errorf("at least one workerIDHash is required")
+	}
+	if len(workerIDHashes) > MaxWorkersPerQuery {
+		return nil, fmt.Errorf("too many workers: %d (max %d)", len(workerIDHashes), MaxWorkersPerQuery)
+	}
+
+	// Deduplicate in input order: each audit entry takes the next sequenced key, so the
+	// entries must be written in an order every endorser agrees on (not map order)
+	result := make(map[string][]*WageRecord, len(workerIDHashes))
+	var hashes []string
+	for _, hash := range workerIDHashes {
+		if hash == "" {
+			return nil, fmt.Errorf("workerIDHash must not be empty")
+		}
+		if _, seen := result[hash]; !seen {
+			result[hash] = []*WageRecord{}
+			hashes = append(hashes, hash)
+		}
+	}
+
+	if IAMEnabled {
+		for _, hash := range hashes {
+			s.LogDataRead(ctx, "QueryWagesByWorkers", hash, "wage")
+		}
+	}
+
+	wages, err := queryWagesForWorkers(ctx, hashes)
+	if err != nil {
+		return nil, err
+	}
+	for _, wage := range wages {
+		result[wage.WorkerIDHash] = append(result[wage.WorkerIDHash], wage)
+	}
+
+	return result, nil
+}
+
+// QueryWagesByWorkerFull retrieves all wages for a worker across the canonical hash and
+// every alias, given any one of those hashes. This is the alias-aware counterpart to
+// QueryWagesByWorker and gives the complete income picture for re-enrolled workers.
+// Results are ordered oldest first (by timestamp, then ID).
+// SECURITY: Workers may query with any of their own hashes; privileged roles can query any worker.
+func (s *SmartContract) QueryWagesByWorkerFull(ctx contractapi.TransactionContextInterface, anyWorkerIDHash string) ([]*WageRecord, error) {
+	if anyWorkerIDHash == "" {
+		return nil, fmt.Errorf("workerIDHash is required")
+	}
+
+	workerHashes, err := resolveWorkerHashes(ctx, anyWorkerIDHash)
+	if err != nil {
+		return nil, err
+	}
+
+	// IAM Check with self-access validation against any of the worker's hashes
+	if IAMEnabled {
+		identity, err := CheckAccess(ctx, "QueryWagesByWorkerFull")
+		if err != nil {
+			s.LogAccessDenied(ctx, "QueryWagesByWorkerFull", anyWorkerIDHash, "wage", err.Error())
+			return nil, fmt.Errorf("access denied: %w", err)
+		}
+
+		var selfErr error
+		for _, hash := range workerHashes {
+			if selfErr = CheckSelfAccess(ctx, identity, "QueryWagesByWorkerFull", hash); selfErr == nil {
+				break
+			}
+		}
+		if selfErr != nil {
+			s.LogAccessDenied(ctx, "QueryWagesByWorkerFull", anyWorkerIDHash, "wage", selfErr.Error())
+			return nil, fmt.Errorf("access denied: %w", selfErr)
+		}
+		s.LogDataRead(ctx, "QueryWagesByWorkerFull", anyWorkerIDHash, "wage")
+	}
+
+	wages, err := queryWagesForWorkers(ctx, workerHashes)
+	if err != nil {
+		return nil, err
+	}
+
+	seen := make(map[string]bool, len(wages))
+	unique := make([]*WageRecord, 0, len(wages))
+	for _, wage := range wages {
+		if seen[wage.WageID] {
+			continue
+		}
+		seen[wage.WageID] = true
+		unique = append(unique, wage)
+	}
+
+	return unique, nil
+}
+
+// queryWagesForWorkers scans wage records once and returns those belonging to any
+// of the given worker hashes (LevelDB compatible), oldest first. No access checks are performed.
+func queryWagesForWorkers(ctx contractapi.TransactionContextInterface, workerIDHashes []string) ([]*WageRecord, error) {
+	wanted := make(map[string]bool, len(workerIDHashes))
+	for _, hash := range workerIDHashes {
+		wanted[hash] = true
+	}
+
+	// Use range query - iterate all keys that could be wages
+	iterator, err := ctx.GetStub().GetStateByRange("WAGE", "WAGE~")
+	if err != nil {
+		return nil, fmt.Errorf("get state range: %w", err)
+	}
+	defer iterator.Close()
+
+	var wages []*WageRecord
+	for iterator.HasNext() {
+		queryResponse, err := iterator.Next()
+		if err != nil {
+			return nil, fmt.Errorf("iterate: %w", err)
+		}
+
+		// Skip non-wage records
+		if !strings.HasPrefix(queryResponse.Key, "WAGE") {
+			continue
+		}
+
+		var wage WageRecord
+		if err := json.Unmarshal(queryResponse.Value, &wage); err != nil {
+			continue // Skip records that don't unmarshal as wage
+		}
+
+		if wanted[wage.WorkerIDHash] {
+			wages = append(wages, &wage)
+		}
+	}
+
+	sortWagesChronologically(wages)
+
+	return wages, nil
+}
+
+// QueryWagesByProgram retrieves all wage records tagged with a welfare/employment program,
+// so spending per scheme can be measured (LevelDB compatible).
+// Results are ordered oldest first (by timestamp, then ID).
+// SECURITY: Only government officials, auditors, and admins.
+func (s *SmartContract) QueryWagesByProgram(ctx contractapi.TransactionContextInterface, program string) ([]*WageRecord, error) {
+	if program == "" {
+		return nil, fmt.Errorf("program is required")
+	}
+
+	// IAM Check
+	if IAMEnabled {
+		_, err := CheckAccess(ctx, "QueryWagesByProgram")
+		if err != nil {
+			s.LogAccessDenied(ctx, "QueryWagesByProgram", program, "wage", err.Error())
+			return nil, fmt.Errorf("access denied: %w", err)
+		}
+		s.LogDataRead(ctx, "QueryWagesByProgram", program, "wage")
+	}
+
+	iterator, err := ctx.GetStub().GetStateByRange("WAGE", "WAGE~")
+	if err != nil {
+		return nil, fmt.Errorf("get state range: %w", err)
+	}
+	defer iterator.Close()
+
+	wages := []*WageRecord{}
+	for iterator.HasNext() {
+		queryResponse, err := iterator.Next()
+		if err != nil {
+			return nil, fmt.Errorf("iterate: %w", err)
+		}
+
+		var wage WageRecord
+		if err := json.Unmarshal(queryResponse.Value, &wage); err != nil || wage.DocType != "wage" {
+			continue
+		}
+
+		if wage.Program == program {
+			wages = append(wages, &wage)
+		}
+	}
+
+	sortWagesChronologically(wages)
+
+	return wages, nil
+}
+
+// QueryWagesByCurrency retrieves all wage records paid in a currency (LevelDB compatible).
+// Use QueryWagesByCurrencyPaginated for large result sets.
+// Results are ordered oldest first (by timestamp, then ID).
+// SECURITY: Only government officials, auditors, and admins.
+func (s *SmartContract) QueryWagesByCurrency(ctx contractapi.TransactionContextInterface, currency string) ([]*WageRecord, error) {
+	// IAM Check
+	if IAMEnabled {
+		_, err := CheckAccess(ctx, "QueryWagesByCurrency")
+		if err != nil {
+			s.LogAccessDenied(ctx, "QueryWagesByCurrency", currency, "wage", err.Error())
+			return nil, fmt.Errorf("access denied: %w", err)
+		}
+		s.LogDataRead(ctx, "QueryWagesByCurrency", currency, "wage")
+	}
+
+	if err := ValidateCurrency(ctx, currency); err != nil {
+		return nil, err
+	}
+
+	iterator, err := ctx.GetStub().GetStateByRange("WAGE", "WAGE~")
+	if err != nil {
+		return nil, fmt.Errorf("get state range: %w", err)
+	}
+	defer iterator.Close()
+
+	wages := []*WageRecord{}
+	for iterator.HasNext() {
+		queryResponse, err := iterator.Next()
+		if err != nil {
+			return nil, fmt.Errorf("iterate: %w", err)
+		}
+
+		var wage WageRecord
+		if err := json.Unmarshal(queryResponse.Value, &wage); err != nil || wage.DocType != "wage" {
+			continue
+		}
+
+		if wage.Currency == currency {
+			wages = append(wages, &wage)
+		}
+	}
+
+	sortWagesChronologically(wages)
+
+	return wages, nil
+}
+
+// QueryWagesByCurrencyPaginated retrieves wages paid in a currency one page at a time using
+// the wage~currency index, in wage ID order. Pass the returned bookmark to fetch the next page. As with
+// QueryWagesByEmployerPaginated, successful reads are not audit-logged and wages recorded
+// before the index existed are only returned by QueryWagesByCurrency.
+// SECURITY: Only government officials, auditors, and admins.
+func (s *SmartContract) QueryWagesByCurrencyPaginated(ctx contractapi.TransactionContextInterface, currency string, pageSize int32, bookmark string) (*WagePage, error) {
+	// IAM Check
+	if IAMEnabled {
+		_, err := CheckAccess(ctx, "QueryWagesByCurrencyPaginated")
+		if err != nil {
+			s.LogAccessDenied(ctx, "QueryWagesByCurrencyPaginated", currency, "wage", err.Error())
+			return nil, fmt.Errorf("access denied: %w", err)
+		}
+	}
+
+	if err := ValidateCurrency(ctx, currency); err != nil {
+		return nil, err
+	}
+
+	iterator, metadata, err := ctx.GetStub().GetStateByPartialCompositeKeyWithPagination("wage~currency", []string{currency}, clampPageSize(pageSize), bookmark)
+	if err != nil {
+		return nil, fmt.Errorf("get currency index: %w", err)
+	}
+	defer iterator.Close()
+
+	page := &WagePage{Records: []*WageRecord{}}
+	for iterator.HasNext() {
+		queryResponse, err := iterator.Next()
+		if err != nil {
+			return nil, fmt.Errorf("iterate: %w", err)
+		}
+
+		_, parts, err := ctx.GetStub().SplitCompositeKey(queryResponse.Key)
+		if err != nil || len(parts) != 2 {
+			continue
+		}
+
+		wage, err := readWageRecord(ctx, parts[1])
+		if err != nil {
+			continue
+		}
+		page.Records = append(page.Records, wage)
+	}
+
+	page.Count = int32(len(page.Records))
+	if metadata != nil && metadata.FetchedRecordsCount >= clampPageSize(pageSize) {
+		page.Bookmark = metadata.Bookmark
+	}
+
+	return page, nil
+}
+
+// UpdateWageStatus approves or rejects a pending wage and moves it in the wage~status index.
+// Only pending wages can be decided; wages recorded before approval existed have no status
+// and cannot be. Emits the "WageStatusUpdated" event with the wage ID as payload.
+// SECURITY: Only government officials and admins.
+func (s *SmartContract) UpdateWageStatus(ctx contractapi.TransactionContextInterface, wageID string, status string) error {
+	if wageID == "" {
+		return fmt.Errorf("wageID is required")
+	}
+
+	// IAM Check
+	if IAMEnabled {
+		_, err := CheckAccess(ctx, "UpdateWageStatus")
+		if err != nil {
+			s.LogAccessDenied(ctx, "UpdateWageStatus", wageID, "wage", err.Error())
+			return fmt.Errorf("access denied: %w", err)
+		}
+	}
+
+	if status != WageStatusApproved && status != WageStatusRejected {
+		return fmt.Errorf("invalid status %q: expected %s or %s", status, WageStatusApproved, WageStatusRejected)
+	}
+
+	wage, err := readWageRecord(ctx, wageID)
+	if err != nil {
+		return err
+	}
+	if wage.Status != WageStatusPending {
+		return fmt.Errorf("wage %s is not pending approval", wageID)
+	}
+
+	oldIndexKey, err := ctx.GetStub().CreateCompositeKey("wage~status", []string{wage.Status, wageID})
+	if err != nil {
+		return fmt.Errorf("create composite key: %w", err)
+	}
+	if err := ctx.GetStub().DelState(oldIndexKey); err != nil {
+		return fmt.Errorf("delete status index: %w", err)
+	}
+
+	wage.Status = status
+	payload, err := marshalState(wage)
+	if err != nil {
+		return fmt.Errorf("marshal wage record: %w", err)
+	}
+	if err := ctx.GetStub().PutState(wageID, payload); err != nil {
+		return fmt.Errorf("put state: %w", err)
+	}
+	if err := putWageStatusIndex(ctx, status, wageID); err != nil {
+		return err
+	}
+
+	if IAMEnabled {
+		s.LogDataWrite(ctx, "UpdateWageStatus", wageID, "wage", fmt.Sprintf("status: %s", status))
+	}
+
+	if err := ctx.GetStub().SetEvent("WageStatusUpdated", []byte(wageID)); err != nil {
+		fmt.Printf("warning: failed to emit event: %v\n", err)
+	}
+
+	return nil
+}
+
+// QueryWagesByStatus retrieves wages in an approval status one page at a time using the
+// wage~status index, in wage ID order, e.g. the pending queue for approvers. Pass the
+// returned bookmark to fetch the next page. Wages recorded before approval existed have no
+// status and are never returned.
+// SECURITY: Only government officials and admins.
+func (s *SmartContract) QueryWagesByStatus(ctx contractapi.TransactionContextInterface, status string, pageSize int32, bookmark string) (*WagePage, error) {
+	// IAM Check
+	if IAMEnabled {
+		_, err := CheckAccess(ctx, "QueryWagesByStatus")
+		if err != nil {
+			s.LogAccessDenied(ctx, "QueryWagesByStatus", status, "wage", err.Error())
+			return nil, fmt.Errorf("access denied: %w", err)
+		}
+		s.LogDataRead(ctx, "QueryWagesByStatus", status, "wage")
+	}
+
+	switch status {
+	case WageStatusPending, WageStatusApproved, WageStatusRejected:
+	default:
+		return nil, fmt.Errorf("invalid status %q: expected %s, %s or %s", status, WageStatusPending, WageStatusApproved, WageStatusRejected)
+	}
+
+	iterator, metadata, err := ctx.GetStub().GetStateByPartialCompositeKeyWithPagination("wage~status", []string{status}, clampPageSize(pageSize), bookmark)
+	if err != nil {
+		return nil, fmt.Errorf("get status index: %w", err)
+	}
+	defer iterator.Close()
+
+	page := &WagePage{Records: []*WageRecord{}}
+	for iterator.HasNext() {
+		queryResponse, err := iterator.Next()
+		if err != nil {
+			return nil, fmt.Errorf("iterate: %w", err)
+		}
+
+		_, parts, err := ctx.GetStub().SplitCompositeKey(queryResponse.Key)
+		if err != nil || len(parts) != 2 {
+			continue
+		}
+
+		wage, err := readWageRecord(ctx, parts[1])
+		if err != nil {
+			continue
+		}
+		page.Records = append(page.Records, wage)
+	}
+
+	page.Count = int32(len(page.Records))
+	if metadata != nil && metadata.FetchedRecordsCount >= clampPageSize(pageSize) {
+		page.Bookmark = metadata.Bookmark
+	}
+
+	return page, nil
+}
+
+// wageIndexDate returns the UTC calendar day ("2006-01-02") of an RFC3339 wage timestamp
+// for the wage~date index. Timestamps that do not parse are not indexed.
+func wageIndexDate(timestamp string) (string, bool) {
+	t, err := time.Parse(time.RFC3339, timestamp)
+	if err != nil {
+		return "", false
+	}
+	return t.UTC().Format("2006-01-02"), true
+}
+
+// QueryWagesByDay retrieves the wages whose timestamp falls on a UTC calendar day using the
+// wage~date index, avoiding a scan of every wage (LevelDB compatible). Wages recorded
+// before the index existed, or with a non-RFC3339 timestamp, are not returned.
+// Results are ordered by timestamp, then wage ID.
+// SECURITY: Only government officials, auditors, and admins.
+func (s *SmartContract) QueryWagesByDay(ctx contractapi.TransactionContextInterface, date string) ([]*WageRecord, error) {
+	// IAM Check
+	if IAMEnabled {
+		_, err := CheckAccess(ctx, "QueryWagesByDay")
+		if err != nil {
+			s.LogAccessDenied(ctx, "QueryWagesByDay", date, "wage", err.Error())
+			return nil, fmt.Errorf("access denied: %w", err)
+		}
+		s.LogDataRead(ctx, "QueryWagesByDay", date, "wage")
+	}
+
+	if _, err := time.Parse("2006-01-02", date); err != nil {
+		return nil, fmt.Errorf("invalid date %q: expected YYYY-MM-DD", date)
+	}
+
+	iterator, err := ctx.GetStub().GetStateByPartialCompositeKey("wage~date", []string{date})
+	if err != nil {
+		return nil, fmt.Errorf("get date index: %w", err)
+	}
+	defer iterator.Close()
+
+	wages := []*WageRecord{}
+	for iterator.HasNext() {
+		queryResponse, err := iterator.Next()
+		if err != nil {
+			return nil, fmt.Errorf("iterate: %w", err)
+		}
+
+		_, parts, err := ctx.GetStub().SplitCompositeKey(queryResponse.Key)
+		if err != nil || len(parts) != 2 {
+			continue
+		}
+
+		wage, err := readWageRecord(ctx, parts[1])
+		if err != nil {
+			continue
+		}
+		wages = append(wages, wage)
+	}
+
+	sortWagesChronologically(wages)
+
+	return wages, nil
+}
+
+// QueryWagesByCreator retrieves the wages recorded by one caller, identified by the client ID
+// stored in CreatedBy (the same value as AuditLog.CallerID), using the wage~creator index.
+// Wages recorded before CreatedBy existed are not returned.
+// Results are ordered by timestamp, then wage ID.
+// SECURITY: Only government officials, auditors, and admins, since client IDs identify
+// individual operators.
+func (s *SmartContract) QueryWagesByCreator(ctx contractapi.TransactionContextInterface, creatorID string) ([]*WageRecord, error) {
+	if creatorID == "" {
+		return nil, fmt.Errorf("creatorID is required")
+	}
+
+	// IAM Check
+	if IAMEnabled {
+		_, err := CheckAccess(ctx, "QueryWagesByCreator")
+		if err != nil {
+			s.LogAccessDenied(ctx, "QueryWagesByCreator", creatorID, "wage", err.Error())
+			return nil, fmt.Errorf("access denied: %w", err)
+		}
+		s.LogDataRead(ctx, "QueryWagesByCreator", creatorID, "wage")
+	}
+
+	iterator, err := ctx.GetStub().GetStateByPartialCompositeKey("wage~creator", []string{creatorID})
+	if err != nil {
+		return nil, fmt.Errorf("get creator index: %w", err)
+	}
+	defer iterator.Close()
+
+	wages := []*WageRecord{}
+	for iterator.HasNext() {
+		queryResponse, err := iterator.Next()
+		if err != nil {
+			return nil, fmt.Errorf("iterate: %w", err)
+		}
+
+		_, parts, err := ctx.GetStub().SplitCompositeKey(queryResponse.Key)
+		if err != nil || len(parts) != 2 {
+			continue
+		}
+
+		wage, err := readWageRecord(ctx, parts[1])
+		if err != nil {
+			continue
+		}
+		wages = append(wages, wage)
+	}
+
+	sortWagesChronologically(wages)
+
+	return wages, nil
+}
+
+// checkStateScope denies callers whose certificate carries a state attribute for a
+// different state. Callers without a state attribute (national officials) see any state.
+func (s *SmartContract) checkStateScope(ctx contractapi.TransactionContextInterface, function string, state string) error {
+	scope, err := GetCallerScope(ctx)
+	if err != nil {
+		return err
+	}
+	if !scope.AllowsState(state) {
+		return &AccessDeniedError{
+			Reason:     fmt.Sprintf("State officials can only access their own state (%s)", scope.State),
+			Function:   function,
+			RequiredBy: "State scope",
+		}
+	}
+	return nil
+}
+
+// QueryWagesByState retrieves wages recorded for a state one page at a time using the
+// wage~state index, in wage ID order. Pass the returned bookmark to fetch the next page. Successful reads are
+// not audit-logged, since Fabric forbids writes alongside pagination.
+// SECURITY: Government officials, auditors, and admins; officials with a state attribute
+// are limited to their own state.
+func (s *SmartContract) QueryWagesByState(ctx contractapi.TransactionContextInterface, state string, pageSize int32, bookmark string) (*WagePage, error) {
+	if state == "" {
+		return nil, fmt.Errorf("state is required")
+	}
+
+	// IAM Check with state scoping
+	if IAMEnabled {
+		_, err := CheckAccess(ctx, "QueryWagesByState")
+		if err == nil {
+			err = s.checkStateScope(ctx, "QueryWagesByState", state)
+		}
+		if err != nil {
+			s.LogAccessDenied(ctx, "QueryWagesByState", state, "wage", err.Error())
+			return nil, fmt.Errorf("access denied: %w", err)
+		}
+	}
+
+	iterator, metadata, err := ctx.GetStub().GetStateByPartialCompositeKeyWithPagination("wage~state", []string{state}, clampPageSize(pageSize), bookmark)
+	if err != nil {
+		return nil, fmt.Errorf("get state index: %w", err)
+	}
+	defer iterator.Close()
+
+	page := &WagePage{Records: []*WageRecord{}}
+	for iterator.HasNext() {
+		queryResponse, err := iterator.Next()
+		if err != nil {
+			return nil, fmt.Errorf("iterate: %w", err)
+		}
+
+		_, parts, err := ctx.GetStub().SplitCompositeKey(queryResponse.Key)
+		if err != nil || len(parts) != 2 {
+			continue
+		}
+
+		wage, err := readWageRecord(ctx, parts[1])
+		if err != nil {
+			continue
+		}
+		page.Records = append(page.Records, wage)
+	}
+
+	page.Count = int32(len(page.Records))
+	if metadata != nil && metadata.FetchedRecordsCount >= clampPageSize(pageSize) {
+		page.Bookmark = metadata.Bookmark
+	}
+
+	return page, nil
+}
+
+// GetStateWageSummary returns totals, distinct worker count, and average wage for a state.
+// Only wages recorded with a state are included.
+// SECURITY: Government officials, auditors, and admins; officials with a state attribute
+// are limited to their own state.
+func (s *SmartContract) GetStateWageSummary(ctx contractapi.TransactionContextInterface, state string) (*StateSummary, error) {
+	if state == "" {
+		return nil, fmt.Errorf("state is required")
+	}
+
+	// IAM Check with state scoping
+	if IAMEnabled {
+		_, err := CheckAccess(ctx, "GetStateWageSummary")
+		if err == nil {
+			err = s.checkStateScope(ctx, "GetStateWageSummary", state)
+		}
+		if err != nil {
+			s.LogAccessDenied(ctx, "GetStateWageSummary", state, "wage", err.Error())
+			return nil, fmt.Errorf("access denied: %w", err)
+		}
+		s.LogDataRead(ctx, "GetStateWageSummary", state, "wage")
+	}
+
+	iterator, err := ctx.GetStub().GetStateByPartialCompositeKey("wage~state", []string{state})
+	if err != nil {
+		return nil, fmt.Errorf("get state index: %w", err)
+	}
+	defer iterator.Close()
+
+	summary := &StateSummary{State: state}
+	workers := make(map[string]bool)
+	for iterator.HasNext() {
+		queryResponse, err := iterator.Next()
+		if err != nil {
+			return nil, fmt.Errorf("iterate: %w", err)
+		}
+
+		_, parts, err := ctx.GetStub().SplitCompositeKey(queryResponse.Key)
+		if err != nil || len(parts) != 2 {
+			continue
+		}
+
+		wage, err := readWageRecord(ctx, parts[1])
+		if err != nil {
+			continue
+		}
+		summary.WageCount++
+		summary.TotalAmount = addAmount(summary.TotalAmount, wage.WageID, wage.Amount)
+		workers[wage.WorkerIDHash] = true
+	}
+
+	summary.WorkerCount = len(workers)
+	summary.TotalAmount = roundMoney(summary.TotalAmount, "")
+	if summary.WageCount > 0 {
+		summary.AverageWage = roundMoney(summary.TotalAmount/float64(summary.WageCount), "")
+	}
+
+	return summary, nil
+}
+
+// GetWageAmountHistogram counts wages per amount bucket of width bucketSize, e.g. with a
+// bucketSize of 500 the key "500.00-1000.00" counts amounts from 500 up to (not including)
+// 1000. Empty state or jobType match every wage. Buckets are computed in integer paise so
+// every endorser produces the same keys; empty buckets are omitted.
+// SECURITY: Government officials and auditors; officials with a state attribute are
+// limited to their own state.
+func (s *SmartContract) GetWageAmountHistogram(ctx contractapi.TransactionContextInterface, state string, jobType string, bucketSize float64) (map[string]int, error) {
+	bucketPaise, ok := toPaise(bucketSize)
+	if !ok || bucketPaise <= 0 {
+		return nil, fmt.Errorf("bucketSize must be a positive amount, got %v", bucketSize)
+	}
+
+	// IAM Check with state scoping
+	if IAMEnabled {
+		_, err := CheckAccess(ctx, "GetWageAmountHistogram")
+		if err == nil {
+			err = s.checkStateScope(ctx, "GetWageAmountHistogram", state)
+		}
+		if err != nil {
+			s.LogAccessDenied(ctx, "GetWageAmountHistogram", state, "wage", err.Error())
+			return nil, fmt.Errorf("access denied: %w", err)
+		}
+		s.LogDataRead(ctx, "GetWageAmountHistogram", fmt.Sprintf("state:%s,jobType:%s", state, jobType), "wage")
+	}
+
+	iterator, err := ctx.GetStub().GetStateByRange("WAGE", "WAGE~")
+	if err != nil {
+		return nil, fmt.Errorf("get state range: %w", err)
+	}
+	defer iterator.Close()
+
+	histogram := make(map[string]int)
+	for iterator.HasNext() {
+		queryResponse, err := iterator.Next()
+		if err != nil {
+			return nil, fmt.Errorf("iterate: %w", err)
+		}
+
+		var wage WageRecord
+		if err := json.Unmarshal(queryResponse.Value, &wage); err != nil || wage.DocType != "wage" {
+			continue
+		}
+		if (state != "" && wage.State != state) || (jobType != "" && wage.JobType != jobType) {
+			continue
+		}
+
+		amountPaise, ok := toPaise(wage.Amount)
+		if !ok {
+			fmt.Printf("warning: skipping wage %s with invalid amount %v in histogram\n", wage.WageID, wage.Amount)
+			continue
+		}
+		histogram[amountBucketKey(amountPaise, bucketPaise)]++
+	}
+
+	return histogram, nil
+}
+
+// amountBucketKey returns the "lower-upper" key of the bucket containing amountPaise.
+func amountBucketKey(amountPaise int64, bucketPaise int64) string {
+	lower := amountPaise / bucketPaise * bucketPaise
+	return fmt.Sprintf("%d.%02d-%d.%02d", lower/100, lower%100, (lower+bucketPaise)/100, (lower+bucketPaise)%100)
+}
+
+// QueryWagesByEmployer retrieves all wage records paid by a specific employer (LevelDB compatible).
+// Results are ordered newest first (by timestamp, then ID). The DATA_READ audit entry records
+// how many wages were returned.
+// NOTE: This scans all WAGE keys.
+// SECURITY: Employers can only query their own wages; privileged roles can query any employer.
+func (s *SmartContract) QueryWagesByEmployer(ctx contractapi.TransactionContextInterface, employerIDHash string) ([]*WageRecord, error) {
+	if employerIDHash == "" {
+		return nil, fmt.Errorf("employerIDHash is required")
+	}
+
+	// IAM Check with self-access validation
+	if IAMEnabled {
+		identity, err := CheckAccess(ctx, "QueryWagesByEmployer")
+		if err != nil {
+			s.LogAccessDenied(ctx, "QueryWagesByEmployer", employerIDHash, "wage", err.Error())
+			return nil, fmt.Errorf("access denied: %w", err)
+		}
+
+		// Check self-access for employers
+		if err := CheckSelfAccess(ctx, identity, "QueryWagesByEmployer", employerIDHash); err != nil {
+			s.LogAccessDenied(ctx, "QueryWagesByEmployer", employerIDHash, "wage", err.Error())
+			return nil, fmt.Errorf("access denied: %w", err)
+		}
+	}
+
+	wages, err := queryWagesForEmployer(ctx, employerIDHash)
+	if err != nil {
+		return nil, err
+	}
+
+	if IAMEnabled {
+		s.LogAccess(ctx, EventDataRead, "QueryWagesByEmployer", employerIDHash, "wage", "success", fmt.Sprintf("Data read: %d wages", len(wages)))
+	}
+
+	return wages, nil
+}
+
+// queryWagesForEmployer scans wage records once and returns those paid by the employer,
+// newest first. No access checks are performed.
+func queryWagesForEmployer(ctx contractapi.TransactionContextInterface, employerIDHash string) ([]*WageRecord, error) {
+	iterator, err := ctx.GetStub().GetStateByRange("WAGE", "WAGE~")
+	if err != nil {
+		return nil, fmt.Errorf("get state range: %w", err)
+	}
+	defer iterator.Close()
+
+	var wages []*WageRecord
+	for iterator.HasNext() {
+		queryResponse, err := iterator.Next()
+		if err != nil {
+			return nil, fmt.Errorf("iterate: %w", err)
+		}
+
+		var wage WageRecord
+		if err := json.Unmarshal(queryResponse.Value, &wage); err != nil || wage.DocType != "wage" {
+			continue
+		}
+
+		if wage.EmployerIDHash == employerIDHash {
+			wages = append(wages, &wage)
+		}
+	}
+
+	sortWagesNewestFirst(wages)
+
+	return wages, nil
+}
+
+// Page size bounds for paginated queries
+const (
+	DefaultPageSize int32 = 50
+	MaxPageSize     int32 = 200
+)
+
+// clampPageSize bounds a requested page size to (0, MaxPageSize], defaulting when unset
+func clampPageSize(pageSize int32) int32 {
+	if pageSize <= 0 {
+		return DefaultPageSize
+	}
+	if pageSize > MaxPageSize {
+		return MaxPageSize
+	}
+	return pageSize
+}
+
+// QueryWagesByEmployerPaginated retrieves an employer's wages one page at a time using the
+// wage~employer index, for employers whose full history exceeds response size limits.
+// Pass the returned bookmark to fetch the next page. Fabric forbids state writes in a
+// transaction that uses pagination, so successful reads are not written to the audit log.
+// Wages recorded before the index existed are only returned by QueryWagesByEmployer.
+// SECURITY: Employers can only query their own wages; privileged roles can query any employer.
+func (s *SmartContract) QueryWagesByEmployerPaginated(ctx contractapi.TransactionContextInterface, employerIDHash string, pageSize int32, bookmark string) (*WagePage, error) {
+	if employerIDHash == "" {
+		return nil, fmt.Errorf("employerIDHash is required")
+	}
+
+	// IAM Check with self-access validation
+	if IAMEnabled {
+		identity, err := CheckAccess(ctx, "QueryWagesByEmployerPaginated")
+		if err != nil {
+			s.LogAccessDenied(ctx, "QueryWagesByEmployerPaginated", employerIDHash, "wage", err.Error())
+			return nil, fmt.Errorf("access denied: %w", err)
+		}
+
+		if err := CheckSelfAccess(ctx, identity, "QueryWagesByEmployerPaginated", employerIDHash); err != nil {
+			s.LogAccessDenied(ctx, "QueryWagesByEmployerPaginated", employerIDHash, "wage", err.Error())
+			return nil, fmt.Errorf("access denied: %w", err)
+		}
+	}
+
+	iterator, metadata, err := ctx.GetStub().GetStateByPartialCompositeKeyWithPagination("wage~employer", []string{employerIDHash}, clampPageSize(pageSize), bookmark)
+	if err != nil {
+		return nil, fmt.Errorf("get employer index: %w", err)
+	}
+	defer iterator.Close()
+
+	page := &WagePage{Records: []*WageRecord{}}
+	for iterator.HasNext() {
+		queryResponse, err := iterator.Next()
+		if err != nil {
+			return nil, fmt.Errorf("iterate: %w", err)
+		}
+
+		_, parts, err := ctx.GetStub().SplitCompositeKey(queryResponse.Key)
+		if err != nil || len(parts) != 2 {
+			continue
+		}
+
+		wage, err := readWageRecord(ctx, parts[1])
+		if err != nil {
+			continue
+		}
+		page.Records = append(page.Records, wage)
+	}
+
+	page.Count = int32(len(page.Records))
+	if metadata != nil && metadata.FetchedRecordsCount >= clampPageSize(pageSize) {
+		page.Bookmark = metadata.Bookmark
+	}
+
+	return page, nil
+}
+
+// CalculateTotalIncome calculates total income for a worker within a date range: their
+// recorded wages plus UPI payments not linked to a wage (linked payments settle a wage that
+// is already counted). Dates are YYYY-MM-DD or RFC3339, given together or both empty for
+// all time. Amounts are only summed within one currency; if the records span several, an
+// error lists them.
+// Fabric forbids pagination in a transaction that writes state, and this one writes its
+// audit entry, so the wage and UPI key ranges are each scanned in a single query.
+// NOTE: This scans all WAGE and UPI keys.
+// SECURITY: Workers can only calculate their own income; privileged roles can calculate any.
+func (s *SmartContract) CalculateTotalIncome(ctx contractapi.TransactionContextInterface, workerIDHash string, startDate string, endDate string) (float64, error) {
+	if workerIDHash == "" {
+		return 0, fmt.Errorf("workerIDHash is required")
+	}
+
+	// IAM Check with self-access validation
+	if IAMEnabled {
+		identity, err := CheckAccess(ctx, "CalculateTotalIncome")
+		if err != nil {
+			s.LogAccessDenied(ctx, "CalculateTotalIncome", workerIDHash, "income", err.Error())
+			return 0, fmt.Errorf("access denied: %w", err)
+		}
+
+		if err := CheckSelfAccess(ctx, identity, "CalculateTotalIncome", workerIDHash); err != nil {
+			s.LogAccessDenied(ctx, "CalculateTotalIncome", workerIDHash, "income", err.Error())
+			return 0, fmt.Errorf("access denied: %w", err)
+		}
+		s.LogDataRead(ctx, "CalculateTotalIncome", workerIDHash, "income")
+	}
+
+	inRange, err := incomeDateRange(startDate, endDate)
+	if err != nil {
+		return 0, err
+	}
+
+	// Union wages across all of the worker's known hashes
+	workerHashes, err := resolveWorkerHashes(ctx, workerIDHash)
+	if err != nil {
+		return 0, err
+	}
+	wages, err := queryWagesForWorkers(ctx, workerHashes)
+	if err != nil {
+		return 0, fmt.Errorf("query wages: %w", err)
+	}
+	transactions, err := queryUPIForWorkers(ctx, workerHashes)
+	if err != nil {
+		return 0, fmt.Errorf("query UPI transactions: %w", err)
+	}
+
+	return sumIncome(wages, transactions, inRange)
+}
+
+// incomeDateRange returns a filter accepting RFC3339 timestamps within [startDate, endDate].
+// Each date is YYYY-MM-DD or RFC3339; both empty accepts every record, and a date that does
+// not parse, or only one of the two, is an error rather than an empty range.
+func incomeDateRange(startDate string, endDate string) (func(timestamp string) bool, error) {
+	if startDate == "" && endDate == "" {
+		return func(string) bool { return true }, nil
+	}
+	if startDate == "" || endDate == "" {
+		return nil, fmt.Errorf("startDate and endDate must be given together")
+	}
+
+	parse := func(name string, value string) (time.Time, error) {
+		if t, err := time.Parse("2006-01-02", value); err == nil {
+			return t, nil
+		}
+		t, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("invalid %s %q: use YYYY-MM-DD or RFC3339", name, value)
+		}
+		return t, nil
+	}
+	start, err := parse("startDate", startDate)
+	if err != nil {
+		return nil, err
+	}
+	end, err := parse("endDate", endDate)
+	if err != nil {
+		return nil, err
+	}
+
+	return func(timestamp string) bool {
+		t, err := time.Parse(time.RFC3339, timestamp)
+		if err != nil {
+			return false // Skip records with invalid timestamps
+		}
+		return !t.Before(start) && !t.After(end)
+	}, nil
+}
+
+// wageIncome totals a worker's recorded wages (across aliases) in a date range, without UPI
+// payments or access checks. Like CalculateTotalIncome it fails when the wages span
+// several currencies.
+func wageIncome(ctx contractapi.TransactionContextInterface, workerIDHash string, startDate string, endDate string) (float64, error) {
+	inRange, err := incomeDateRange(startDate, endDate)
+	if err != nil {
+		return 0, err
+	}
+	workerHashes, err := resolveWorkerHashes(ctx, workerIDHash)
+	if err != nil {
+		return 0, err
+	}
+	wages, err := queryWagesForWorkers(ctx, workerHashes)
+	if err != nil {
+		return 0, fmt.Errorf("query wages: %w", err)
+	}
+	return sumIncome(wages, nil, inRange)
+}
+
+// sumIncome totals the wages and unlinked UPI payments accepted by inRange, failing with
+// the list of currencies when they do not share one.
+func sumIncome(wages []*WageRecord, transactions []*UPITransaction, inRange func(timestamp string) bool) (float64, error) {
+	var totalIncome float64
+	currencies := make(map[string]bool)
+	for _, wage := range wages {
+		if !inRange(wage.Timestamp) {
+			continue
+		}
+		totalIncome = addAmount(totalIncome, wage.WageID, wage.Amount)
+		currencies[wage.Currency] = true
+	}
+	for _, tx := range transactions {
+		if tx.OnChainReference != "" || !inRange(tx.Timestamp) {
+			continue
+		}
+		totalIncome = addAmount(totalIncome, tx.TxID, tx.Amount)
+		currencies[tx.Currency] = true
+	}
+
+	currency, err := singleCurrency(currencies)
+	if err != nil {
+		return 0, err
+	}
+	return roundMoney(totalIncome, currency), nil
+}
+
+// singleCurrency returns the one currency in the set (empty for an empty set), failing with
+// the list of currencies when there are several, since income is never summed across them.
+func singleCurrency(currencies map[string]bool) (string, error) {
+	if len(currencies) > 1 {
+		codes := make([]string, 0, len(currencies))
+		for code := range currencies {
+			codes = append(codes, code)
+		}
+		sort.Strings(codes)
+		return "", fmt.Errorf("income spans multiple currencies (%s); totals are only computed in a single currency", strings.Join(codes, ", "))
+	}
+
+	var currency string
+	for code := range currencies {
+		currency = code
+	}
+	return currency, nil
+}
+
+// BatchRecordWages records multiple wage transactions in a single call. The batch is
+// all-or-nothing: each wage goes through the same validation as RecordWage, and the first
+// failure (including a wageID repeated within the batch) fails the whole transaction with
+// an error naming that wageID, so nothing is written. On success a single
+// "BatchWagesRecorded" event lists every recorded wageID; it replaces the per-wage
+// "WageRecorded" events because Fabric keeps only the last event of a transaction.
+// SECURITY: Requires 'canRecordWage' and 'canBatchProcess' permissions with clearance level 6+.
+func (s *SmartContract) BatchRecordWages(ctx contractapi.TransactionContextInterface, wagesJSON string) (string, error) {
+	// IAM Check
+	if IAMEnabled {
+		identity, err := CheckAccess(ctx, "BatchRecordWages")
+		if err != nil {
+			s.LogAccessDenied(ctx, "BatchRecordWages", "batch", "wage", err.Error())
+			return "", fmt.Errorf("access denied: %w", err)
+		}
+		s.LogAccessGranted(ctx, "BatchRecordWages", "batch", "wage")
+		fmt.Printf("[IAM] BatchRecordWages by %s\n", identity.ID)
+	}
+
+	var wages []struct {
+		WageID         string   `json:"wageId"`
+		WorkerIDHash   string   `json:"workerIdHash"`
+		EmployerIDHash string   `json:"employerIdHash"`
+		Amount         float64  `json:"amount"`
+		Currency       string   `json:"currency"`
+		JobType        string   `json:"jobType"`
+		Timestamp      string   `json:"timestamp"`
+		PolicyVersion  string   `json:"policyVersion"`
+		Program        string   `json:"program"`
+		Tags           []string `json:"tags"`
+		State          string   `json:"state"`
+		DocumentHash   string   `json:"documentHash"`
+		DocumentType   string   `json:"documentType"`
+	}
+
+	if err := json.Unmarshal([]byte(wagesJSON), &wages); err != nil {
+		return "", fmt.Errorf("unmarshal wages: %w", err)
+	}
+	if len(wages) == 0 {
+		return "", fmt.Errorf("wages must not be empty")
+	}
+
+	// Reads in a transaction do not see its own writes, so repeats within the batch
+	// would pass the existence check in recordWage and overwrite each other
+	seen := make(map[string]bool, len(wages))
+	for i, w := range wages {
+		if seen[w.WageID] {
+			return "", fmt.Errorf("wage %d (%s): wageID appears more than once in the batch", i, w.WageID)
+		}
+		seen[w.WageID] = true
+	}
+
+	// One wage scan for the whole batch; the totals then grow with each recorded wage
+	var capTotals *monthlyIncomeTotals
+	limit, err := getConfigFloat(ctx, ConfigMonthlyIncomeCap)
+	if err != nil {
+		return "", err
+	}
+	if limit > 0 {
+		workers := make([]string, 0, len(wages))
+		for _, w := range wages {
+			workers = append(workers, w.WorkerIDHash)
+		}
+		if capTotals, err = loadMonthlyIncomeTotals(ctx, workers); err != nil {
+			return "", err
+		}
+	}
+
+	createdIDs := make([]string, 0, len(wages))
+	for i, w := range wages {
+		err := s.recordWage(ctx, w.WageID, w.WorkerIDHash, w.EmployerIDHash, w.Amount, w.Currency, w.JobType, w.Timestamp, w.PolicyVersion, WageOptions{Program: w.Program, Tags: w.Tags, State: w.State, DocumentHash: w.DocumentHash, DocumentType: w.DocumentType}, capTotals)
+		if err != nil {
+			return "", fmt.Errorf("wage %d (%s): %w", i, w.WageID, err)
+		}
+		createdIDs = append(createdIDs, w.WageID)
+	}
+
+	payload, err := marshalState(BatchWagesRecordedEvent{WageIDs: createdIDs, Count: len(createdIDs)})
+	if err != nil {
+		return "", fmt.Errorf("marshal event: %w", err)
+	}
+	if err := ctx.GetStub().SetEvent("BatchWagesRecorded", payload); err != nil {
+		fmt.Printf("warning: failed to emit BatchWagesRecorded event: %v\n", err)
+	}
+
+	return fmt.Sprintf("recorded %d wages", len(createdIDs)), nil
+}
+
+// GetWorkerIncomeHistory retrieves monthly income breakdown for a worker: wages plus UPI
+// payments not linked to a wage, across all of the worker's hashes, with one entry per
+// month and currency. Records with invalid timestamps are reported in an "unknown" month
+// after the dated months.
+// NOTE: This scans all WAGE and UPI keys.
+// SECURITY: Workers can only view their own history; privileged roles can view any.
+func (s *SmartContract) GetWorkerIncomeHistory(ctx contractapi.TransactionContextInterface, workerIDHash string, months int) ([]*MonthlyIncome, error) {
+	if workerIDHash == "" {
+		return nil, fmt.Errorf("workerIDHash is required")
+	}
+
+	// IAM Check with self-access validation
+	if IAMEnabled {
+		identity, err := CheckAccess(ctx, "GetWorkerIncomeHistory")
+		if err != nil {
+			s.LogAccessDenied(ctx, "GetWorkerIncomeHistory", workerIDHash, "income", err.Error())
+			return nil, fmt.Errorf("access denied: %w", err)
+		}
+
+		if err := CheckSelfAccess(ctx, identity, "GetWorkerIncomeHistory", workerIDHash); err != nil {
+			s.LogAccessDenied(ctx, "GetWorkerIncomeHistory", workerIDHash, "income", err.Error())
+			return nil, fmt.Errorf("access denied: %w", err)
+		}
+		s.LogDataRead(ctx, "GetWorkerIncomeHistory", workerIDHash, "income")
+	}
+
+	if months <= 0 {
+		months = 12 // Default to 12 months
+	}
+
+	// Union wages across all of the worker's known hashes
+	workerHashes, err := resolveWorkerHashes(ctx, workerIDHash)
+	if err != nil {
+		return nil, err
+	}
+	wages, err := queryWagesForWorkers(ctx, workerHashes)
+	if err != nil {
+		return nil, fmt.Errorf("query wages: %w", err)
+	}
+	transactions, err := queryUPIForWorkers(ctx, workerHashes)
+	if err != nil {
+		return nil, fmt.Errorf("query UPI transactions: %w", err)
+	}
+
+	return monthlyIncome(wages, transactions, months), nil
+}
+
+// UnknownMonth is the MonthlyIncome bucket of records whose timestamp is not RFC3339
+const UnknownMonth = "unknown"
+
+// monthlyIncome groups wages and unlinked UPI payments (see CalculateTotalIncome) by the
+// calendar month of their timestamp and their currency, newest month first and then by
+// currency, keeping the entries of at most the latest `months` months with income. Records
+// with unparseable timestamps are totalled in trailing UnknownMonth entries rather than
+// dropped; they do not count towards `months`.
+func monthlyIncome(wages []*WageRecord, transactions []*UPITransaction, months int) []*MonthlyIncome {
+	monthlyData := make(map[[2]string]*MonthlyIncome)
+	bucket := func(timestamp string, currency string) *MonthlyIncome {
+		monthKey := UnknownMonth
+		if recordTime, err := time.Parse(time.RFC3339, timestamp); err == nil {
+			monthKey = recordTime.Format("2006-01")
+		}
+		key := [2]string{monthKey, currency}
+		if _, exists := monthlyData[key]; !exists {
+			monthlyData[key] = &MonthlyIncome{Month: monthKey, Currency: currency}
+		}
+		return monthlyData[key]
+	}
+
+	for _, wage := range wages {
+		income := bucket(wage.Timestamp, wage.Currency)
+		income.TotalIncome = addAmount(income.TotalIncome, wage.WageID, wage.Amount)
+		income.WageCount++
+	}
+	for _, tx := range transactions {
+		if tx.OnChainReference != "" {
+			continue // Settles a wage that is already counted
+		}
+		income := bucket(tx.Timestamp, tx.Currency)
+		income.TotalIncome = addAmount(income.TotalIncome, tx.TxID, tx.Amount)
+		income.PaymentCount++
+	}
+
+	// Convert to slice and sort by month descending, then currency
+	var result, unknown []*MonthlyIncome
+	for _, income := range monthlyData {
+		if income.Month == UnknownMonth {
+			unknown = append(unknown, income)
+		} else {
+			result = append(result, income)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Month != result[j].Month {
+			return result[i].Month > result[j].Month
+		}
+		return result[i].Currency < result[j].Currency
+	})
+	sort.Slice(unknown, func(i, j int) bool { return unknown[i].Currency < unknown[j].Currency })
+
+	// Limit to requested months
+	seen := 0
+	for i, income := range result {
+		if i == 0 || income.Month != result[i-1].Month {
+			seen++
+		}
+		if seen > months {
+			result = result[:i]
+			break
+		}
+	}
+
+	return append(result, unknown...)
+}
+
+// GetWorkerEmployers lists the distinct employers that have paid a worker, with the
+// number of wages and total paid by each, largest total first.
+// SECURITY: Workers can only view their own employers; privileged roles can view any.
+func (s *SmartContract) GetWorkerEmployers(ctx contractapi.TransactionContextInterface, workerIDHash string) ([]EmployerSummary, error) {
+	if workerIDHash == "" {
+		return nil, fmt.Errorf("workerIDHash is required")
+	}
+
+	// IAM Check with self-access validation
+	if IAMEnabled {
+		identity, err := CheckAccess(ctx, "GetWorkerEmployers")
+		if err != nil {
+			s.LogAccessDenied(ctx, "GetWorkerEmployers", workerIDHash, "wage", err.Error())
+			return nil, fmt.Errorf("access denied: %w", err)
+		}
+
+		if err := CheckSelfAccess(ctx, identity, "GetWorkerEmployers", workerIDHash); err != nil {
+			s.LogAccessDenied(ctx, "GetWorkerEmployers", workerIDHash, "wage", err.Error())
+			return nil, fmt.Errorf("access denied: %w", err)
+		}
+		s.LogDataRead(ctx, "GetWorkerEmployers", workerIDHash, "wage")
+	}
+
+	workerHashes, err := resolveWorkerHashes(ctx, workerIDHash)
+	if err != nil {
+		return nil, err
+	}
+	wages, err := queryWagesForWorkers(ctx, workerHashes)
+	if err != nil {
+		return nil, fmt.Errorf("query wages: %w", err)
+	}
+
+	byEmployer := make(map[string]*EmployerSummary)
+	for _, wage := range wages {
+		summary, exists := byEmployer[wage.EmployerIDHash]
+		if !exists {
+			summary = &EmployerSummary{EmployerIDHash: wage.EmployerIDHash}
+			byEmployer[wage.EmployerIDHash] = summary
+		}
+		summary.WageCount++
+		summary.TotalPaid = addAmount(summary.TotalPaid, wage.WageID, wage.Amount)
+	}
+
+	employers := make([]EmployerSummary, 0, len(byEmployer))
+	for _, summary := range byEmployer {
+		employers = append(employers, *summary)
+	}
+
+	sort.Slice(employers, func(i, j int) bool {
+		if employers[i].TotalPaid != employers[j].TotalPaid {
+			return employers[i].TotalPaid > employers[j].TotalPaid
+		}
+		return employers[i].EmployerIDHash < employers[j].EmployerIDHash
+	})
+
+	return employers, nil
+}
+
+// GetWorkerPaymentSpan returns the earliest and latest wage or UPI payment timestamps for a
+// worker (across all of the worker's alias hashes) and the number of payments between them.
+// A worker with a single payment has a span of zero days; one with none has no timestamps.
+// Records with unparseable timestamps are not counted.
+// SECURITY: Workers can only view their own span; privileged roles can view any.
+func (s *SmartContract) GetWorkerPaymentSpan(ctx contractapi.TransactionContextInterface, workerIDHash string) (*PaymentSpan, error) {
+	if workerIDHash == "" {
+		return nil, fmt.Errorf("workerIDHash is required")
+	}
+
+	// IAM Check with self-access validation
+	if IAMEnabled {
+		identity, err := CheckAccess(ctx, "GetWorkerPaymentSpan")
+		if err != nil {
+			s.LogAccessDenied(ctx, "GetWorkerPaymentSpan", workerIDHash, "income", err.Error())
+			return nil, fmt.Errorf("access denied: %w", err)
+		}
+
+		if err := CheckSelfAccess(ctx, identity, "GetWorkerPaymentSpan", workerIDHash); err != nil {
+			s.LogAccessDenied(ctx, "GetWorkerPaymentSpan", workerIDHash, "income", err.Error())
+			return nil, fmt.Errorf("access denied: %w", err)
+		}
+		s.LogDataRead(ctx, "GetWorkerPaymentSpan", workerIDHash, "income")
+	}
+
+	workerHashes, err := resolveWorkerHashes(ctx, workerIDHash)
+	if err != nil {
+		return nil, err
+	}
+	wages, err := queryWagesForWorkers(ctx, workerHashes)
+	if err != nil {
+		return nil, fmt.Errorf("query wages: %w", err)
+	}
+
+	span := &PaymentSpan{WorkerIDHash: workerIDHash}
+	var first, last time.Time
+	observe := func(timestamp string) bool {
+		paidAt, err := time.Parse(time.RFC3339, timestamp)
+		if err != nil {
+			return false
+		}
+		if first.IsZero() || paidAt.Before(first) {
+			first = paidAt
+		}
+		if last.IsZero() || paidAt.After(last) {
+			last = paidAt
+		}
+		return true
+	}
+
+	for _, wage := range wages {
+		if observe(wage.Timestamp) {
+			span.WageCount++
+		}
+	}
+
+	transactions, err := queryUPIForWorkers(ctx, workerHashes)
+	if err != nil {
+		return nil, fmt.Errorf("query UPI transactions: %w", err)
+	}
+	for _, tx := range transactions {
+		if observe(tx.Timestamp) {
+			span.UPICount++
+		}
+	}
+
+	span.PaymentCount = span.WageCount + span.UPICount
+	if span.PaymentCount > 0 {
+		span.FirstPayment = first.UTC().Format(time.RFC3339)
+		span.LastPayment = last.UTC().Format(time.RFC3339)
+		span.SpanDays = int(last.Sub(first).Hours() / 24)
+	}
+
+	return span, nil
+}
+
+// newPaymentCalendar returns an empty DayPayments entry for every day of yearMonth (YYYY-MM).
+func newPaymentCalendar(yearMonth string) (map[string]DayPayments, error) {
+	month, err := time.Parse("2006-01", yearMonth)
+	if err != nil {
+		return nil, fmt.Errorf("invalid yearMonth %q: expected YYYY-MM", yearMonth)
+	}
+
+	calendar := make(map[string]DayPayments)
+	for day := month; day.Month() == month.Month(); day = day.AddDate(0, 0, 1) {
+		date := day.Format("2006-01-02")
+		calendar[date] = DayPayments{
+			Date:            date,
+			Wages:           []*WageRecord{},
+			UPITransactions: []*UPITransaction{},
+			WageTotals:      map[string]float64{},
+			UPITotals:       map[string]float64{},
+		}
+	}
+	return calendar, nil
+}
+
+// GetWorkerPaymentCalendar returns the wages and UPI payments a worker received on each day
+// of yearMonth (YYYY-MM), keyed by UTC date. Every day of the month is present, with empty
+// lists on days without payments, so a calendar view can show gaps directly. Payments are
+// ordered oldest first within a day; records with unparseable timestamps are skipped.
+// NOTE: This scans all WAGE and UPI_ keys.
+// SECURITY: Workers can only view their own calendar; privileged roles can view any.
+func (s *SmartContract) GetWorkerPaymentCalendar(ctx contractapi.TransactionContextInterface, workerIDHash string, yearMonth string) (map[string]DayPayments, error) {
+	if workerIDHash == "" {
+		return nil, fmt.Errorf("workerIDHash is required")
+	}
+	calendar, err := newPaymentCalendar(yearMonth)
+	if err != nil {
+		return nil, err
+	}
+
+	// IAM Check with self-access validation
+	if IAMEnabled {
+		identity, err := CheckAccess(ctx, "GetWorkerPaymentCalendar")
+		if err != nil {
+			s.LogAccessDenied(ctx, "GetWorkerPaymentCalendar", workerIDHash, "income", err.Error())
+			return nil, fmt.Errorf("access denied: %w", err)
+		}
+
+		if err := CheckSelfAccess(ctx, identity, "GetWorkerPaymentCalendar", workerIDHash); err != nil {
+			s.LogAccessDenied(ctx, "GetWorkerPaymentCalendar", workerIDHash, "income", err.Error())
+			return nil, fmt.Errorf("access denied: %w", err)
+		}
+		s.LogDataRead(ctx, "GetWorkerPaymentCalendar", workerIDHash, "income")
+	}
+
+	workerHashes, err := resolveWorkerHashes(ctx, workerIDHash)
+	if err != nil {
+		return nil, err
+	}
+	wages, err := queryWagesForWorkers(ctx, workerHashes)
+	if err != nil {
+		return nil, fmt.Errorf("query wages: %w", err)
+	}
+
+	for _, wage := range wages {
+		paidAt, err := time.Parse(time.RFC3339, wage.Timestamp)
+		if err != nil {
+			continue
+		}
+		day, ok := calendar[paidAt.UTC().Format("2006-01-02")]
+		if !ok {
+			continue
+		}
+		day.Wages = append(day.Wages, wage)
+		day.WageTotals[wage.Currency] = addAmount(day.WageTotals[wage.Currency], wage.WageID, wage.Amount)
+		calendar[day.Date] = day
+	}
+
+	transactions, err := queryUPIForWorkers(ctx, workerHashes)
+	if err != nil {
+		return nil, fmt.Errorf("query upi transactions: %w", err)
+	}
+
+	for _, tx := range transactions {
+		paidAt, err := time.Parse(time.RFC3339, tx.Timestamp)
+		if err != nil {
+			continue
+		}
+		day, ok := calendar[paidAt.UTC().Format("2006-01-02")]
+		if !ok {
+			continue
+		}
+		day.UPITransactions = append(day.UPITransactions, tx)
+		day.UPITotals[tx.Currency] = addAmount(day.UPITotals[tx.Currency], tx.TxID, tx.Amount)
+		calendar[day.Date] = day
+	}
+
+	for _, day := range calendar {
+		sortWagesChronologically(day.Wages)
+		sortUPIChronologically(day.UPITransactions)
+		roundMoneyTotals(day.WageTotals)
+		roundMoneyTotals(day.UPITotals)
+	}
+
+	return calendar, nil
+}
+
+// queryUPIForWorkers returns the UPI transactions received by any of the given worker
+// hashes, scanning the UPI_ range once.
+func queryUPIForWorkers(ctx contractapi.TransactionContextInterface, workerIDHashes []string) ([]*UPITransaction, error) {
+	wanted := make(map[string]bool, len(workerIDHashes))
+	for _, hash := range workerIDHashes {
+		wanted[hash] = true
+	}
+
+	iterator, err := ctx.GetStub().GetStateByRange("UPI_", "UPI_~")
+	if err != nil {
+		return nil, fmt.Errorf("get state range: %w", err)
+	}
+	defer iterator.Close()
+
+	var transactions []*UPITransaction
+	for iterator.HasNext() {
+		queryResponse, err := iterator.Next()
+		if err != nil {
+			return nil, fmt.Errorf("iterate: %w", err)
+		}
+
+		var tx UPITransaction
+		if err := json.Unmarshal(queryResponse.Value, &tx); err != nil || !wanted[tx.WorkerIDHash] {
+			continue
+		}
+		transactions = append(transactions, &tx)
+	}
+
+	return transactions, nil
+}
+
+// DiscrepancyPeriodDays is the look-back window of CheckMyPaymentDiscrepancy
+const DiscrepancyPeriodDays = 365
+
+// CurrencyDiscrepancy compares declared wages with received UPI payments in one currency.
+type CurrencyDiscrepancy struct {
+	Declared  float64 `json:"declared"`  // Total of recorded wages
+	Received  float64 `json:"received"`  // Total of UPI payments received
+	Shortfall float64 `json:"shortfall"` // Declared minus received, or 0 if fully paid
+}
+
+// DiscrepancyResult is a worker's self-audit of declared wages against received payments.
+type DiscrepancyResult struct {
+	WorkerIDHash string                          `json:"workerIdHash"`
+	PeriodStart  string                          `json:"periodStart"`
+	PeriodEnd    string                          `json:"periodEnd"`
+	HasData      bool                            `json:"hasData"`   // False when the worker has no wages or payments in the period
+	Shortfall    bool                            `json:"shortfall"` // True when any currency is short by more than the UPI tolerance
+	Currencies   map[string]*CurrencyDiscrepancy `json:"currencies"`
+	Message      string                          `json:"message"`
+}
+
+// CheckMyPaymentDiscrepancy lets a worker check whether the UPI payments they received
+// cover the wages recorded for them over the last DiscrepancyPeriodDays days, including
+// records under their alias hashes. Totals are kept per currency, without conversion, and
+// a currency counts as short only when the gap exceeds the upiAmountTolerance config.
+// SECURITY: Workers only, and only their own records (identified by the idHash attribute).
+func (s *SmartContract) CheckMyPaymentDiscrepancy(ctx contractapi.TransactionContextInterface) (*DiscrepancyResult, error) {
+	workerIDHash, err := callerWorkerHash(ctx, "CheckMyPaymentDiscrepancy")
+	if err != nil {
+		s.LogAccessDenied(ctx, "CheckMyPaymentDiscrepancy", "", "income", err.Error())
+		return nil, fmt.Errorf("access denied: %w", err)
+	}
+	s.LogDataRead(ctx, "CheckMyPaymentDiscrepancy", workerIDHash, "income")
+
+	workerHashes, err := resolveWorkerHashes(ctx, workerIDHash)
+	if err != nil {
+		return nil, err
+	}
+	wages, err := queryWagesForWorkers(ctx, workerHashes)
+	if err != nil {
+		return nil, fmt.Errorf("query wages: %w", err)
+	}
+	transactions, err := queryUPIForWorkers(ctx, workerHashes)
+	if err != nil {
+		return nil, fmt.Errorf("query upi transactions: %w", err)
+	}
+	tolerance, err := getConfigFloat(ctx, ConfigUPIAmountTolerance)
+	if err != nil {
+		return nil, err
+	}
+
+	end, err := time.Parse(time.RFC3339, GetTxTimestampRFC3339(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("parse tx timestamp: %w", err)
+	}
+	return paymentDiscrepancy(workerIDHash, wages, transactions, end.AddDate(0, 0, -DiscrepancyPeriodDays), end, tolerance), nil
+}
+
+// paymentDiscrepancy computes the CheckMyPaymentDiscrepancy result for the records dated
+// within [start, end].
+func paymentDiscrepancy(workerIDHash string, wages []*WageRecord, transactions []*UPITransaction, start time.Time, end time.Time, tolerance float64) *DiscrepancyResult {
+	result := &DiscrepancyResult{
+		WorkerIDHash: workerIDHash,
+		PeriodStart:  start.UTC().Format(time.RFC3339),
+		PeriodEnd:    end.UTC().Format(time.RFC3339),
+		Currencies:   map[string]*CurrencyDiscrepancy{},
+	}
+	inPeriod := func(timestamp string) bool {
+		at, err := time.Parse(time.RFC3339, timestamp)
+		return err == nil && !at.Before(start) && !at.After(end)
+	}
+	currency := func(code string) *CurrencyDiscrepancy {
+		if result.Currencies[code] == nil {
+			result.Currencies[code] = &CurrencyDiscrepancy{}
+		}
+		return result.Currencies[code]
+	}
+
+	for _, wage := range wages {
+		if inPeriod(wage.Timestamp) {
+			totals := currency(wage.Currency)
+			totals.Declared = addAmount(totals.Declared, wage.WageID, wage.Amount)
+		}
+	}
+	for _, tx := range transactions {
+		if inPeriod(tx.Timestamp) {
+			totals := currency(tx.Currency)
+			totals.Received = addAmount(totals.Received, tx.TxID, tx.Amount)
+		}
+	}
+
+	if len(result.Currencies) == 0 {
+		result.Message = "No wages or UPI payments recorded in this period"
+		return result
+	}
+	result.HasData = true
+
+	for code, totals := range result.Currencies {
+		totals.Declared = roundMoney(totals.Declared, code)
+		totals.Received = roundMoney(totals.Received, code)
+		if gap := roundMoney(totals.Declared-totals.Received, code); gap > tolerance {
+			totals.Shortfall = gap
+			result.Shortfall = true
+		}
+	}
+	if result.Shortfall {
+		result.Message = "Received payments are less than the wages recorded for you"
+	} else {
+		result.Message = "Received payments cover the wages recorded for you"
+	}
+	return result
+}
+
+// GetWorkersByEmployer lists the distinct workers an employer has paid, with the number of
+// wages and total paid to each, largest total first. This is the reverse of
+// GetWorkerEmployers. Results are paged with offset/limit (limit defaults to 100, max 500).
+// SECURITY: Employers can only view their own roster; privileged roles can view any.
+func (s *SmartContract) GetWorkersByEmployer(ctx contractapi.TransactionContextInterface, employerIDHash string, offset int, limit int) ([]WorkerSummary, error) {
+	if employerIDHash == "" {
+		return nil, fmt.Errorf("employerIDHash is required")
+	}
+
+	// IAM Check with self-access validation
+	if IAMEnabled {
+		identity, err := CheckAccess(ctx, "GetWorkersByEmployer")
+		if err != nil {
+			s.LogAccessDenied(ctx, "GetWorkersByEmployer", employerIDHash, "wage", err.Error())
+			return nil, fmt.Errorf("access denied: %w", err)
+		}
+
+		if err := CheckSelfAccess(ctx, identity, "GetWorkersByEmployer", employerIDHash); err != nil {
+			s.LogAccessDenied(ctx, "GetWorkersByEmployer", employerIDHash, "wage", err.Error())
+			return nil, fmt.Errorf("access denied: %w", err)
+		}
+		s.LogDataRead(ctx, "GetWorkersByEmployer", employerIDHash, "wage")
+	}
+
+	if offset < 0 {
+		offset = 0
+	}
+	if limit <= 0 || limit > 500 {
+		limit = 100
+	}
+
+	iterator, err := ctx.GetStub().GetStateByRange("WAGE", "WAGE~")
+	if err != nil {
+		return nil, fmt.Errorf("get state range: %w", err)
+	}
+	defer iterator.Close()
+
+	byWorker := make(map[string]*WorkerSummary)
+	for iterator.HasNext() {
+		queryResponse, err := iterator.Next()
+		if err != nil {
+			return nil, fmt.Errorf("iterate: %w", err)
+		}
+
+		var wage WageRecord
+		if err := json.Unmarshal(queryResponse.Value, &wage); err != nil || wage.DocType != "wage" {
+			continue
+		}
+		if wage.EmployerIDHash != employerIDHash {
+			continue
+		}
+
+		summary, exists := byWorker[wage.WorkerIDHash]
+		if !exists {
+			summary = &WorkerSummary{WorkerIDHash: wage.WorkerIDHash}
+			byWorker[wage.WorkerIDHash] = summary
+		}
+		summary.WageCount++
+		summary.TotalPaid = addAmount(summary.TotalPaid, wage.WageID, wage.Amount)
+	}
+
+	workers := make([]WorkerSummary, 0, len(byWorker))
+	for _, summary := range byWorker {
+		workers = append(workers, *summary)
+	}
+
+	sort.Slice(workers, func(i, j int) bool {
+		if workers[i].TotalPaid != workers[j].TotalPaid {
+			return workers[i].TotalPaid > workers[j].TotalPaid
+		}
+		return workers[i].WorkerIDHash < workers[j].WorkerIDHash
+	})
+
+	if offset >= len(workers) {
+		return []WorkerSummary{}, nil
+	}
+	end := offset + limit
+	if end > len(workers) {
+		end = len(workers)
+	}
+
+	return workers[offset:end], nil
+}
+
+// GetWorkerRiskScore computes a deterministic 0-100 vulnerability score from on-chain wages.
+// Higher scores mean a more vulnerable worker. The score is the sum of three components:
+//
+//   - Stability (0-40): 40 * min(CV, 1), where CV is the coefficient of variation
+//     (population std-dev / mean) of monthly income from the first to the last paid
+//     month inclusive, with unpaid months counted as zero.
+//   - Payment gaps (0-30): 30 * min(longestGapDays / 90, 1), using the longest gap
+//     between consecutive wages, including the gap from the last wage to the
+//     transaction timestamp.
+//   - Poverty (0-30): 30 when income over the 365 days before the transaction timestamp
+//     is below the DEFAULT BPL threshold, otherwise 0.
+//
+// A worker with no wages scores 100. Wages in more than one currency are rejected, since
+// the BPL threshold applies to a single currency. All inputs come from ledger state and
+// the transaction timestamp, so every endorser computes the same result.
+// SECURITY: Workers can only view their own score; privileged roles can view any.
+func (s *SmartContract) GetWorkerRiskScore(ctx contractapi.TransactionContextInterface, workerIDHash string) (*RiskScore, error) {
+	if workerIDHash == "" {
+		return nil, fmt.Errorf("workerIDHash is required")
+	}
+
+	// IAM Check with self-access validation
+	if IAMEnabled {
+		identity, err := CheckAccess(ctx, "GetWorkerRiskScore")
+		if err != nil {
+			s.LogAccessDenied(ctx, "GetWorkerRiskScore", workerIDHash, "income", err.Error())
+			return nil, fmt.Errorf("access denied: %w", err)
+		}
+
+		if err := CheckSelfAccess(ctx, identity, "GetWorkerRiskScore", workerIDHash); err != nil {
+			s.LogAccessDenied(ctx, "GetWorkerRiskScore", workerIDHash, "income", err.Error())
+			return nil, fmt.Errorf("access denied: %w", err)
+		}
+		s.LogDataRead(ctx, "GetWorkerRiskScore", workerIDHash, "income")
+	}
+
+	workerHashes, err := resolveWorkerHashes(ctx, workerIDHash)
+	if err != nil {
+		return nil, err
+	}
+	wages, err := queryWagesForWorkers(ctx, workerHashes)
+	if err != nil {
+		return nil, fmt.Errorf("query wages: %w", err)
+	}
+
+	return riskScoreFromWages(ctx, workerIDHash, wages)
+}
+
+// riskScoreFromWages computes the GetWorkerRiskScore result from a worker's wages.
+// It performs no access checks.
+func riskScoreFromWages(ctx contractapi.TransactionContextInterface, workerIDHash string, wages []*WageRecord) (*RiskScore, error) {
+	now, err := time.Parse(time.RFC3339, GetTxTimestampRFC3339(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("parse tx timestamp: %w", err)
+	}
+
+	bplThreshold := 32000.0 // Default annual BPL threshold
+	if payload, err := ctx.GetStub().GetState("THRESHOLD_DEFAULT_BPL"); err == nil && payload != nil {
+		var threshold PovertyThreshold
+		if err := json.Unmarshal(payload, &threshold); err == nil && threshold.Amount > 0 {
+			bplThreshold = threshold.Amount
+		}
+	}
+
+	currencies := make(map[string]bool)
+	for _, wage := range wages {
+		currencies[wage.Currency] = true
+	}
+	currency, err := singleCurrency(currencies)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &RiskScore{WorkerIDHash: workerIDHash, Currency: currency}
+
+	var paidAt []time.Time
+	monthly := make(map[string]float64)
+	for _, wage := range wages {
+		wageTime, err := time.Parse(time.RFC3339, wage.Timestamp)
+		if err != nil {
+			continue
+		}
+		paidAt = append(paidAt, wageTime)
+		month := wageTime.Format("2006-01")
+		monthly[month] = addAmount(monthly[month], wage.WageID, wage.Amount)
+		if !wageTime.After(now) && now.Sub(wageTime) <= 365*24*time.Hour {
+			result.AnnualIncome = addAmount(result.AnnualIncome, wage.WageID, wage.Amount)
+		}
+	}
+
+	result.PovertyStatus = "APL"
+	if result.AnnualIncome < bplThreshold {
+		result.PovertyStatus = "BPL"
+		result.PovertyComponent = 30
+	}
+
+	if len(paidAt) == 0 {
+		result.StabilityComponent = 40
+		result.PaymentGapComponent = 30
+		result.Score = 100
+		return result, nil
+	}
+
+	sort.Slice(paidAt, func(i, j int) bool { return paidAt[i].Before(paidAt[j]) })
+
+	// Monthly income series from the first to the last paid month, zero-filled
+	var series []float64
+	first := time.Date(paidAt[0].Year(), paidAt[0].Month(), 1, 0, 0, 0, 0, time.UTC)
+	last := time.Date(paidAt[len(paidAt)-1].Year(), paidAt[len(paidAt)-1].Month(), 1, 0, 0, 0, 0, time.UTC)
+	for month := first; !month.After(last); month = month.AddDate(0, 1, 0) {
+		series = append(series, monthly[month.Format("2006-01")])
+	}
+	result.MonthsObserved = len(series)
+
+	result.CoefficientOfVariation, _ = coefficientOfVariation(series)
+	result.StabilityComponent = 40 * math.Min(result.CoefficientOfVariation, 1)
+
+	// Longest gap between consecutive payments, including the time since the last one
+	for i := 1; i < len(paidAt); i++ {
+		result.LongestGapDays = math.Max(result.LongestGapDays, paidAt[i].Sub(paidAt[i-1]).Hours()/24)
+	}
+	if now.After(paidAt[len(paidAt)-1]) {
+		result.LongestGapDays = math.Max(result.LongestGapDays, now.Sub(paidAt[len(paidAt)-1]).Hours()/24)
+	}
+	result.PaymentGapComponent = 30 * math.Min(result.LongestGapDays/90, 1)
+
+	result.Score = result.StabilityComponent + result.PaymentGapComponent + result.PovertyComponent
+
+	return result, nil
+}
+
+// Limits of the CaseFile sections
+const (
+	CaseFileMonths   = 12
+	CaseFilePayments = 10
+)
+
+// GetWorkerCaseFile assembles everything a case worker reviewing one worker needs in one
+// call, across the worker's canonical and alias hashes. Income and poverty status are those
+// of GetWorkerRiskScore. A missing profile, wages in more than one currency, or a section
+// that fails to load is reported in Warnings rather than failing the call.
+// NOTE: This scans all WAGE and UPI keys.
+// SECURITY: Only government officials, auditors, and admins.
+func (s *SmartContract) GetWorkerCaseFile(ctx contractapi.TransactionContextInterface, workerIDHash string) (*CaseFile, error) {
+	if workerIDHash == "" {
+		return nil, fmt.Errorf("workerIDHash is required")
+	}
+
+	// IAM Check
+	if IAMEnabled {
+		_, err := CheckAccess(ctx, "GetWorkerCaseFile")
+		if err != nil {
+			s.LogAccessDenied(ctx, "GetWorkerCaseFile", workerIDHash, "income", err.Error())
+			return nil, fmt.Errorf("access denied: %w", err)
+		}
+		s.LogDataRead(ctx, "GetWorkerCaseFile", workerIDHash, "income")
+	}
+
+	workerHashes, err := resolveWorkerHashes(ctx, workerIDHash)
+	if err != nil {
+		return nil, err
+	}
+	wages, err := queryWagesForWorkers(ctx, workerHashes)
+	if err != nil {
+		return nil, fmt.Errorf("query wages: %w", err)
+	}
+
+	caseFile := &CaseFile{
+		WorkerIDHash:   workerIDHash,
+		MonthlyIncome:  monthlyIncome(wages, nil, CaseFileMonths),
+		OpenAnomalies:  []*Anomaly{},
+		RecentPayments: []*UPITransaction{},
+		GeneratedAt:    GetTxTimestampRFC3339(ctx),
+	}
+	if caseFile.MonthlyIncome == nil {
+		caseFile.MonthlyIncome = []*MonthlyIncome{}
+	}
+
+	if payload, err := ctx.GetStub().GetState(fmt.Sprintf("USER_%s", workerHashes[0])); err != nil {
+		caseFile.Warnings = append(caseFile.Warnings, fmt.Sprintf("profile unavailable: %v", err))
+	} else if payload == nil {
+		caseFile.Warnings = append(caseFile.Warnings, "worker is not registered")
+	} else {
+		profile := new(User)
+		if err := json.Unmarshal(payload, profile); err != nil {
+			caseFile.Warnings = append(caseFile.Warnings, fmt.Sprintf("profile unreadable: %v", err))
+		} else {
+			caseFile.Profile = profile
+		}
+	}
+
+	if total, err := sumIncome(wages, nil, func(string) bool { return true }); err != nil {
+		caseFile.Warnings = append(caseFile.Warnings, fmt.Sprintf("total income unavailable: %v", err))
+	} else {
+		caseFile.TotalIncome = total
+	}
+
+	if risk, err := riskScoreFromWages(ctx, workerIDHash, wages); err != nil {
+		caseFile.Warnings = append(caseFile.Warnings, fmt.Sprintf("poverty status unavailable: %v", err))
+	} else {
+		caseFile.AnnualIncome = roundMoney(risk.AnnualIncome, risk.Currency)
+		caseFile.PovertyStatus = risk.PovertyStatus
+	}
+
+	for _, wage := range wages {
+		anomalies, err := queryAnomaliesForWage(ctx, wage.WageID)
+		if err != nil {
+			caseFile.Warnings = append(caseFile.Warnings, fmt.Sprintf("anomalies for %s unavailable: %v", wage.WageID, err))
+			continue
+		}
+		for _, anomaly := range anomalies {
+			if anomaly.Status == "pending" || anomaly.Status == "reviewed" {
+				caseFile.OpenAnomalies = append(caseFile.OpenAnomalies, anomaly)
+			}
+		}
+	}
+	sortAnomaliesChronologically(caseFile.OpenAnomalies)
+
+	if transactions, err := queryUPIForWorkers(ctx, workerHashes); err != nil {
+		caseFile.Warnings = append(caseFile.Warnings, fmt.Sprintf("payments unavailable: %v", err))
+	} else {
+		sortUPIChronologically(transactions)
+		for i := len(transactions) - 1; i >= 0 && len(caseFile.RecentPayments) < CaseFilePayments; i-- {
+			caseFile.RecentPayments = append(caseFile.RecentPayments, transactions[i])
+		}
+	}
+
+	return caseFile, nil
+}
+
+// coefficientOfVariation returns the population standard deviation of series divided by
+// its mean. ok is false when the mean is not positive, where the ratio is undefined.
+func coefficientOfVariation(series []float64) (cv float64, ok bool) {
+	if len(series) == 0 {
+		return 0, false
+	}
+	var mean float64
+	for _, v := range series {
+		mean += v
+	}
+	mean /= float64(len(series))
+	if mean <= 0 {
+		return 0, false
+	}
+	var variance float64
+	for _, v := range series {
+		variance += (v - mean) * (v - mean)
+	}
+	variance /= float64(len(series))
+	return math.Sqrt(variance) / mean, true
+}
+
+// MaxVolatilityMonths caps the window of GetWorkerWageVolatility
+const MaxVolatilityMonths = 120
+
+// GetWorkerWageVolatility returns the coefficient of variation (population std-dev / mean)
+// of a worker's monthly income over the last `months` calendar months, ending with the
+// month of the transaction timestamp. Months without wages count as zero, and wages under
+// the worker's alias hashes are included. Amounts are summed per month without currency
+// conversion, as in GetWorkerRiskScore.
+//
+// It fails rather than return a misleading value when the window starts before the
+// worker's first recorded wage (insufficient history) or holds no income (zero mean).
+// SECURITY: Workers can only view their own volatility; privileged roles can view any.
+func (s *SmartContract) GetWorkerWageVolatility(ctx contractapi.TransactionContextInterface, workerIDHash string, months int) (float64, error) {
+	if workerIDHash == "" {
+		return 0, fmt.Errorf("workerIDHash is required")
+	}
+	if months < 2 || months > MaxVolatilityMonths {
+		return 0, fmt.Errorf("months must be between 2 and %d, got %d", MaxVolatilityMonths, months)
+	}
+
+	// IAM Check with self-access validation
+	if IAMEnabled {
+		identity, err := CheckAccess(ctx, "GetWorkerWageVolatility")
+		if err != nil {
+			s.LogAccessDenied(ctx, "GetWorkerWageVolatility", workerIDHash, "income", err.Error())
+			return 0, fmt.Errorf("access denied: %w", err)
+		}
+
+		if err := CheckSelfAccess(ctx, identity, "GetWorkerWageVolatility", workerIDHash); err != nil {
+			s.LogAccessDenied(ctx, "GetWorkerWageVolatility", workerIDHash, "income", err.Error())
+			return 0, fmt.Errorf("access denied: %w", err)
+		}
+		s.LogDataRead(ctx, "GetWorkerWageVolatility", workerIDHash, "income")
+	}
+
+	workerHashes, err := resolveWorkerHashes(ctx, workerIDHash)
+	if err != nil {
+		return 0, err
+	}
+	wages, err := queryWagesForWorkers(ctx, workerHashes)
+	if err != nil {
+		return 0, fmt.Errorf("query wages: %w", err)
+	}
+
+	now, err := time.Parse(time.RFC3339, GetTxTimestampRFC3339(ctx))
+	if err != nil {
+		return 0, fmt.Errorf("parse tx timestamp: %w", err)
+	}
+	return wageVolatility(wages, now, months)
+}
+
+// wageVolatility computes the GetWorkerWageVolatility result for the `months` calendar
+// months ending with the month of now. Wages dated after now are ignored.
+func wageVolatility(wages []*WageRecord, now time.Time, months int) (float64, error) {
+	end := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+	start := end.AddDate(0, 1-months, 0)
+
+	firstMonth := ""
+	monthly := make(map[string]float64)
+	for _, wage := range wages {
+		wageTime, err := time.Parse(time.RFC3339, wage.Timestamp)
+		if err != nil || wageTime.After(now) {
+			continue
+		}
+		month := wageTime.Format("2006-01")
+		if firstMonth == "" || month < firstMonth {
+			firstMonth = month
+		}
+		monthly[month] = addAmount(monthly[month], wage.WageID, wage.Amount)
+	}
+
+	// "2006-01" strings order chronologically
+	if firstMonth == "" || firstMonth > start.Format("2006-01") {
+		return 0, fmt.Errorf("insufficient history: the %d-month window starts %s, before the worker's first wage", months, start.Format("2006-01"))
+	}
+
+	series := make([]float64, 0, months)
+	for month := start; !month.After(end); month = month.AddDate(0, 1, 0) {
+		series = append(series, monthly[month.Format("2006-01")])
+	}
+
+	cv, ok := coefficientOfVariation(series)
+	if !ok {
+		return 0, fmt.Errorf("no income between %s and %s; volatility is undefined", start.Format("2006-01"), end.Format("2006-01"))
+	}
+	return cv, nil
+}
+
+// GetWorkerCreditProfile returns the derived income metrics a bank needs to assess a worker's
+// creditworthiness: total and annual income, income stability, payment regularity and BPL
+// status. Individual wages, employers and UPI payments are deliberately left out. The
+// metrics are those of GetWorkerRiskScore, computed over the worker's canonical and alias
+// hashes, so wages in more than one currency are rejected.
+// SECURITY: Bank officers and admins only. Bank officers also need an active consent
+// from the worker to their organisation (see GrantConsent).
+func (s *SmartContract) GetWorkerCreditProfile(ctx contractapi.TransactionContextInterface, workerIDHash string) (*CreditProfile, error) {
+	if workerIDHash == "" {
+		return nil, fmt.Errorf("workerIDHash is required")
+	}
+
+	// IAM Check with the worker's consent
+	if IAMEnabled {
+		identity, err := CheckAccess(ctx, "GetWorkerCreditProfile")
+		if err != nil {
+			s.LogAccessDenied(ctx, "GetWorkerCreditProfile", workerIDHash, "income", err.Error())
+			return nil, fmt.Errorf("access denied: %w", err)
+		}
+
+		if err := checkWorkerConsent(ctx, identity, "GetWorkerCreditProfile", workerIDHash); err != nil {
+			s.LogAccessDenied(ctx, "GetWorkerCreditProfile", workerIDHash, "income", err.Error())
+			return nil, fmt.Errorf("access denied: %w", err)
+		}
+		s.LogDataRead(ctx, "GetWorkerCreditProfile", workerIDHash, "income")
+	}
+
+	workerHashes, err := resolveWorkerHashes(ctx, workerIDHash)
+	if err != nil {
+		return nil, err
+	}
+	wages, err := queryWagesForWorkers(ctx, workerHashes)
+	if err != nil {
+		return nil, fmt.Errorf("query wages: %w", err)
+	}
+
+	risk, err := riskScoreFromWages(ctx, workerIDHash, wages)
+	if err != nil {
+		return nil, err
+	}
+
+	profile := &CreditProfile{
+		WorkerIDHash:           workerIDHash,
+		WageCount:              len(wages),
+		AnnualIncome:           roundMoney(risk.AnnualIncome, risk.Currency),
+		Currency:               risk.Currency,
+		MonthsObserved:         risk.MonthsObserved,
+		CoefficientOfVariation: risk.CoefficientOfVariation,
+		LongestGapDays:         risk.LongestGapDays,
+		PovertyStatus:          risk.PovertyStatus,
+		RiskScore:              risk.Score,
+		GeneratedAt:            GetTxTimestampRFC3339(ctx),
+	}
+	// riskScoreFromWages has already rejected wages in more than one currency
+	if profile.TotalIncome, err = sumIncome(wages, nil, func(string) bool { return true }); err != nil {
+		return nil, err
+	}
+	if profile.MonthsObserved > 0 {
+		profile.AverageMonthlyIncome = roundMoney(profile.TotalIncome/float64(profile.MonthsObserved), profile.Currency)
+	}
+
+	return profile, nil
+}
+
+// ============================================================================
+// UPI TRANSACTION FUNCTIONS
+// ============================================================================
+
+// RecordUPITransaction records a UPI payment transaction on the ledger.
+// SECURITY: Requires 'canRecordUPI' permission; only employers, bank officers, and admins.
+// Called during integration stage when a fake UPI payment is received.
+// When linkedWageID is set, the payment is linked to that wage via OnChainReference, must be
+// in the wage's currency, and its amount is checked against the declared wage amount.
+// employerIDHash optionally attributes the payment to an employer; when empty it is taken
+// from the linked wage, and when both are given they must match.
+// Payments linked to a wage in a quarantined state are rejected (see QuarantineState).
+func (s *SmartContract) RecordUPITransaction(ctx contractapi.TransactionContextInterface, txID string, workerIDHash string, amount float64, currency string, senderName string, senderPhone string, transactionRef string, paymentMethod string, linkedWageID string, employerIDHash string) (string, error) {
+	// IAM Check
+	if IAMEnabled {
+		identity, err := CheckAccess(ctx, "RecordUPITransaction")
+		if err != nil {
+			s.LogAccessDenied(ctx, "RecordUPITransaction", txID, "upi", err.Error())
+			return "", fmt.Errorf("access denied: %w", err)
+		}
+		s.LogAccessGranted(ctx, "RecordUPITransaction", txID, "upi")
+		fmt.Printf("[IAM] RecordUPITransaction by %s for %s, amount %.2f\n", identity.ID, workerIDHash, amount)
+	}
+
+	if err := validateUPITxID(txID); err != nil {
+		return "", err
+	}
+	if workerIDHash == "" {
+		return "", fmt.Errorf("workerIDHash is required")
+	}
+	if amount <= 0 || math.IsNaN(amount) || math.IsInf(amount, 0) {
+		return "", fmt.Errorf("amount must be positive")
+	}
+	if err := ValidateCurrency(ctx, currency); err != nil {
+		return "", err
+	}
+	if strings.TrimSpace(senderName) == "" {
+		return "", fmt.Errorf("senderName is required")
+	}
+	if senderPhone != "" {
+		if err := validatePhoneNumber(senderPhone); err != nil {
+			return "", fmt.Errorf("invalid senderPhone: %w", err)
+		}
+	}
+
+	exists, err := s.UPITransactionExists(ctx, txID)
+	if err != nil {
+		return "", err
+	}
+	if exists {
+		return "", fmt.Errorf("upi transaction %s already recorded", txID)
+	}
+
+	var linkedWage *WageRecord
+	if linkedWageID != "" {
+		linkedWage, err = readWageRecord(ctx, linkedWageID)
+		if err != nil {
+			return "", fmt.Errorf("linked wage: %w", err)
+		}
+		if linkedWage.WorkerIDHash != workerIDHash {
+			return "", fmt.Errorf("linked wage %s belongs to a different worker", linkedWageID)
+		}
+		if linkedWage.Currency != currency {
+			return "", fmt.Errorf("linked wage %s is in %s but the payment is in %s", linkedWageID, linkedWage.Currency, currency)
+		}
+		if employerIDHash == "" {
+			employerIDHash = linkedWage.EmployerIDHash
+		} else if linkedWage.EmployerIDHash != employerIDHash {
+			return "", fmt.Errorf("linked wage %s belongs to a different employer", linkedWageID)
+		}
+		if err := checkStateQuarantine(ctx, linkedWage.State); err != nil {
+			s.LogAccess(ctx, EventQuarantinedWrite, "RecordUPITransaction", txID, "upi", "denied", err.Error())
+			return "", err
+		}
+	}
+
+	if paymentMethod == "" {
+		paymentMethod = "UPI"
+	}
+
+	timestamp := time.Now().UTC().Format(time.RFC3339)
+
+	tx := UPITransaction{
+		DocType:          "upi",
+		TxID:             txID,
+		WorkerIDHash:     workerIDHash,
+		Amount:           amount,
+		Currency:         currency,
+		SenderName:       senderName,
+		SenderPhone:      senderPhone,
+		TransactionRef:   transactionRef,
+		Timestamp:        timestamp,
+		PaymentMethod:    paymentMethod,
+		OnChainReference: linkedWageID,
+		EmployerIDHash:   employerIDHash,
+	}
+
+	payload, err := marshalState(tx)
+	if err != nil {
+		return "", fmt.Errorf("marshal upi transaction: %w", err)
+	}
+
+	// Store with prefix "UPI_" for easy filtering
+	key := fmt.Sprintf("UPI_%s", txID)
+	if err := ctx.GetStub().PutState(key, payload); err != nil {
+		return "", fmt.Errorf("put state: %w", err)
 	}
 
-	return record, nil
+	// Index by sender for exact-match reconciliation lookups
+	if senderName != "" {
+		indexKey, err := ctx.GetStub().CreateCompositeKey("upi~sender", []string{senderName, txID})
+		if err != nil {
+			return "", fmt.Errorf("create composite key: %w", err)
+		}
+		if err := ctx.GetStub().PutState(indexKey, []byte{0x00}); err != nil {
+			return "", fmt.Errorf("put sender index: %w", err)
+		}
+	}
+
+	// Index by employer so payments can be matched to wages without relying on sender names
+	if employerIDHash != "" {
+		indexKey, err := ctx.GetStub().CreateCompositeKey("upi~employer", []string{employerIDHash, txID})
+		if err != nil {
+			return "", fmt.Errorf("create composite key: %w", err)
+		}
+		if err := ctx.GetStub().PutState(indexKey, []byte{0x00}); err != nil {
+			return "", fmt.Errorf("put employer index: %w", err)
+		}
+	}
+
+	if err := putNotification(ctx, workerIDHash, NotificationUPIRecorded, txID); err != nil {
+		return "", err
+	}
+
+	// Emit event for external listeners (e.g., dashboard)
+	eventData, err := marshalState(UPIRecordedEvent{
+		TxID:           tx.TxID,
+		WorkerIDHash:   tx.WorkerIDHash,
+		Amount:         tx.Amount,
+		Currency:       tx.Currency,
+		Timestamp:      tx.Timestamp,
+		LinkedWageID:   tx.OnChainReference,
+		EmployerIDHash: tx.EmployerIDHash,
+	})
+	if err != nil {
+		return "", fmt.Errorf("marshal event: %w", err)
+	}
+	if err := ctx.GetStub().SetEvent("UPITransactionRecorded", eventData); err != nil {
+		fmt.Printf("warning: failed to emit event: %v\n", err)
+	}
+
+	// Detect employers declaring one wage amount but paying another
+	if linkedWage != nil {
+		if err := s.checkUPIAmountMismatch(ctx, &tx, linkedWage); err != nil {
+			return "", err
+		}
+	}
+
+	return key, nil
 }
 
-// WageExists checks whether a wage record is already stored.
-// SECURITY: All authenticated users can check if a wage exists.
-func (s *SmartContract) WageExists(ctx contractapi.TransactionContextInterface, wageID string) (bool, error) {
+// MaxUPITxIDLength bounds UPI txIDs; bank references are well under this
+const MaxUPITxIDLength = 64
+
+// validateUPITxID rejects UPI txIDs that could escape the UPI_ key space. Only ASCII letters,
+// digits, '-', '_' and '.' are allowed after a leading letter or digit, which keeps keys inside
+// the UPI_ to UPI_~ range scans and out of the composite key namespace. IDs that already
+// carry a reserved key prefix (e.g. "UPI_") are rejected so keys are not double-prefixed.
+func validateUPITxID(txID string) error {
+	if txID == "" {
+		return fmt.Errorf("txID is required")
+	}
+	if len(txID) > MaxUPITxIDLength {
+		return fmt.Errorf("invalid txID: longer than %d characters", MaxUPITxIDLength)
+	}
+	for _, prefix := range reservedKeyPrefixes {
+		if strings.HasPrefix(txID, prefix) {
+			return fmt.Errorf("invalid txID %q: prefix %s is reserved", txID, prefix)
+		}
+	}
+	for i, r := range txID {
+		alphanumeric := (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9')
+		if i == 0 && !alphanumeric {
+			return fmt.Errorf("invalid txID %q: must start with a letter or digit", txID)
+		}
+		if !alphanumeric && r != '-' && r != '_' && r != '.' {
+			return fmt.Errorf("invalid txID %q: character %q is not allowed", txID, r)
+		}
+	}
+	return nil
+}
+
+// validatePhoneNumber checks for a 10-digit Indian mobile number, optionally prefixed with +91
+func validatePhoneNumber(phone string) error {
+	number := strings.TrimPrefix(phone, "+91")
+	if len(number) != 10 {
+		return fmt.Errorf("expected 10 digits, got %q", phone)
+	}
+	for _, r := range number {
+		if r < '0' || r > '9' {
+			return fmt.Errorf("must contain only digits, got %q", phone)
+		}
+	}
+	if number[0] < '6' {
+		return fmt.Errorf("mobile numbers start with 6-9, got %q", phone)
+	}
+	return nil
+}
+
+// checkUPIAmountMismatch compares a UPI payment against the wage it settles and
+// automatically flags the wage when the amounts differ beyond the configured tolerance.
+// Amounts are only comparable in the same currency; exchange rates are for display only.
+// A wage that already has an open mismatch anomaly is left as it is, with no audit entry
+// or event; other anomalies on the wage do not suppress the flag.
+func (s *SmartContract) checkUPIAmountMismatch(ctx contractapi.TransactionContextInterface, tx *UPITransaction, wage *WageRecord) error {
+	if tx.Currency != wage.Currency {
+		return fmt.Errorf("cannot compare UPI %s in %s with wage %s in %s", tx.TxID, tx.Currency, wage.WageID, wage.Currency)
+	}
+
+	tolerance, err := getConfigFloat(ctx, ConfigUPIAmountTolerance)
+	if err != nil {
+		return err
+	}
+
+	difference := math.Abs(tx.Amount - wage.Amount)
+	if difference <= tolerance {
+		return nil
+	}
+
+	reason := fmt.Sprintf("UPI amount mismatch: wage %s declared %.2f %s, UPI %s paid %.2f %s",
+		wage.WageID, wage.Amount, wage.Currency, tx.TxID, tx.Amount, tx.Currency)
+
+	// Score grows with the relative size of the discrepancy, capped at 1.0
+	score := 1.0
+	if wage.Amount > 0 {
+		score = math.Min(difference/wage.Amount, 1.0)
+	}
+
+	// One open mismatch anomaly per wage is enough for the review queue
+	category := anomalyReasonCategory(reason)
+	duplicate, err := findOpenAnomaly(ctx, wage.WageID, category)
+	if err != nil {
+		return err
+	}
+	if duplicate != nil {
+		return nil
+	}
+	id, err := nextAnomalyID(ctx, wage.WageID)
+	if err != nil {
+		return err
+	}
+
+	anomaly := Anomaly{
+		DocType:      "anomaly",
+		WageID:       wage.WageID,
+		AnomalyScore: score,
+		Reason:       reason,
+		FlaggedBy:    "system",
+		Status:       "pending",
+		Timestamp:    GetTxTimestampRFC3339(ctx),
+		AnomalyID:    id,
+		Category:     category,
+	}
+	if err := putAnomaly(ctx, &anomaly); err != nil {
+		return err
+	}
+
+	s.LogAccess(ctx, EventAnomalyFlagged, "RecordUPITransaction", wage.WageID, "anomaly", "success", reason)
+
+	// Fabric keeps only the last event set in a transaction, so the mismatch
+	// event deliberately supersedes UPITransactionRecorded
+	eventData, _ := marshalState(map[string]interface{}{
+		"wageId":     wage.WageID,
+		"txId":       tx.TxID,
+		"wageAmount": wage.Amount,
+		"upiAmount":  tx.Amount,
+	})
+	if err := ctx.GetStub().SetEvent("UPIAmountMismatch", eventData); err != nil {
+		fmt.Printf("warning: failed to emit event: %v\n", err)
+	}
+
+	return nil
+}
+
+// UPITransactionExists checks whether a UPI transaction has been recorded.
+// SECURITY: All authenticated users can check if a UPI transaction exists.
+func (s *SmartContract) UPITransactionExists(ctx contractapi.TransactionContextInterface, txID string) (bool, error) {
 	// IAM Check
 	if IAMEnabled {
-		_, err := CheckAccess(ctx, "WageExists")
+		_, err := CheckAccess(ctx, "UPITransactionExists")
 		if err != nil {
-			s.LogAccessDenied(ctx, "WageExists", wageID, "wage", err.Error())
+			s.LogAccessDenied(ctx, "UPITransactionExists", txID, "upi", err.Error())
 			return false, fmt.Errorf("access denied: %w", err)
 		}
 	}
 	
-	payload, err := ctx.GetStub().GetState(wageID)
+	key := fmt.Sprintf("UPI_%s", txID)
+	payload, err := ctx.GetStub().GetState(key)
 	if err != nil {
 		return false, fmt.Errorf("get state: %w", err)
 	}
 	return payload != nil, nil
 }
 
-// QueryWageHistory streams the state history for a given wage record.
-// SECURITY: Authenticated users with clearance level 2+ can query history.
-func (s *SmartContract) QueryWageHistory(ctx contractapi.TransactionContextInterface, wageID string) ([]*WageRecord, error) {
+// ReadUPITransaction retrieves a UPI transaction record by ID.
+// SECURITY: All authenticated users can read UPI transactions.
+func (s *SmartContract) ReadUPITransaction(ctx contractapi.TransactionContextInterface, txID string) (*UPITransaction, error) {
 	// IAM Check
 	if IAMEnabled {
-		_, err := CheckAccess(ctx, "QueryWageHistory")
+		_, err := CheckAccess(ctx, "ReadUPITransaction")
 		if err != nil {
-			s.LogAccessDenied(ctx, "QueryWageHistory", wageID, "wage", err.Error())
+			s.LogAccessDenied(ctx, "ReadUPITransaction", txID, "upi", err.Error())
 			return nil, fmt.Errorf("access denied: %w", err)
 		}
-		s.LogDataRead(ctx, "QueryWageHistory", wageID, "wage")
+		s.LogDataRead(ctx, "ReadUPITransaction", txID, "upi")
 	}
 
-	historyIter, err := ctx.GetStub().GetHistoryForKey(wageID)
+	key := fmt.Sprintf("UPI_%s", txID)
+	payload, err := ctx.GetStub().GetState(key)
 	if err != nil {
-		return nil, fmt.Errorf("get history: %w", err)
+		return nil, fmt.Errorf("get state: %w", err)
+	}
+	if payload == nil {
+		return nil, fmt.Errorf("upi transaction %s not found", txID)
 	}
-	defer historyIter.Close()
-
-	var history []*WageRecord
-	for historyIter.HasNext() {
-		record, err := historyIter.Next()
-		if err != nil {
-			return nil, fmt.Errorf("iterate history: %w", err)
-		}
-
-		if record.Value == nil {
-			continue
-		}
 
-		entry := new(WageRecord)
-		if err := json.Unmarshal(record.Value, entry); err != nil {
-			return nil, fmt.Errorf("unmarshal history record: %w", err)
-		}
-		history = append(history, entry)
+	tx := new(UPITransaction)
+	if err := json.Unmarshal(payload, tx); err != nil {
+		return nil, fmt.Errorf("unmarshal upi transaction: %w", err)
 	}
 
-	return history, nil
+	return tx, nil
 }
 
-// QueryWagesByWorker retrieves all wage records for a specific worker (LevelDB compatible).
-// SECURITY: Workers can only query their own wages; privileged roles can query any worker.
-func (s *SmartContract) QueryWagesByWorker(ctx contractapi.TransactionContextInterface, workerIDHash string) ([]*WageRecord, error) {
+// QueryUPITransactionsByWorker retrieves all UPI transactions for a worker (LevelDB compatible).
+// Results are ordered oldest first (by timestamp, then ID).
+// SECURITY: Workers can only query their own UPI transactions; privileged roles can query any.
+func (s *SmartContract) QueryUPITransactionsByWorker(ctx contractapi.TransactionContextInterface, workerIDHash string) ([]*UPITransaction, error) {
 	if workerIDHash == "" {
 		return nil, fmt.Errorf("workerIDHash is required")
 	}
 
 	// IAM Check with self-access validation
 	if IAMEnabled {
-		identity, err := CheckAccess(ctx, "QueryWagesByWorker")
+		identity, err := CheckAccess(ctx, "QueryUPITransactionsByWorker")
 		if err != nil {
-			s.LogAccessDenied(ctx, "QueryWagesByWorker", workerIDHash, "wage", err.Error())
+			s.LogAccessDenied(ctx, "QueryUPITransactionsByWorker", workerIDHash, "upi", err.Error())
 			return nil, fmt.Errorf("access denied: %w", err)
 		}
 
-		// Check self-access for workers
-		if err := CheckSelfAccess(identity, "QueryWagesByWorker", workerIDHash); err != nil {
-			s.LogAccessDenied(ctx, "QueryWagesByWorker", workerIDHash, "wage", err.Error())
+		if err := CheckSelfAccess(ctx, identity, "QueryUPITransactionsByWorker", workerIDHash); err != nil {
+			s.LogAccessDenied(ctx, "QueryUPITransactionsByWorker", workerIDHash, "upi", err.Error())
 			return nil, fmt.Errorf("access denied: %w", err)
 		}
-		s.LogDataRead(ctx, "QueryWagesByWorker", workerIDHash, "wage")
+		s.LogDataRead(ctx, "QueryUPITransactionsByWorker", workerIDHash, "upi")
 	}
 
-	// Use range query - iterate all keys that could be wages
-	iterator, err := ctx.GetStub().GetStateByRange("", "")
+	transactions, err := queryUPIForWorkers(ctx, []string{workerIDHash})
 	if err != nil {
-		return nil, fmt.Errorf("get state range: %w", err)
-	}
-	defer iterator.Close()
-
-	var wages []*WageRecord
-	for iterator.HasNext() {
-		queryResponse, err := iterator.Next()
-		if err != nil {
-			return nil, fmt.Errorf("iterate: %w", err)
-		}
-
-		// Skip non-wage records
-		if !strings.HasPrefix(queryResponse.Key, "WAGE") {
-			continue
-		}
-
-		var wage WageRecord
-		if err := json.Unmarshal(queryResponse.Value, &wage); err != nil {
-			continue // Skip records that don't unmarshal as wage
-		}
-
-		if wage.WorkerIDHash == workerIDHash {
-			wages = append(wages, &wage)
-		}
+		return nil, err
 	}
+	sortUPIChronologically(transactions)
 
-	return wages, nil
+	return transactions, nil
 }
 
-// QueryWagesByEmployer retrieves all wage records paid by a specific employer (LevelDB compatible).
-// SECURITY: Employers can only query their own wages; privileged roles can query any employer.
-func (s *SmartContract) QueryWagesByEmployer(ctx contractapi.TransactionContextInterface, employerIDHash string) ([]*WageRecord, error) {
-	if employerIDHash == "" {
-		return nil, fmt.Errorf("employerIDHash is required")
+// QueryUPIBySender retrieves the UPI transactions of a payer identified by exact sender
+// name, reconciling by the structured EmployerIDHash where it is set: the payments matching
+// the name are looked up via the upi~sender index, and for every employer they are
+// attributed to, all of that employer's payments (upi~employer) are returned, whatever
+// sender name they were recorded under. Payments without an EmployerIDHash fall back to the
+// sender name match alone. Transactions recorded before the indexes existed are not
+// returned. If the state database moves to CouchDB and this becomes a rich query, it
+// requires an index on ["docType", "senderName"] under META-INF/statedb/couchdb/indexes.
+// Results are ordered oldest first (by timestamp, then ID).
+// SECURITY: Only bank officers, auditors, and admins.
+func (s *SmartContract) QueryUPIBySender(ctx contractapi.TransactionContextInterface, senderName string) ([]*UPITransaction, error) {
+	if senderName == "" {
+		return nil, fmt.Errorf("senderName is required")
 	}
 
-	// IAM Check with self-access validation
+	// IAM Check
 	if IAMEnabled {
-		identity, err := CheckAccess(ctx, "QueryWagesByEmployer")
+		_, err := CheckAccess(ctx, "QueryUPIBySender")
 		if err != nil {
-			s.LogAccessDenied(ctx, "QueryWagesByEmployer", employerIDHash, "wage", err.Error())
+			s.LogAccessDenied(ctx, "QueryUPIBySender", senderName, "upi", err.Error())
 			return nil, fmt.Errorf("access denied: %w", err)
 		}
+		s.LogDataRead(ctx, "QueryUPIBySender", senderName, "upi")
+	}
 
-		// Check self-access for employers
-		if err := CheckSelfAccess(identity, "QueryWagesByEmployer", employerIDHash); err != nil {
-			s.LogAccessDenied(ctx, "QueryWagesByEmployer", employerIDHash, "wage", err.Error())
-			return nil, fmt.Errorf("access denied: %w", err)
+	byName, err := queryUPIByIndex(ctx, "upi~sender", senderName)
+	if err != nil {
+		return nil, err
+	}
+
+	transactions := []*UPITransaction{}
+	seen := make(map[string]bool)
+	employerSeen := make(map[string]bool)
+	var employers []string
+	for _, tx := range byName {
+		if tx.EmployerIDHash == "" {
+			transactions = append(transactions, tx)
+			seen[tx.TxID] = true
+		} else if !employerSeen[tx.EmployerIDHash] {
+			employerSeen[tx.EmployerIDHash] = true
+			employers = append(employers, tx.EmployerIDHash)
+		}
+	}
+	for _, employerIDHash := range employers {
+		byEmployer, err := queryUPIByIndex(ctx, "upi~employer", employerIDHash)
+		if err != nil {
+			return nil, err
+		}
+		for _, tx := range byEmployer {
+			if !seen[tx.TxID] {
+				seen[tx.TxID] = true
+				transactions = append(transactions, tx)
+			}
 		}
-		s.LogDataRead(ctx, "QueryWagesByEmployer", employerIDHash, "wage")
 	}
 
-	iterator, err := ctx.GetStub().GetStateByRange("", "")
+	sortUPIChronologically(transactions)
+
+	return transactions, nil
+}
+
+// queryUPIByIndex returns the UPI transactions listed under a "<index>" composite key
+// [value, txID], e.g. upi~sender or upi~employer, in index order.
+func queryUPIByIndex(ctx contractapi.TransactionContextInterface, index string, value string) ([]*UPITransaction, error) {
+	iterator, err := ctx.GetStub().GetStateByPartialCompositeKey(index, []string{value})
 	if err != nil {
-		return nil, fmt.Errorf("get state range: %w", err)
+		return nil, fmt.Errorf("get %s index: %w", index, err)
 	}
 	defer iterator.Close()
 
-	var wages []*WageRecord
+	transactions := []*UPITransaction{}
 	for iterator.HasNext() {
 		queryResponse, err := iterator.Next()
 		if err != nil {
 			return nil, fmt.Errorf("iterate: %w", err)
 		}
 
-		if !strings.HasPrefix(queryResponse.Key, "WAGE") {
+		_, parts, err := ctx.GetStub().SplitCompositeKey(queryResponse.Key)
+		if err != nil || len(parts) != 2 {
 			continue
 		}
 
-		var wage WageRecord
-		if err := json.Unmarshal(queryResponse.Value, &wage); err != nil {
+		payload, err := ctx.GetStub().GetState(fmt.Sprintf("UPI_%s", parts[1]))
+		if err != nil {
+			return nil, fmt.Errorf("get state: %w", err)
+		}
+		if payload == nil {
 			continue
 		}
 
-		if wage.EmployerIDHash == employerIDHash {
-			wages = append(wages, &wage)
+		var tx UPITransaction
+		if err := json.Unmarshal(payload, &tx); err != nil {
+			continue
 		}
+		transactions = append(transactions, &tx)
 	}
-
-	return wages, nil
+	return transactions, nil
 }
 
-// CalculateTotalIncome calculates total income for a worker within a date range.
-// CalculateTotalIncome calculates total income for a worker within a date range.
-// SECURITY: Workers can only calculate their own income; privileged roles can calculate any.
-func (s *SmartContract) CalculateTotalIncome(ctx contractapi.TransactionContextInterface, workerIDHash string, startDate string, endDate string) (float64, error) {
-	if workerIDHash == "" {
-		return 0, fmt.Errorf("workerIDHash is required")
+// QueryUPIByEmployer retrieves the UPI transactions attributed to an employer via their
+// EmployerIDHash, for reconciling payments against the employer's declared wages. Uses the
+// upi~employer index maintained by RecordUPITransaction; payments recorded without an
+// employer are not returned. Results are ordered oldest first (by timestamp, then ID).
+// SECURITY: Employers can only query their own payments; bank officers, auditors, and admins can query any.
+func (s *SmartContract) QueryUPIByEmployer(ctx contractapi.TransactionContextInterface, employerIDHash string) ([]*UPITransaction, error) {
+	if employerIDHash == "" {
+		return nil, fmt.Errorf("employerIDHash is required")
 	}
 
 	// IAM Check with self-access validation
 	if IAMEnabled {
-		identity, err := CheckAccess(ctx, "CalculateTotalIncome")
+		identity, err := CheckAccess(ctx, "QueryUPIByEmployer")
 		if err != nil {
-			s.LogAccessDenied(ctx, "CalculateTotalIncome", workerIDHash, "income", err.Error())
-			return 0, fmt.Errorf("access denied: %w", err)
+			s.LogAccessDenied(ctx, "QueryUPIByEmployer", employerIDHash, "upi", err.Error())
+			return nil, fmt.Errorf("access denied: %w", err)
 		}
 
-		if err := CheckSelfAccess(identity, "CalculateTotalIncome", workerIDHash); err != nil {
-			s.LogAccessDenied(ctx, "CalculateTotalIncome", workerIDHash, "income", err.Error())
-			return 0, fmt.Errorf("access denied: %w", err)
+		// Bank officers are not self-access bypass roles, so only employers are held to their own data
+		if identity.Role == "employer" {
+			if err := CheckSelfAccess(ctx, identity, "QueryUPIByEmployer", employerIDHash); err != nil {
+				s.LogAccessDenied(ctx, "QueryUPIByEmployer", employerIDHash, "upi", err.Error())
+				return nil, fmt.Errorf("access denied: %w", err)
+			}
 		}
-		s.LogDataRead(ctx, "CalculateTotalIncome", workerIDHash, "income")
+		s.LogDataRead(ctx, "QueryUPIByEmployer", employerIDHash, "upi")
 	}
 
-	wages, err := s.QueryWagesByWorker(ctx, workerIDHash)
+	transactions, err := queryUPIByIndex(ctx, "upi~employer", employerIDHash)
 	if err != nil {
-		return 0, fmt.Errorf("query wages: %w", err)
+		return nil, err
 	}
 
-	var totalIncome float64
-	for _, wage := range wages {
-		// Parse timestamp and filter by date range if provided
-		if startDate != "" && endDate != "" {
-			wageTime, err := time.Parse(time.RFC3339, wage.Timestamp)
-			if err != nil {
-				continue // Skip records with invalid timestamps
-			}
-
-			start, err := time.Parse("2006-01-02", startDate)
-			if err != nil {
-				start, _ = time.Parse(time.RFC3339, startDate)
-			}
+	sortUPIChronologically(transactions)
 
-			end, err := time.Parse("2006-01-02", endDate)
-			if err != nil {
-				end, _ = time.Parse(time.RFC3339, endDate)
-			}
+	return transactions, nil
+}
 
-			if wageTime.Before(start) || wageTime.After(end) {
-				continue
-			}
-		}
+// MaxSettlementRangeDays caps the date span GetUPISettlementSummary scans in one call
+const MaxSettlementRangeDays = 92
 
-		totalIncome += wage.Amount
+// GetUPISettlementSummary groups UPI transactions by UTC day (YYYY-MM-DD) between startDate
+// and endDate inclusive, with a count and per-currency total for each day, to reconcile
+// against the bank's daily batches. Days without transactions are omitted.
+// NOTE: This scans all UPI_ keys.
+// SECURITY: Only bank officers, auditors, and admins.
+func (s *SmartContract) GetUPISettlementSummary(ctx contractapi.TransactionContextInterface, startDate string, endDate string) (map[string]DaySummary, error) {
+	start, err := time.Parse("2006-01-02", startDate)
+	if err != nil {
+		return nil, fmt.Errorf("invalid startDate %q: expected YYYY-MM-DD", startDate)
 	}
+	end, err := time.Parse("2006-01-02", endDate)
+	if err != nil {
+		return nil, fmt.Errorf("invalid endDate %q: expected YYYY-MM-DD", endDate)
+	}
+	if end.Before(start) {
+		return nil, fmt.Errorf("endDate must not be before startDate")
+	}
+	if end.Sub(start) > (MaxSettlementRangeDays-1)*24*time.Hour {
+		return nil, fmt.Errorf("date range exceeds %d days", MaxSettlementRangeDays)
+	}
+	endExclusive := end.AddDate(0, 0, 1)
 
-	return totalIncome, nil
-}
-
-// BatchRecordWages records multiple wage transactions in a single call.
-// SECURITY: Requires 'canRecordWage' and 'canBatchProcess' permissions with clearance level 6+.
-func (s *SmartContract) BatchRecordWages(ctx contractapi.TransactionContextInterface, wagesJSON string) ([]string, error) {
 	// IAM Check
 	if IAMEnabled {
-		identity, err := CheckAccess(ctx, "BatchRecordWages")
+		_, err := CheckAccess(ctx, "GetUPISettlementSummary")
 		if err != nil {
-			s.LogAccessDenied(ctx, "BatchRecordWages", "batch", "wage", err.Error())
+			s.LogAccessDenied(ctx, "GetUPISettlementSummary", "all", "upi", err.Error())
 			return nil, fmt.Errorf("access denied: %w", err)
 		}
-		s.LogAccessGranted(ctx, "BatchRecordWages", "batch", "wage")
-		fmt.Printf("[IAM] BatchRecordWages by %s\n", identity.ID)
+		s.LogDataRead(ctx, "GetUPISettlementSummary", fmt.Sprintf("%s..%s", startDate, endDate), "upi")
 	}
 
-	var wages []struct {
-		WageID         string  `json:"wageId"`
-		WorkerIDHash   string  `json:"workerIdHash"`
-		EmployerIDHash string  `json:"employerIdHash"`
-		Amount         float64 `json:"amount"`
-		Currency       string  `json:"currency"`
-		JobType        string  `json:"jobType"`
-		Timestamp      string  `json:"timestamp"`
-		PolicyVersion  string  `json:"policyVersion"`
+	iterator, err := ctx.GetStub().GetStateByRange("UPI_", "UPI_~")
+	if err != nil {
+		return nil, fmt.Errorf("get state range: %w", err)
 	}
+	defer iterator.Close()
 
-	if err := json.Unmarshal([]byte(wagesJSON), &wages); err != nil {
-		return nil, fmt.Errorf("unmarshal wages: %w", err)
-	}
+	days := make(map[string]DaySummary)
+	for iterator.HasNext() {
+		queryResponse, err := iterator.Next()
+		if err != nil {
+			return nil, fmt.Errorf("iterate: %w", err)
+		}
 
-	var createdIDs []string
-	for _, w := range wages {
-		err := s.RecordWage(ctx, w.WageID, w.WorkerIDHash, w.EmployerIDHash, w.Amount, w.Currency, w.JobType, w.Timestamp, w.PolicyVersion)
+		var tx UPITransaction
+		if err := json.Unmarshal(queryResponse.Value, &tx); err != nil {
+			continue
+		}
+		txTime, err := time.Parse(time.RFC3339, tx.Timestamp)
 		if err != nil {
-			// Continue with other wages even if one fails
 			continue
 		}
-		createdIDs = append(createdIDs, w.WageID)
-	}
+		txTime = txTime.UTC()
+		if txTime.Before(start) || !txTime.Before(endExclusive) {
+			continue
+		}
 
-	return createdIDs, nil
-}
+		date := txTime.Format("2006-01-02")
+		day, exists := days[date]
+		if !exists {
+			day = DaySummary{Date: date, Totals: make(map[string]float64)}
+		}
+		day.Count++
+		day.Totals[tx.Currency] = addAmount(day.Totals[tx.Currency], tx.TxID, tx.Amount)
+		days[date] = day
+	}
 
-// GetWorkerIncomeHistory retrieves monthly income breakdown for a worker.
-// SECURITY: Workers can only view their own history; privileged roles can view any.
-func (s *SmartContract) GetWorkerIncomeHistory(ctx contractapi.TransactionContextInterface, workerIDHash string, months int) ([]*MonthlyIncome, error) {
-	if workerIDHash == "" {
-		return nil, fmt.Errorf("workerIDHash is required")
+	for _, day := range days {
+		roundMoneyTotals(day.Totals)
 	}
 
-	// IAM Check with self-access validation
+	return days, nil
+}
+
+// QueryWagesByOutdatedPolicy returns wages whose PolicyVersion differs from currentVersion, for
+// re-evaluation after a policy change. An empty currentVersion uses the currentPolicyVersion
+// config. Results are ordered oldest first and paginated with offset/limit (limit defaults to
+// 100, max 500).
+// NOTE: This scans all WAGE keys.
+// SECURITY: Only government officials and admins.
+func (s *SmartContract) QueryWagesByOutdatedPolicy(ctx contractapi.TransactionContextInterface, currentVersion string, offset int, limit int) ([]*WageRecord, error) {
+	// IAM Check
 	if IAMEnabled {
-		identity, err := CheckAccess(ctx, "GetWorkerIncomeHistory")
+		_, err := CheckAccess(ctx, "QueryWagesByOutdatedPolicy")
 		if err != nil {
-			s.LogAccessDenied(ctx, "GetWorkerIncomeHistory", workerIDHash, "income", err.Error())
+			s.LogAccessDenied(ctx, "QueryWagesByOutdatedPolicy", currentVersion, "wage", err.Error())
 			return nil, fmt.Errorf("access denied: %w", err)
 		}
+		s.LogDataRead(ctx, "QueryWagesByOutdatedPolicy", currentVersion, "wage")
+	}
 
-		if err := CheckSelfAccess(identity, "GetWorkerIncomeHistory", workerIDHash); err != nil {
-			s.LogAccessDenied(ctx, "GetWorkerIncomeHistory", workerIDHash, "income", err.Error())
-			return nil, fmt.Errorf("access denied: %w", err)
+	if currentVersion == "" {
+		configured, err := getConfigValue(ctx, ConfigCurrentPolicyVersion)
+		if err != nil {
+			return nil, err
 		}
-		s.LogDataRead(ctx, "GetWorkerIncomeHistory", workerIDHash, "income")
+		currentVersion = configured
 	}
 
-	if months <= 0 {
-		months = 12 // Default to 12 months
+	if offset < 0 {
+		offset = 0
+	}
+	if limit <= 0 || limit > 500 {
+		limit = 100
 	}
 
-	wages, err := s.QueryWagesByWorker(ctx, workerIDHash)
+	iterator, err := ctx.GetStub().GetStateByRange("WAGE", "WAGE~")
 	if err != nil {
-		return nil, fmt.Errorf("query wages: %w", err)
+		return nil, fmt.Errorf("get state range: %w", err)
 	}
+	defer iterator.Close()
 
-	// Group wages by month
-	monthlyData := make(map[string]*MonthlyIncome)
-	for _, wage := range wages {
-		wageTime, err := time.Parse(time.RFC3339, wage.Timestamp)
+	outdated := []*WageRecord{}
+	for iterator.HasNext() {
+		queryResponse, err := iterator.Next()
 		if err != nil {
-			continue
+			return nil, fmt.Errorf("iterate: %w", err)
 		}
 
-		monthKey := wageTime.Format("2006-01")
-		if _, exists := monthlyData[monthKey]; !exists {
-			monthlyData[monthKey] = &MonthlyIncome{
-				Month:       monthKey,
-				TotalIncome: 0,
-				WageCount:   0,
-			}
+		var wage WageRecord
+		if err := json.Unmarshal(queryResponse.Value, &wage); err != nil || wage.DocType != "wage" {
+			continue
+		}
+		if wage.PolicyVersion != currentVersion {
+			outdated = append(outdated, &wage)
 		}
-		monthlyData[monthKey].TotalIncome += wage.Amount
-		monthlyData[monthKey].WageCount++
-	}
-
-	// Convert to slice and sort
-	var result []*MonthlyIncome
-	for _, income := range monthlyData {
-		result = append(result, income)
 	}
 
-	// Sort by month descending
-	sort.Slice(result, func(i, j int) bool {
-		return result[i].Month > result[j].Month
-	})
+	sortWagesChronologically(outdated)
 
-	// Limit to requested months
-	if len(result) > months {
-		result = result[:months]
+	if offset >= len(outdated) {
+		return []*WageRecord{}, nil
+	}
+	end := offset + limit
+	if end > len(outdated) {
+		end = len(outdated)
 	}
 
-	return result, nil
+	return outdated[offset:end], nil
 }
 
-// ============================================================================
-// UPI TRANSACTION FUNCTIONS
-// ============================================================================
+// GetWagesWithoutUPI returns wages older than olderThanDays (relative to the transaction
+// timestamp) that no UPI transaction links to via OnChainReference, i.e. wages that were
+// declared but never paid. Results are ordered oldest first and paginated with offset/limit.
+// NOTE: This scans all UPI_ and WAGE keys.
+// SECURITY: Only government officials, auditors, and admins.
+func (s *SmartContract) GetWagesWithoutUPI(ctx contractapi.TransactionContextInterface, olderThanDays int, offset int, limit int) ([]*WageRecord, error) {
+	if olderThanDays < 0 {
+		return nil, fmt.Errorf("olderThanDays must not be negative")
+	}
 
-// RecordUPITransaction records a UPI payment transaction on the ledger.
-// SECURITY: Requires 'canRecordUPI' permission; only employers, bank officers, and admins.
-// Called during integration stage when a fake UPI payment is received.
-func (s *SmartContract) RecordUPITransaction(ctx contractapi.TransactionContextInterface, txID string, workerIDHash string, amount float64, currency string, senderName string, senderPhone string, transactionRef string, paymentMethod string) (string, error) {
 	// IAM Check
 	if IAMEnabled {
-		identity, err := CheckAccess(ctx, "RecordUPITransaction")
+		_, err := CheckAccess(ctx, "GetWagesWithoutUPI")
 		if err != nil {
-			s.LogAccessDenied(ctx, "RecordUPITransaction", txID, "upi", err.Error())
-			return "", fmt.Errorf("access denied: %w", err)
+			s.LogAccessDenied(ctx, "GetWagesWithoutUPI", "all", "wage", err.Error())
+			return nil, fmt.Errorf("access denied: %w", err)
 		}
-		s.LogAccessGranted(ctx, "RecordUPITransaction", txID, "upi")
-		fmt.Printf("[IAM] RecordUPITransaction by %s for %s, amount %.2f\n", identity.ID, workerIDHash, amount)
+		s.LogDataRead(ctx, "GetWagesWithoutUPI", fmt.Sprintf("olderThanDays:%d", olderThanDays), "wage")
 	}
 
-	if txID == "" {
-		return "", fmt.Errorf("txID is required")
-	}
-	if workerIDHash == "" {
-		return "", fmt.Errorf("workerIDHash is required")
+	if offset < 0 {
+		offset = 0
 	}
-	if amount <= 0 {
-		return "", fmt.Errorf("amount must be positive")
+	if limit <= 0 || limit > 500 {
+		limit = 100
 	}
 
-	exists, err := s.UPITransactionExists(ctx, txID)
+	now, err := time.Parse(time.RFC3339, GetTxTimestampRFC3339(ctx))
 	if err != nil {
-		return "", err
-	}
-	if exists {
-		return "", fmt.Errorf("upi transaction %s already recorded", txID)
+		return nil, fmt.Errorf("parse tx timestamp: %w", err)
 	}
+	cutoff := now.AddDate(0, 0, -olderThanDays)
 
-	if paymentMethod == "" {
-		paymentMethod = "UPI"
+	// Collect every wage that a UPI payment settles
+	paid := make(map[string]bool)
+	upiIterator, err := ctx.GetStub().GetStateByRange("UPI_", "UPI_~")
+	if err != nil {
+		return nil, fmt.Errorf("get state range: %w", err)
 	}
+	defer upiIterator.Close()
 
-	timestamp := time.Now().UTC().Format(time.RFC3339)
+	for upiIterator.HasNext() {
+		queryResponse, err := upiIterator.Next()
+		if err != nil {
+			return nil, fmt.Errorf("iterate: %w", err)
+		}
 
-	tx := UPITransaction{
-		DocType:          "upi",
-		TxID:             txID,
-		WorkerIDHash:     workerIDHash,
-		Amount:           amount,
-		Currency:         currency,
-		SenderName:       senderName,
-		SenderPhone:      senderPhone,
-		TransactionRef:   transactionRef,
-		Timestamp:        timestamp,
-		PaymentMethod:    paymentMethod,
-		OnChainReference: fmt.Sprintf("UPI_%s", txID), // Set the on-chain reference
+		var tx UPITransaction
+		if err := json.Unmarshal(queryResponse.Value, &tx); err != nil {
+			continue
+		}
+		if tx.OnChainReference != "" {
+			paid[tx.OnChainReference] = true
+		}
 	}
 
-	payload, err := json.Marshal(tx)
+	wageIterator, err := ctx.GetStub().GetStateByRange("WAGE", "WAGE~")
 	if err != nil {
-		return "", fmt.Errorf("marshal upi transaction: %w", err)
-	}
-
-	// Store with prefix "UPI_" for easy filtering
-	key := fmt.Sprintf("UPI_%s", txID)
-	if err := ctx.GetStub().PutState(key, payload); err != nil {
-		return "", fmt.Errorf("put state: %w", err)
+		return nil, fmt.Errorf("get state range: %w", err)
 	}
+	defer wageIterator.Close()
 
-	// Emit event for external listeners (e.g., dashboard)
-	if err := ctx.GetStub().SetEvent("UPITransactionRecorded", []byte(txID)); err != nil {
-		fmt.Printf("warning: failed to emit event: %v\n", err)
+	type datedWage struct {
+		wage *WageRecord
+		at   time.Time
 	}
+	var unpaid []datedWage
+	for wageIterator.HasNext() {
+		queryResponse, err := wageIterator.Next()
+		if err != nil {
+			return nil, fmt.Errorf("iterate: %w", err)
+		}
 
-	return key, nil
-}
+		var wage WageRecord
+		if err := json.Unmarshal(queryResponse.Value, &wage); err != nil || wage.DocType != "wage" {
+			continue
+		}
+		if paid[wage.WageID] {
+			continue
+		}
 
-// UPITransactionExists checks whether a UPI transaction has been recorded.
-// SECURITY: All authenticated users can check if a UPI transaction exists.
-func (s *SmartContract) UPITransactionExists(ctx contractapi.TransactionContextInterface, txID string) (bool, error) {
-	// IAM Check
-	if IAMEnabled {
-		_, err := CheckAccess(ctx, "UPITransactionExists")
-		if err != nil {
-			s.LogAccessDenied(ctx, "UPITransactionExists", txID, "upi", err.Error())
-			return false, fmt.Errorf("access denied: %w", err)
+		wageTime, err := time.Parse(time.RFC3339, wage.Timestamp)
+		if err != nil || wageTime.After(cutoff) {
+			continue
 		}
+		unpaid = append(unpaid, datedWage{wage: &wage, at: wageTime})
 	}
-	
-	key := fmt.Sprintf("UPI_%s", txID)
-	payload, err := ctx.GetStub().GetState(key)
-	if err != nil {
-		return false, fmt.Errorf("get state: %w", err)
-	}
-	return payload != nil, nil
-}
 
-// ReadUPITransaction retrieves a UPI transaction record by ID.
-// SECURITY: All authenticated users can read UPI transactions.
-func (s *SmartContract) ReadUPITransaction(ctx contractapi.TransactionContextInterface, txID string) (*UPITransaction, error) {
-	// IAM Check
-	if IAMEnabled {
-		_, err := CheckAccess(ctx, "ReadUPITransaction")
-		if err != nil {
-			s.LogAccessDenied(ctx, "ReadUPITransaction", txID, "upi", err.Error())
-			return nil, fmt.Errorf("access denied: %w", err)
+	sort.Slice(unpaid, func(i, j int) bool {
+		if !unpaid[i].at.Equal(unpaid[j].at) {
+			return unpaid[i].at.Before(unpaid[j].at)
 		}
-		s.LogDataRead(ctx, "ReadUPITransaction", txID, "upi")
-	}
+		return unpaid[i].wage.WageID < unpaid[j].wage.WageID
+	})
 
-	key := fmt.Sprintf("UPI_%s", txID)
-	payload, err := ctx.GetStub().GetState(key)
-	if err != nil {
-		return nil, fmt.Errorf("get state: %w", err)
+	if offset >= len(unpaid) {
+		return []*WageRecord{}, nil
 	}
-	if payload == nil {
-		return nil, fmt.Errorf("upi transaction %s not found", txID)
+	end := offset + limit
+	if end > len(unpaid) {
+		end = len(unpaid)
 	}
 
-	tx := new(UPITransaction)
-	if err := json.Unmarshal(payload, tx); err != nil {
-		return nil, fmt.Errorf("unmarshal upi transaction: %w", err)
+	wages := make([]*WageRecord, 0, end-offset)
+	for _, entry := range unpaid[offset:end] {
+		wages = append(wages, entry.wage)
 	}
 
-	return tx, nil
+	return wages, nil
 }
 
-// QueryUPITransactionsByWorker retrieves all UPI transactions for a worker (LevelDB compatible).
-// SECURITY: Workers can only query their own UPI transactions; privileged roles can query any.
-func (s *SmartContract) QueryUPITransactionsByWorker(ctx contractapi.TransactionContextInterface, workerIDHash string) ([]*UPITransaction, error) {
-	if workerIDHash == "" {
-		return nil, fmt.Errorf("workerIDHash is required")
+// GetUPITransactionsWithoutWage returns UPI payments older than olderThanDays (relative to
+// the transaction timestamp) whose OnChainReference is empty or names no existing wage, i.e.
+// payments made without a declared wage. It is the counterpart of GetWagesWithoutUPI.
+// Results are ordered oldest first and paginated with offset/limit.
+// NOTE: This scans all UPI_ keys.
+// SECURITY: Only government officials and auditors.
+func (s *SmartContract) GetUPITransactionsWithoutWage(ctx contractapi.TransactionContextInterface, olderThanDays int, offset int, limit int) ([]*UPITransaction, error) {
+	if olderThanDays < 0 {
+		return nil, fmt.Errorf("olderThanDays must not be negative")
 	}
 
-	// IAM Check with self-access validation
+	// IAM Check
 	if IAMEnabled {
-		identity, err := CheckAccess(ctx, "QueryUPITransactionsByWorker")
+		_, err := CheckAccess(ctx, "GetUPITransactionsWithoutWage")
 		if err != nil {
-			s.LogAccessDenied(ctx, "QueryUPITransactionsByWorker", workerIDHash, "upi", err.Error())
+			s.LogAccessDenied(ctx, "GetUPITransactionsWithoutWage", "all", "upi", err.Error())
 			return nil, fmt.Errorf("access denied: %w", err)
 		}
+		s.LogDataRead(ctx, "GetUPITransactionsWithoutWage", fmt.Sprintf("olderThanDays:%d", olderThanDays), "upi")
+	}
 
-		if err := CheckSelfAccess(identity, "QueryUPITransactionsByWorker", workerIDHash); err != nil {
-			s.LogAccessDenied(ctx, "QueryUPITransactionsByWorker", workerIDHash, "upi", err.Error())
-			return nil, fmt.Errorf("access denied: %w", err)
-		}
-		s.LogDataRead(ctx, "QueryUPITransactionsByWorker", workerIDHash, "upi")
+	if offset < 0 {
+		offset = 0
+	}
+	if limit <= 0 || limit > 500 {
+		limit = 100
+	}
+
+	now, err := time.Parse(time.RFC3339, GetTxTimestampRFC3339(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("parse tx timestamp: %w", err)
 	}
+	cutoff := now.AddDate(0, 0, -olderThanDays)
 
 	iterator, err := ctx.GetStub().GetStateByRange("UPI_", "UPI_~")
 	if err != nil {
@@ -792,7 +4873,11 @@ func (s *SmartContract) QueryUPITransactionsByWorker(ctx contractapi.Transaction
 	}
 	defer iterator.Close()
 
-	var transactions []*UPITransaction
+	type datedTx struct {
+		tx *UPITransaction
+		at time.Time
+	}
+	var unlinked []datedTx
 	for iterator.HasNext() {
 		queryResponse, err := iterator.Next()
 		if err != nil {
@@ -804,9 +4889,41 @@ func (s *SmartContract) QueryUPITransactionsByWorker(ctx contractapi.Transaction
 			continue
 		}
 
-		if tx.WorkerIDHash == workerIDHash {
-			transactions = append(transactions, &tx)
+		txTime, err := time.Parse(time.RFC3339, tx.Timestamp)
+		if err != nil || txTime.After(cutoff) {
+			continue
+		}
+
+		if tx.OnChainReference != "" {
+			wagePayload, err := ctx.GetStub().GetState(tx.OnChainReference)
+			if err != nil {
+				return nil, fmt.Errorf("get state: %w", err)
+			}
+			if wagePayload != nil {
+				continue
+			}
+		}
+		unlinked = append(unlinked, datedTx{tx: &tx, at: txTime})
+	}
+
+	sort.Slice(unlinked, func(i, j int) bool {
+		if !unlinked[i].at.Equal(unlinked[j].at) {
+			return unlinked[i].at.Before(unlinked[j].at)
 		}
+		return unlinked[i].tx.TxID < unlinked[j].tx.TxID
+	})
+
+	if offset >= len(unlinked) {
+		return []*UPITransaction{}, nil
+	}
+	end := offset + limit
+	if end > len(unlinked) {
+		end = len(unlinked)
+	}
+
+	transactions := make([]*UPITransaction, 0, end-offset)
+	for _, entry := range unlinked[offset:end] {
+		transactions = append(transactions, entry.tx)
 	}
 
 	return transactions, nil
@@ -838,15 +4955,7 @@ func (s *SmartContract) RegisterUser(ctx contractapi.TransactionContextInterface
 	}
 
 	// Validate role
-	validRoles := map[string]bool{
-		"worker":              true,
-		"employer":            true,
-		"government_official": true,
-		"bank_officer":        true,
-		"auditor":             true,
-		"admin":               true,
-	}
-	if !validRoles[role] {
+	if !knownRoles[role] {
 		return fmt.Errorf("invalid role: %s. Valid roles: worker, employer, government_official, bank_officer, auditor, admin", role)
 	}
 
@@ -875,7 +4984,7 @@ func (s *SmartContract) RegisterUser(ctx contractapi.TransactionContextInterface
 		UpdatedAt:   timestamp,
 	}
 
-	payload, err := json.Marshal(user)
+	payload, err := marshalState(user)
 	if err != nil {
 		return fmt.Errorf("marshal user: %w", err)
 	}
@@ -884,6 +4993,15 @@ func (s *SmartContract) RegisterUser(ctx contractapi.TransactionContextInterface
 		return fmt.Errorf("put state: %w", err)
 	}
 
+	counters, err := loadUserCounters(ctx)
+	if err != nil {
+		return err
+	}
+	counters.add(user.Role, user.Status, 1)
+	if err := putUserCounters(ctx, counters); err != nil {
+		return err
+	}
+
 	// Emit event
 	if err := ctx.GetStub().SetEvent("UserRegistered", []byte(userIDHash)); err != nil {
 		fmt.Printf("warning: failed to emit UserRegistered event: %v\n", err)
@@ -899,23 +5017,90 @@ func (s *SmartContract) GetUserProfile(ctx contractapi.TransactionContextInterfa
 		return nil, fmt.Errorf("userIDHash is required")
 	}
 
-	// IAM Check with self-access validation
-	if IAMEnabled {
-		identity, err := CheckAccess(ctx, "GetUserProfile")
-		if err != nil {
-			s.LogAccessDenied(ctx, "GetUserProfile", userIDHash, "user", err.Error())
-			return nil, fmt.Errorf("access denied: %w", err)
-		}
+	// IAM Check with self-access validation
+	var identity *ClientIdentity
+	if IAMEnabled {
+		var err error
+		identity, err = CheckAccess(ctx, "GetUserProfile")
+		if err != nil {
+			s.LogAccessDenied(ctx, "GetUserProfile", userIDHash, "user", err.Error())
+			return nil, fmt.Errorf("access denied: %w", err)
+		}
+
+		if err := CheckSelfAccess(ctx, identity, "GetUserProfile", userIDHash); err != nil {
+			s.LogAccessDenied(ctx, "GetUserProfile", userIDHash, "user", err.Error())
+			return nil, fmt.Errorf("access denied: %w", err)
+		}
+		s.LogDataRead(ctx, "GetUserProfile", userIDHash, "user")
+	}
+
+	key := fmt.Sprintf("USER_%s", userIDHash)
+	payload, err := ctx.GetStub().GetState(key)
+	if err != nil {
+		return nil, fmt.Errorf("get state: %w", err)
+	}
+	if payload == nil {
+		return nil, fmt.Errorf("user %s not found", userIDHash)
+	}
+
+	user := new(User)
+	if err := json.Unmarshal(payload, user); err != nil {
+		return nil, fmt.Errorf("unmarshal user: %w", err)
+	}
+
+	// Users viewing their own profile are also checked for certificate/registry role drift
+	if identity != nil && identity.Attributes["idHash"] == userIDHash {
+		s.checkRoleDrift(ctx, identity, user, "GetUserProfile")
+	}
+
+	return user, nil
+}
+
+// checkRoleDrift compares the caller's certificate role with their stored User role and
+// records an audit entry and a "RoleDriftDetected" event when they differ.
+func (s *SmartContract) checkRoleDrift(ctx contractapi.TransactionContextInterface, identity *ClientIdentity, user *User, function string) *RoleDrift {
+	drift := &RoleDrift{
+		UserIDHash:   user.UserIDHash,
+		CertRole:     identity.Role,
+		StoredRole:   user.Role,
+		StoredStatus: user.Status,
+		Drifted:      identity.Role != user.Role,
+		CheckedAt:    GetTxTimestampRFC3339(ctx),
+	}
+	if !drift.Drifted {
+		return drift
+	}
+
+	details := fmt.Sprintf("certificate role %q differs from stored role %q", drift.CertRole, drift.StoredRole)
+	s.LogAccess(ctx, EventRoleDrift, function, user.UserIDHash, "user", "drift", details)
+	if payload, err := marshalState(drift); err == nil {
+		if err := ctx.GetStub().SetEvent("RoleDriftDetected", payload); err != nil {
+			fmt.Printf("warning: failed to emit event: %v\n", err)
+		}
+	}
+	fmt.Printf("[IAM] Role drift for %s: %s\n", user.UserIDHash, details)
+
+	return drift
+}
 
-		if err := CheckSelfAccess(identity, "GetUserProfile", userIDHash); err != nil {
-			s.LogAccessDenied(ctx, "GetUserProfile", userIDHash, "user", err.Error())
-			return nil, fmt.Errorf("access denied: %w", err)
-		}
-		s.LogDataRead(ctx, "GetUserProfile", userIDHash, "user")
+// DetectRoleDrift compares the caller's certificate role with the Role stored in their
+// User record (located by the certificate's idHash attribute) and returns the result so
+// operators can reconcile stale records or wrongly issued certificates. Drift is also
+// recorded in the audit log.
+// SECURITY: All authenticated users can check their own record.
+func (s *SmartContract) DetectRoleDrift(ctx contractapi.TransactionContextInterface) (*RoleDrift, error) {
+	identity, err := CheckAccess(ctx, "DetectRoleDrift")
+	if err != nil {
+		s.LogAccessDenied(ctx, "DetectRoleDrift", "self", "user", err.Error())
+		return nil, fmt.Errorf("access denied: %w", err)
 	}
 
-	key := fmt.Sprintf("USER_%s", userIDHash)
-	payload, err := ctx.GetStub().GetState(key)
+	userIDHash := identity.Attributes["idHash"]
+	if userIDHash == "" {
+		return nil, fmt.Errorf("certificate has no idHash attribute")
+	}
+
+	payload, err := ctx.GetStub().GetState(fmt.Sprintf("USER_%s", userIDHash))
 	if err != nil {
 		return nil, fmt.Errorf("get state: %w", err)
 	}
@@ -923,12 +5108,12 @@ func (s *SmartContract) GetUserProfile(ctx contractapi.TransactionContextInterfa
 		return nil, fmt.Errorf("user %s not found", userIDHash)
 	}
 
-	user := new(User)
-	if err := json.Unmarshal(payload, user); err != nil {
+	var user User
+	if err := json.Unmarshal(payload, &user); err != nil {
 		return nil, fmt.Errorf("unmarshal user: %w", err)
 	}
 
-	return user, nil
+	return s.checkRoleDrift(ctx, identity, &user, "DetectRoleDrift"), nil
 }
 
 // UpdateUserStatus updates a user's status (requires government_official or admin role).
@@ -966,16 +5151,203 @@ func (s *SmartContract) UpdateUserStatus(ctx contractapi.TransactionContextInter
 		return err
 	}
 
+	// Counters are loaded before the update so a lazy backfill sees the old status
+	counters, err := loadUserCounters(ctx)
+	if err != nil {
+		return err
+	}
+	counters.add(user.Role, user.Status, -1)
+	counters.add(user.Role, status, 1)
+
 	user.Status = status
 	user.UpdatedAt = time.Now().UTC().Format(time.RFC3339)
 
-	payload, err := json.Marshal(user)
+	payload, err := marshalState(user)
 	if err != nil {
 		return fmt.Errorf("marshal user: %w", err)
 	}
 
 	key := fmt.Sprintf("USER_%s", userIDHash)
-	return ctx.GetStub().PutState(key, payload)
+	if err := ctx.GetStub().PutState(key, payload); err != nil {
+		return fmt.Errorf("put state: %w", err)
+	}
+
+	return putUserCounters(ctx, counters)
+}
+
+// UserCountersKey stores the running user population counters.
+const UserCountersKey = "COUNTER_USERS"
+
+// UserCounters holds user population metrics maintained by RegisterUser and UpdateUserStatus.
+type UserCounters struct {
+	DocType      string         `json:"docType"`
+	Total        int            `json:"total"`
+	Active       int            `json:"active"`
+	ByRole       map[string]int `json:"byRole"`
+	ActiveByRole map[string]int `json:"activeByRole"`
+}
+
+// add adjusts the counters for one user with the given role and status by delta
+func (c *UserCounters) add(role string, status string, delta int) {
+	c.Total += delta
+	c.ByRole[role] += delta
+	if status == "active" {
+		c.Active += delta
+		c.ActiveByRole[role] += delta
+	}
+}
+
+// loadUserCounters reads the user counters. If they have never been written, they are
+// rebuilt once from a scan of USER_ records so users registered earlier are counted.
+func loadUserCounters(ctx contractapi.TransactionContextInterface) (*UserCounters, error) {
+	counters := &UserCounters{DocType: "counter", ByRole: make(map[string]int), ActiveByRole: make(map[string]int)}
+
+	payload, err := ctx.GetStub().GetState(UserCountersKey)
+	if err != nil {
+		return nil, fmt.Errorf("get state: %w", err)
+	}
+	if payload != nil {
+		if err := json.Unmarshal(payload, counters); err != nil {
+			return nil, fmt.Errorf("unmarshal user counters: %w", err)
+		}
+		if counters.ByRole == nil {
+			counters.ByRole = make(map[string]int)
+		}
+		if counters.ActiveByRole == nil {
+			counters.ActiveByRole = make(map[string]int)
+		}
+		return counters, nil
+	}
+
+	iterator, err := ctx.GetStub().GetStateByRange("USER_", "USER_~")
+	if err != nil {
+		return nil, fmt.Errorf("get state range: %w", err)
+	}
+	defer iterator.Close()
+
+	for iterator.HasNext() {
+		queryResponse, err := iterator.Next()
+		if err != nil {
+			return nil, fmt.Errorf("iterate: %w", err)
+		}
+
+		var user User
+		if err := json.Unmarshal(queryResponse.Value, &user); err != nil || user.DocType != "user" {
+			continue
+		}
+		counters.add(user.Role, user.Status, 1)
+	}
+
+	return counters, nil
+}
+
+// putUserCounters writes the user counters.
+// NOTE: Every registration and status change writes this single key, so concurrent
+// user updates in the same block will MVCC-conflict and must be retried by the client.
+func putUserCounters(ctx contractapi.TransactionContextInterface, counters *UserCounters) error {
+	payload, err := marshalState(counters)
+	if err != nil {
+		return fmt.Errorf("marshal user counters: %w", err)
+	}
+	if err := ctx.GetStub().PutState(UserCountersKey, payload); err != nil {
+		return fmt.Errorf("put user counters: %w", err)
+	}
+	return nil
+}
+
+// GetUserCount returns the number of registered users.
+// SECURITY: Only government officials and admins.
+func (s *SmartContract) GetUserCount(ctx contractapi.TransactionContextInterface) (int, error) {
+	counters, err := s.readUserCounters(ctx, "GetUserCount")
+	if err != nil {
+		return 0, err
+	}
+	return counters.Total, nil
+}
+
+// GetActiveUserCount returns the number of users with status "active".
+// SECURITY: Only government officials and admins.
+func (s *SmartContract) GetActiveUserCount(ctx contractapi.TransactionContextInterface) (int, error) {
+	counters, err := s.readUserCounters(ctx, "GetActiveUserCount")
+	if err != nil {
+		return 0, err
+	}
+	return counters.Active, nil
+}
+
+// GetUserCountsByRole returns total and active user counts broken down by role.
+// SECURITY: Only government officials and admins.
+func (s *SmartContract) GetUserCountsByRole(ctx contractapi.TransactionContextInterface) (*UserCounters, error) {
+	return s.readUserCounters(ctx, "GetUserCountsByRole")
+}
+
+// readUserCounters implements the user count queries.
+func (s *SmartContract) readUserCounters(ctx contractapi.TransactionContextInterface, function string) (*UserCounters, error) {
+	// IAM Check
+	if IAMEnabled {
+		_, err := CheckAccess(ctx, function)
+		if err != nil {
+			s.LogAccessDenied(ctx, function, "users", "user", err.Error())
+			return nil, fmt.Errorf("access denied: %w", err)
+		}
+		s.LogDataRead(ctx, function, "users", "user")
+	}
+
+	return loadUserCounters(ctx)
+}
+
+// GetUserRegistrationTrend counts user registrations per "day" or "week" bucket between
+// startDate and endDate (inclusive, YYYY-MM-DD, UTC), by the CreatedAt of each User record,
+// to chart program uptake. TotalEvents is the number of registrations in the bucket and
+// EventsByType breaks it down by role. Every bucket is returned, including empty ones.
+// Users whose records were removed are not counted.
+// NOTE: This scans all USER_ keys.
+// SECURITY: Only government officials and admins.
+func (s *SmartContract) GetUserRegistrationTrend(ctx contractapi.TransactionContextInterface, startDate string, endDate string, granularity string) ([]TimeBucket, error) {
+	// IAM Check
+	if IAMEnabled {
+		_, err := CheckAccess(ctx, "GetUserRegistrationTrend")
+		if err != nil {
+			s.LogAccessDenied(ctx, "GetUserRegistrationTrend", "users", "user", err.Error())
+			return nil, fmt.Errorf("access denied: %w", err)
+		}
+		s.LogDataRead(ctx, "GetUserRegistrationTrend", "users", "user")
+	}
+
+	series, err := newTimeSeries(startDate, endDate, granularity)
+	if err != nil {
+		return nil, err
+	}
+
+	iterator, err := ctx.GetStub().GetStateByRange("USER_", "USER_~")
+	if err != nil {
+		return nil, fmt.Errorf("get state range: %w", err)
+	}
+	defer iterator.Close()
+
+	for iterator.HasNext() {
+		queryResponse, err := iterator.Next()
+		if err != nil {
+			return nil, fmt.Errorf("iterate: %w", err)
+		}
+
+		var user User
+		if err := json.Unmarshal(queryResponse.Value, &user); err != nil || user.DocType != "user" {
+			continue
+		}
+		createdAt, err := time.Parse(time.RFC3339, user.CreatedAt)
+		if err != nil {
+			continue
+		}
+		bucket := series.bucket(createdAt)
+		if bucket == nil {
+			continue
+		}
+		bucket.TotalEvents++
+		bucket.EventsByType[user.Role]++
+	}
+
+	return series.buckets, nil
 }
 
 // VerifyUserRole checks if a user has the required role.
@@ -1026,6 +5398,140 @@ func (s *SmartContract) UserExists(ctx contractapi.TransactionContextInterface,
 	return payload != nil, nil
 }
 
+// AddWorkerAlias records that aliasHash belongs to the same worker as canonicalHash,
+// so income and wage history can be retrieved under either hash.
+// SECURITY: Only admins from Org1MSP can remap worker identities.
+func (s *SmartContract) AddWorkerAlias(ctx contractapi.TransactionContextInterface, canonicalHash string, aliasHash string) error {
+	if canonicalHash == "" || aliasHash == "" {
+		return fmt.Errorf("canonicalHash and aliasHash are required")
+	}
+	if canonicalHash == aliasHash {
+		return fmt.Errorf("alias must differ from canonical hash")
+	}
+
+	// IAM Check
+	addedBy := "system"
+	if IAMEnabled {
+		identity, err := CheckAccess(ctx, "AddWorkerAlias")
+		if err != nil {
+			s.LogAccessDenied(ctx, "AddWorkerAlias", aliasHash, "alias", err.Error())
+			return fmt.Errorf("access denied: %w", err)
+		}
+		s.LogAccess(ctx, EventUserUpdated, "AddWorkerAlias", aliasHash, "alias", "success", fmt.Sprintf("canonical: %s", canonicalHash))
+		fmt.Printf("[IAM] AddWorkerAlias by %s: %s -> %s\n", identity.ID, aliasHash, canonicalHash)
+		addedBy = identity.ID
+	}
+
+	// Keep the mapping one level deep so resolution never has to follow chains
+	existing, err := ctx.GetStub().GetState(aliasKey(aliasHash))
+	if err != nil {
+		return fmt.Errorf("get state: %w", err)
+	}
+	if existing != nil {
+		return fmt.Errorf("hash %s is already an alias", aliasHash)
+	}
+	canonicalAlias, err := ctx.GetStub().GetState(aliasKey(canonicalHash))
+	if err != nil {
+		return fmt.Errorf("get state: %w", err)
+	}
+	if canonicalAlias != nil {
+		return fmt.Errorf("canonical hash %s is itself an alias", canonicalHash)
+	}
+	aliasesOfAlias, err := getWorkerAliases(ctx, aliasHash)
+	if err != nil {
+		return err
+	}
+	if len(aliasesOfAlias) > 0 {
+		return fmt.Errorf("hash %s is canonical for other aliases and cannot become an alias", aliasHash)
+	}
+
+	alias := WorkerAlias{
+		DocType:       "alias",
+		AliasHash:     aliasHash,
+		CanonicalHash: canonicalHash,
+		AddedBy:       addedBy,
+		AddedAt:       GetTxTimestampRFC3339(ctx),
+	}
+
+	payload, err := marshalState(alias)
+	if err != nil {
+		return fmt.Errorf("marshal alias: %w", err)
+	}
+	if err := ctx.GetStub().PutState(aliasKey(aliasHash), payload); err != nil {
+		return fmt.Errorf("put state: %w", err)
+	}
+
+	// Reverse index so a canonical hash can enumerate its aliases
+	indexKey, err := ctx.GetStub().CreateCompositeKey("alias~canonical", []string{canonicalHash, aliasHash})
+	if err != nil {
+		return fmt.Errorf("create composite key: %w", err)
+	}
+	if err := ctx.GetStub().PutState(indexKey, []byte{0x00}); err != nil {
+		return fmt.Errorf("put alias index: %w", err)
+	}
+
+	// Emit event
+	if err := ctx.GetStub().SetEvent("WorkerAliasAdded", []byte(aliasHash)); err != nil {
+		fmt.Printf("warning: failed to emit event: %v\n", err)
+	}
+
+	return nil
+}
+
+// aliasKey returns the ledger key for a worker alias mapping
+func aliasKey(aliasHash string) string {
+	return fmt.Sprintf("ALIAS_%s", aliasHash)
+}
+
+// getWorkerAliases lists the alias hashes registered for a canonical worker hash.
+func getWorkerAliases(ctx contractapi.TransactionContextInterface, canonicalHash string) ([]string, error) {
+	iterator, err := ctx.GetStub().GetStateByPartialCompositeKey("alias~canonical", []string{canonicalHash})
+	if err != nil {
+		return nil, fmt.Errorf("get alias index: %w", err)
+	}
+	defer iterator.Close()
+
+	var aliases []string
+	for iterator.HasNext() {
+		queryResponse, err := iterator.Next()
+		if err != nil {
+			return nil, fmt.Errorf("iterate: %w", err)
+		}
+		_, parts, err := ctx.GetStub().SplitCompositeKey(queryResponse.Key)
+		if err != nil || len(parts) != 2 {
+			continue
+		}
+		aliases = append(aliases, parts[1])
+	}
+
+	return aliases, nil
+}
+
+// resolveWorkerHashes returns the canonical hash for any of a worker's hashes followed
+// by all of its aliases. Unknown hashes resolve to themselves.
+func resolveWorkerHashes(ctx contractapi.TransactionContextInterface, workerIDHash string) ([]string, error) {
+	canonical := workerIDHash
+
+	payload, err := ctx.GetStub().GetState(aliasKey(workerIDHash))
+	if err != nil {
+		return nil, fmt.Errorf("get state: %w", err)
+	}
+	if payload != nil {
+		var alias WorkerAlias
+		if err := json.Unmarshal(payload, &alias); err != nil {
+			return nil, fmt.Errorf("unmarshal alias: %w", err)
+		}
+		canonical = alias.CanonicalHash
+	}
+
+	aliases, err := getWorkerAliases(ctx, canonical)
+	if err != nil {
+		return nil, err
+	}
+
+	return append([]string{canonical}, aliases...), nil
+}
+
 // ============================================================================
 // POVERTY THRESHOLD FUNCTIONS
 // ============================================================================
@@ -1068,7 +5574,7 @@ func (s *SmartContract) SetPovertyThreshold(ctx contractapi.TransactionContextIn
 		UpdatedAt: GetTxTimestampRFC3339(ctx),
 	}
 
-	payload, err := json.Marshal(threshold)
+	payload, err := marshalState(threshold)
 	if err != nil {
 		return fmt.Errorf("marshal threshold: %w", err)
 	}
@@ -1083,57 +5589,187 @@ func (s *SmartContract) SetPovertyThreshold(ctx contractapi.TransactionContextIn
 		fmt.Printf("warning: failed to emit event: %v\n", err)
 	}
 
-	return nil
+	return nil
+}
+
+// GetPovertyThreshold retrieves the poverty threshold for a state and category.
+// SECURITY: All authenticated users can read thresholds.
+func (s *SmartContract) GetPovertyThreshold(ctx contractapi.TransactionContextInterface, state string, category string) (*PovertyThreshold, error) {
+	// IAM Check
+	if IAMEnabled {
+		_, err := CheckAccess(ctx, "GetPovertyThreshold")
+		if err != nil {
+			s.LogAccessDenied(ctx, "GetPovertyThreshold", fmt.Sprintf("%s_%s", state, category), "threshold", err.Error())
+			return nil, fmt.Errorf("access denied: %w", err)
+		}
+		s.LogDataRead(ctx, "GetPovertyThreshold", fmt.Sprintf("%s_%s", state, category), "threshold")
+	}
+
+	if state == "" {
+		state = "DEFAULT"
+	}
+	if category != "BPL" && category != "APL" {
+		return nil, fmt.Errorf("category must be 'BPL' or 'APL'")
+	}
+
+	return readPovertyThreshold(ctx, state, category)
+}
+
+// readPovertyThreshold reads a state's threshold, falling back to the DEFAULT (national)
+// one when the state has none. It performs no access checks.
+func readPovertyThreshold(ctx contractapi.TransactionContextInterface, state string, category string) (*PovertyThreshold, error) {
+	key := fmt.Sprintf("THRESHOLD_%s_%s", state, category)
+	payload, err := ctx.GetStub().GetState(key)
+	if err != nil {
+		return nil, fmt.Errorf("get state: %w", err)
+	}
+
+	// If state-specific threshold not found, try DEFAULT
+	if payload == nil && state != "DEFAULT" {
+		key = fmt.Sprintf("THRESHOLD_DEFAULT_%s", category)
+		payload, err = ctx.GetStub().GetState(key)
+		if err != nil {
+			return nil, fmt.Errorf("get default state: %w", err)
+		}
+	}
+
+	if payload == nil {
+		return nil, fmt.Errorf("poverty threshold not found for %s/%s", state, category)
+	}
+
+	threshold := new(PovertyThreshold)
+	if err := json.Unmarshal(payload, threshold); err != nil {
+		return nil, fmt.Errorf("unmarshal threshold: %w", err)
+	}
+
+	return threshold, nil
+}
+
+// MaxStatesPerComparison caps how many states CompareThresholds accepts in one call
+const MaxStatesPerComparison = 50
+
+// CompareThresholds returns the current threshold of one category for each requested state,
+// keyed by the state as given, for building a comparison table in one call. A state without
+// its own threshold gets the DEFAULT (national) one, recognisable by its State field.
+// SECURITY: Only government officials, auditors, and admins.
+func (s *SmartContract) CompareThresholds(ctx contractapi.TransactionContextInterface, states []string, category string) (map[string]*PovertyThreshold, error) {
+	// IAM Check
+	if IAMEnabled {
+		_, err := CheckAccess(ctx, "CompareThresholds")
+		if err != nil {
+			s.LogAccessDenied(ctx, "CompareThresholds", category, "threshold", err.Error())
+			return nil, fmt.Errorf("access denied: %w", err)
+		}
+		s.LogDataRead(ctx, "CompareThresholds", category, "threshold")
+	}
+
+	if category != "BPL" && category != "APL" {
+		return nil, fmt.Errorf("category must be 'BPL' or 'APL'")
+	}
+	if len(states) == 0 {
+		return nil, fmt.Errorf("at least one state is required")
+	}
+	if len(states) > MaxStatesPerComparison {
+		return nil, fmt.Errorf("too many states: %d (max %d)", len(states), MaxStatesPerComparison)
+	}
+
+	result := make(map[string]*PovertyThreshold, len(states))
+	for _, state := range states {
+		if state == "" {
+			return nil, fmt.Errorf("state must not be empty")
+		}
+		threshold, err := readPovertyThreshold(ctx, state, category)
+		if err != nil {
+			return nil, err
+		}
+		result[state] = threshold
+	}
+
+	return result, nil
 }
 
-// GetPovertyThreshold retrieves the poverty threshold for a state and category.
-// SECURITY: All authenticated users can read thresholds.
-func (s *SmartContract) GetPovertyThreshold(ctx contractapi.TransactionContextInterface, state string, category string) (*PovertyThreshold, error) {
+// GetThresholdHistory returns every recorded version of a state's BPL and APL thresholds,
+// newest first, showing who set each value and when.
+// SECURITY: Only government officials, auditors, and admins.
+func (s *SmartContract) GetThresholdHistory(ctx contractapi.TransactionContextInterface, state string) ([]*ThresholdHistoryEntry, error) {
+	if state == "" {
+		state = "DEFAULT"
+	}
+
 	// IAM Check
 	if IAMEnabled {
-		_, err := CheckAccess(ctx, "GetPovertyThreshold")
+		_, err := CheckAccess(ctx, "GetThresholdHistory")
 		if err != nil {
-			s.LogAccessDenied(ctx, "GetPovertyThreshold", fmt.Sprintf("%s_%s", state, category), "threshold", err.Error())
+			s.LogAccessDenied(ctx, "GetThresholdHistory", state, "threshold", err.Error())
 			return nil, fmt.Errorf("access denied: %w", err)
 		}
-		s.LogDataRead(ctx, "GetPovertyThreshold", fmt.Sprintf("%s_%s", state, category), "threshold")
+		s.LogDataRead(ctx, "GetThresholdHistory", state, "threshold")
 	}
 
-	if state == "" {
-		state = "DEFAULT"
-	}
-	if category != "BPL" && category != "APL" {
-		return nil, fmt.Errorf("category must be 'BPL' or 'APL'")
-	}
-
-	key := fmt.Sprintf("THRESHOLD_%s_%s", state, category)
-	payload, err := ctx.GetStub().GetState(key)
-	if err != nil {
-		return nil, fmt.Errorf("get state: %w", err)
+	type timedEntry struct {
+		entry *ThresholdHistoryEntry
+		at    time.Time
 	}
+	var entries []timedEntry
 
-	// If state-specific threshold not found, try DEFAULT
-	if payload == nil && state != "DEFAULT" {
-		key = fmt.Sprintf("THRESHOLD_DEFAULT_%s", category)
-		payload, err = ctx.GetStub().GetState(key)
+	for _, category := range []string{"BPL", "APL"} {
+		key := fmt.Sprintf("THRESHOLD_%s_%s", state, category)
+		historyIter, err := ctx.GetStub().GetHistoryForKey(key)
 		if err != nil {
-			return nil, fmt.Errorf("get default state: %w", err)
+			return nil, fmt.Errorf("get history: %w", err)
 		}
-	}
 
-	if payload == nil {
-		return nil, fmt.Errorf("poverty threshold not found for %s/%s", state, category)
+		for historyIter.HasNext() {
+			modification, err := historyIter.Next()
+			if err != nil {
+				historyIter.Close()
+				return nil, fmt.Errorf("iterate history: %w", err)
+			}
+
+			at := time.Unix(modification.GetTimestamp().GetSeconds(), int64(modification.GetTimestamp().GetNanos())).UTC()
+			entry := &ThresholdHistoryEntry{
+				TxID:      modification.GetTxId(),
+				Timestamp: at.Format(time.RFC3339),
+				State:     state,
+				Category:  category,
+				IsDelete:  modification.GetIsDelete(),
+			}
+			if !modification.GetIsDelete() {
+				var threshold PovertyThreshold
+				if err := json.Unmarshal(modification.GetValue(), &threshold); err != nil {
+					historyIter.Close()
+					return nil, fmt.Errorf("unmarshal history record: %w", err)
+				}
+				entry.Amount = threshold.Amount
+				entry.SetBy = threshold.SetBy
+			}
+			entries = append(entries, timedEntry{entry: entry, at: at})
+		}
+		historyIter.Close()
 	}
 
-	threshold := new(PovertyThreshold)
-	if err := json.Unmarshal(payload, threshold); err != nil {
-		return nil, fmt.Errorf("unmarshal threshold: %w", err)
+	// Newest first; ties broken by category then txID for stable output
+	sort.Slice(entries, func(i, j int) bool {
+		if !entries[i].at.Equal(entries[j].at) {
+			return entries[i].at.After(entries[j].at)
+		}
+		if entries[i].entry.Category != entries[j].entry.Category {
+			return entries[i].entry.Category < entries[j].entry.Category
+		}
+		return entries[i].entry.TxID < entries[j].entry.TxID
+	})
+
+	history := make([]*ThresholdHistoryEntry, 0, len(entries))
+	for _, e := range entries {
+		history = append(history, e.entry)
 	}
 
-	return threshold, nil
+	return history, nil
 }
 
-// CheckPovertyStatus determines if a worker is BPL or APL based on income.
+// CheckPovertyStatus determines if a worker is BPL or APL based on income. Income is the
+// worker's recorded wages in the date range (see wageIncome); UPI payments are not counted,
+// and wages in more than one currency are an error since thresholds carry no currency.
 // SECURITY: Workers can only check their own status; privileged roles can check any.
 func (s *SmartContract) CheckPovertyStatus(ctx contractapi.TransactionContextInterface, workerIDHash string, state string, startDate string, endDate string) (*PovertyStatusResult, error) {
 	if workerIDHash == "" {
@@ -1148,7 +5784,7 @@ func (s *SmartContract) CheckPovertyStatus(ctx contractapi.TransactionContextInt
 			return nil, fmt.Errorf("access denied: %w", err)
 		}
 
-		if err := CheckSelfAccess(identity, "CheckPovertyStatus", workerIDHash); err != nil {
+		if err := CheckSelfAccess(ctx, identity, "CheckPovertyStatus", workerIDHash); err != nil {
 			s.LogAccessDenied(ctx, "CheckPovertyStatus", workerIDHash, "poverty_status", err.Error())
 			return nil, fmt.Errorf("access denied: %w", err)
 		}
@@ -1156,7 +5792,7 @@ func (s *SmartContract) CheckPovertyStatus(ctx contractapi.TransactionContextInt
 	}
 
 	// Calculate total income
-	totalIncome, err := s.CalculateTotalIncome(ctx, workerIDHash, startDate, endDate)
+	totalIncome, err := wageIncome(ctx, workerIDHash, startDate, endDate)
 	if err != nil {
 		return nil, fmt.Errorf("calculate income: %w", err)
 	}
@@ -1187,7 +5823,7 @@ func (s *SmartContract) CheckPovertyStatus(ctx contractapi.TransactionContextInt
 	}
 
 	// Emit event for poverty status check
-	eventData, _ := json.Marshal(map[string]interface{}{
+	eventData, _ := marshalState(map[string]interface{}{
 		"workerIDHash": workerIDHash,
 		"status":       status,
 		"income":       totalIncome,
@@ -1199,11 +5835,123 @@ func (s *SmartContract) CheckPovertyStatus(ctx contractapi.TransactionContextInt
 	return result, nil
 }
 
+// ============================================================================
+// EXCHANGE RATE FUNCTIONS
+// ============================================================================
+
+// exchangeRateKey returns the state key of the rate from one currency to another
+func exchangeRateKey(from string, to string) string {
+	return fmt.Sprintf("FXRATE_%s_%s", from, to)
+}
+
+// readExchangeRate reads a stored exchange rate without access checks.
+// Only the direct pair is used; the inverse rate is not derived.
+func readExchangeRate(ctx contractapi.TransactionContextInterface, from string, to string) (*ExchangeRate, error) {
+	payload, err := ctx.GetStub().GetState(exchangeRateKey(from, to))
+	if err != nil {
+		return nil, fmt.Errorf("get state: %w", err)
+	}
+	if payload == nil {
+		return nil, fmt.Errorf("no exchange rate from %s to %s", from, to)
+	}
+
+	rate := new(ExchangeRate)
+	if err := json.Unmarshal(payload, rate); err != nil {
+		return nil, fmt.Errorf("unmarshal exchange rate: %w", err)
+	}
+	return rate, nil
+}
+
+// SetExchangeRate stores the rate from one currency to another (units of to per unit of from).
+// Rates are reference values for display only; recorded wage amounts are never converted.
+// SECURITY: Only government officials and admins with 'canUpdateThresholds' permission from Org1MSP.
+func (s *SmartContract) SetExchangeRate(ctx contractapi.TransactionContextInterface, from string, to string, rateStr string, setBy string) error {
+	if from == "" || to == "" {
+		return fmt.Errorf("from and to currencies are required")
+	}
+	if from == to {
+		return fmt.Errorf("from and to currencies must differ")
+	}
+
+	// IAM Check
+	if IAMEnabled {
+		identity, err := CheckAccess(ctx, "SetExchangeRate")
+		if err != nil {
+			s.LogAccessDenied(ctx, "SetExchangeRate", exchangeRateKey(from, to), "exchange_rate", err.Error())
+			return fmt.Errorf("access denied: %w", err)
+		}
+		s.LogAccessGranted(ctx, "SetExchangeRate", exchangeRateKey(from, to), "exchange_rate")
+		fmt.Printf("[IAM] SetExchangeRate by %s: %s->%s = %s\n", identity.ID, from, to, rateStr)
+	}
+
+	if err := ValidateCurrency(ctx, from); err != nil {
+		return err
+	}
+	if err := ValidateCurrency(ctx, to); err != nil {
+		return err
+	}
+
+	rate, err := strconv.ParseFloat(rateStr, 64)
+	if err != nil {
+		return fmt.Errorf("invalid rate: %w", err)
+	}
+	if rate <= 0 || math.IsInf(rate, 0) {
+		return fmt.Errorf("rate must be positive")
+	}
+
+	exchangeRate := ExchangeRate{
+		DocType:   "exchange_rate",
+		From:      from,
+		To:        to,
+		Rate:      rate,
+		SetBy:     setBy,
+		UpdatedAt: GetTxTimestampRFC3339(ctx),
+	}
+
+	payload, err := marshalState(exchangeRate)
+	if err != nil {
+		return fmt.Errorf("marshal exchange rate: %w", err)
+	}
+
+	if err := ctx.GetStub().PutState(exchangeRateKey(from, to), payload); err != nil {
+		return fmt.Errorf("put state: %w", err)
+	}
+
+	// Emit event
+	if err := ctx.GetStub().SetEvent("ExchangeRateUpdated", []byte(exchangeRateKey(from, to))); err != nil {
+		fmt.Printf("warning: failed to emit event: %v\n", err)
+	}
+
+	return nil
+}
+
+// GetExchangeRate retrieves the stored rate from one currency to another.
+// SECURITY: All authenticated users can read exchange rates.
+func (s *SmartContract) GetExchangeRate(ctx contractapi.TransactionContextInterface, from string, to string) (*ExchangeRate, error) {
+	// IAM Check
+	if IAMEnabled {
+		_, err := CheckAccess(ctx, "GetExchangeRate")
+		if err != nil {
+			s.LogAccessDenied(ctx, "GetExchangeRate", exchangeRateKey(from, to), "exchange_rate", err.Error())
+			return nil, fmt.Errorf("access denied: %w", err)
+		}
+		s.LogDataRead(ctx, "GetExchangeRate", exchangeRateKey(from, to), "exchange_rate")
+	}
+
+	return readExchangeRate(ctx, from, to)
+}
+
 // ============================================================================
 // ANOMALY DETECTION FUNCTIONS
 // ============================================================================
 
 // FlagAnomaly flags a wage record as suspicious (from AI model).
+// A wage can carry several open anomalies only if their reason categories differ (see
+// anomalyReasonCategory). Flagging the same category again while it is open is rejected,
+// except that an exact retry (same reason and flagger) is a no-op. The first anomaly on a
+// wage is stored under the wageID; additional ones get a generated anomalyId.
+// FlaggedBy is the caller's client ID, which QueryAnomaliesByFlagger is keyed by; the
+// flaggedBy argument is kept for compatibility but cannot attribute a flag to someone else.
 // SECURITY: Only auditors, government officials, and admins with 'canFlagAnomaly' permission.
 func (s *SmartContract) FlagAnomaly(ctx contractapi.TransactionContextInterface, wageID string, anomalyScoreStr string, reason string, flaggedBy string) error {
 	if wageID == "" {
@@ -1217,10 +5965,16 @@ func (s *SmartContract) FlagAnomaly(ctx contractapi.TransactionContextInterface,
 			s.LogAccessDenied(ctx, "FlagAnomaly", wageID, "anomaly", err.Error())
 			return fmt.Errorf("access denied: %w", err)
 		}
-		s.LogAccess(ctx, EventAnomalyFlagged, "FlagAnomaly", wageID, "anomaly", "success", fmt.Sprintf("score: %s, reason: %s", anomalyScoreStr, reason))
 		fmt.Printf("[IAM] FlagAnomaly by %s: %s (score: %s)\n", identity.ID, wageID, anomalyScoreStr)
 	}
 
+	// Attribute the flag to the certificate, not the caller-supplied flaggedBy
+	caller, err := GetClientIdentity(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get client identity: %w", err)
+	}
+	flaggedBy = caller.ID
+
 	anomalyScore, err := strconv.ParseFloat(anomalyScoreStr, 64)
 	if err != nil {
 		return fmt.Errorf("invalid anomaly score: %w", err)
@@ -1231,39 +5985,306 @@ func (s *SmartContract) FlagAnomaly(ctx contractapi.TransactionContextInterface,
 	if err != nil {
 		return err
 	}
-	if !exists {
-		return fmt.Errorf("wage record %s not found", wageID)
+	if !exists {
+		return fmt.Errorf("wage record %s not found", wageID)
+	}
+
+	// Keep the review queue free of duplicate open flags
+	category := anomalyReasonCategory(reason)
+	duplicate, err := findOpenAnomaly(ctx, wageID, category)
+	if err != nil {
+		return err
+	}
+	if duplicate != nil {
+		if duplicate.Reason == reason && duplicate.FlaggedBy == flaggedBy {
+			return nil
+		}
+		return fmt.Errorf("wage %s already has an open %q anomaly (%s)", wageID, category, anomalyKeyID(duplicate))
+	}
+
+	id, err := nextAnomalyID(ctx, wageID)
+	if err != nil {
+		return err
+	}
+
+	// Only audit flags that are actually recorded; an exact retry returned above
+	if IAMEnabled {
+		s.LogAccess(ctx, EventAnomalyFlagged, "FlagAnomaly", wageID, "anomaly", "success", fmt.Sprintf("score: %s, reason: %s", anomalyScoreStr, reason))
+	}
+
+	anomaly := Anomaly{
+		DocType:      "anomaly",
+		WageID:       wageID,
+		AnomalyScore: anomalyScore,
+		Reason:       reason,
+		FlaggedBy:    flaggedBy,
+		Status:       "pending",
+		Timestamp:    GetTxTimestampRFC3339(ctx),
+		AnomalyID:    id,
+		Category:     category,
+	}
+
+	if err := putAnomaly(ctx, &anomaly); err != nil {
+		return err
+	}
+
+	// Emit event for anomaly flagging
+	if err := ctx.GetStub().SetEvent("AnomalyFlagged", []byte(wageID)); err != nil {
+		fmt.Printf("warning: failed to emit event: %v\n", err)
+	}
+
+	return nil
+}
+
+// nextAnomalyID returns the ID for a new anomaly on a wage: the wageID slot when it is free
+// or its anomaly is closed, otherwise a newly minted ID.
+func nextAnomalyID(ctx contractapi.TransactionContextInterface, wageID string) (string, error) {
+	primary, err := ctx.GetStub().GetState(fmt.Sprintf("ANOMALY_%s", wageID))
+	if err != nil {
+		return "", fmt.Errorf("get state: %w", err)
+	}
+	if primary != nil {
+		var previous Anomaly
+		if err := json.Unmarshal(primary, &previous); err == nil && isOpenAnomalyStatus(previous.Status) {
+			return generateDeterministicID(ctx, wageID), nil
+		}
+	}
+	return wageID, nil
+}
+
+// anomalyKeyID returns the ANOMALY_ key suffix of an anomaly. Records written before
+// AnomalyID existed are keyed by their wageID.
+func anomalyKeyID(anomaly *Anomaly) string {
+	if anomaly.AnomalyID != "" {
+		return anomaly.AnomalyID
+	}
+	return anomaly.WageID
+}
+
+// anomalyReasonCategory normalizes a reason to the category used for duplicate detection:
+// the text before the first ':' (or the whole reason), trimmed and lower-cased. For example
+// "UPI amount mismatch: wage W1 declared ..." has category "upi amount mismatch".
+func anomalyReasonCategory(reason string) string {
+	category, _, _ := strings.Cut(reason, ":")
+	category = strings.ToLower(strings.TrimSpace(category))
+	if category == "" {
+		return "unspecified"
+	}
+	return category
+}
+
+// findOpenAnomaly returns an open anomaly on wageID with the given reason category, or nil.
+// It checks the anomaly keyed by the wageID (which covers records that predate the
+// anomaly~wage~status index) and the indexed open anomalies.
+func findOpenAnomaly(ctx contractapi.TransactionContextInterface, wageID string, category string) (*Anomaly, error) {
+	candidates := []string{wageID}
+	for _, status := range []string{"pending", "reviewed"} {
+		iterator, err := ctx.GetStub().GetStateByPartialCompositeKey("anomaly~wage~status", []string{wageID, status})
+		if err != nil {
+			return nil, fmt.Errorf("get anomaly status index: %w", err)
+		}
+		for iterator.HasNext() {
+			queryResponse, err := iterator.Next()
+			if err != nil {
+				iterator.Close()
+				return nil, fmt.Errorf("iterate: %w", err)
+			}
+			_, parts, err := ctx.GetStub().SplitCompositeKey(queryResponse.Key)
+			if err == nil && len(parts) == 3 && parts[2] != wageID {
+				candidates = append(candidates, parts[2])
+			}
+		}
+		iterator.Close()
+	}
+
+	for _, id := range candidates {
+		payload, err := ctx.GetStub().GetState(fmt.Sprintf("ANOMALY_%s", id))
+		if err != nil {
+			return nil, fmt.Errorf("get state: %w", err)
+		}
+		if payload == nil {
+			continue
+		}
+		var anomaly Anomaly
+		if err := json.Unmarshal(payload, &anomaly); err != nil {
+			continue
+		}
+		if anomaly.WageID != wageID || !isOpenAnomalyStatus(anomaly.Status) {
+			continue // Stale index entry or closed anomaly
+		}
+		if anomalyReasonCategory(anomaly.Reason) == category {
+			return &anomaly, nil
+		}
+	}
+
+	return nil, nil
+}
+
+// putAnomaly writes an anomaly under ANOMALY_<anomalyId> and maintains the
+// anomaly~flagger composite index used by QueryAnomaliesByFlagger and the
+// anomaly~wage~status index used to detect duplicate open flags.
+func putAnomaly(ctx contractapi.TransactionContextInterface, anomaly *Anomaly) error {
+	id := anomalyKeyID(anomaly)
+	anomaly.AnomalyID = id
+	if anomaly.Category == "" {
+		anomaly.Category = anomalyReasonCategory(anomaly.Reason)
+	}
+	key := fmt.Sprintf("ANOMALY_%s", id)
+
+	// Drop the index entries of an anomaly being replaced or changing status
+	existing, err := ctx.GetStub().GetState(key)
+	if err != nil {
+		return fmt.Errorf("get state: %w", err)
+	}
+	if existing != nil {
+		var previous Anomaly
+		if err := json.Unmarshal(existing, &previous); err == nil {
+			if previous.FlaggedBy != anomaly.FlaggedBy {
+				oldIndexKey, err := ctx.GetStub().CreateCompositeKey("anomaly~flagger", []string{previous.FlaggedBy, id})
+				if err != nil {
+					return fmt.Errorf("create composite key: %w", err)
+				}
+				if err := ctx.GetStub().DelState(oldIndexKey); err != nil {
+					return fmt.Errorf("delete flagger index: %w", err)
+				}
+			}
+			if previous.Status != anomaly.Status || previous.WageID != anomaly.WageID {
+				oldStatusKey, err := ctx.GetStub().CreateCompositeKey("anomaly~wage~status", []string{previous.WageID, previous.Status, id})
+				if err != nil {
+					return fmt.Errorf("create composite key: %w", err)
+				}
+				if err := ctx.GetStub().DelState(oldStatusKey); err != nil {
+					return fmt.Errorf("delete status index: %w", err)
+				}
+			}
+		}
+	}
+
+	payload, err := marshalState(anomaly)
+	if err != nil {
+		return fmt.Errorf("marshal anomaly: %w", err)
+	}
+	if err := ctx.GetStub().PutState(key, payload); err != nil {
+		return fmt.Errorf("put state: %w", err)
+	}
+
+	indexKey, err := ctx.GetStub().CreateCompositeKey("anomaly~flagger", []string{anomaly.FlaggedBy, id})
+	if err != nil {
+		return fmt.Errorf("create composite key: %w", err)
+	}
+	if err := ctx.GetStub().PutState(indexKey, []byte{0x00}); err != nil {
+		return fmt.Errorf("put flagger index: %w", err)
+	}
+
+	statusKey, err := ctx.GetStub().CreateCompositeKey("anomaly~wage~status", []string{anomaly.WageID, anomaly.Status, id})
+	if err != nil {
+		return fmt.Errorf("create composite key: %w", err)
+	}
+	if err := ctx.GetStub().PutState(statusKey, []byte{0x00}); err != nil {
+		return fmt.Errorf("put status index: %w", err)
+	}
+
+	return nil
+}
+
+// QueryAnomaliesByFlagger retrieves the anomalies raised by a given flagger, for oversight
+// of auditors themselves (e.g. detecting over-flagging). Uses the anomaly~flagger index;
+// anomalies flagged before the index existed are only returned once re-flagged.
+// Results are ordered oldest first (by timestamp, then ID).
+// SECURITY: Only government officials and admins.
+func (s *SmartContract) QueryAnomaliesByFlagger(ctx contractapi.TransactionContextInterface, flaggerID string) ([]*Anomaly, error) {
+	if flaggerID == "" {
+		return nil, fmt.Errorf("flaggerID is required")
+	}
+
+	// IAM Check
+	if IAMEnabled {
+		_, err := CheckAccess(ctx, "QueryAnomaliesByFlagger")
+		if err != nil {
+			s.LogAccessDenied(ctx, "QueryAnomaliesByFlagger", flaggerID, "anomaly", err.Error())
+			return nil, fmt.Errorf("access denied: %w", err)
+		}
+		s.LogDataRead(ctx, "QueryAnomaliesByFlagger", flaggerID, "anomaly")
+	}
+
+	iterator, err := ctx.GetStub().GetStateByPartialCompositeKey("anomaly~flagger", []string{flaggerID})
+	if err != nil {
+		return nil, fmt.Errorf("get flagger index: %w", err)
+	}
+	defer iterator.Close()
+
+	anomalies := []*Anomaly{}
+	for iterator.HasNext() {
+		queryResponse, err := iterator.Next()
+		if err != nil {
+			return nil, fmt.Errorf("iterate: %w", err)
+		}
+
+		_, parts, err := ctx.GetStub().SplitCompositeKey(queryResponse.Key)
+		if err != nil || len(parts) != 2 {
+			continue
+		}
+
+		payload, err := ctx.GetStub().GetState(fmt.Sprintf("ANOMALY_%s", parts[1]))
+		if err != nil {
+			return nil, fmt.Errorf("get state: %w", err)
+		}
+		if payload == nil {
+			continue
+		}
+
+		var anomaly Anomaly
+		if err := json.Unmarshal(payload, &anomaly); err != nil {
+			continue
+		}
+		if anomaly.FlaggedBy != flaggerID {
+			continue // Stale index entry
+		}
+		anomalies = append(anomalies, &anomaly)
+	}
+
+	sortAnomaliesChronologically(anomalies)
+
+	return anomalies, nil
+}
+
+// GetAnomaly reads one anomaly by its ID (the ANOMALY_ key suffix, as carried in event
+// payloads and the anomalyId field), e.g. for deep links from the review UI.
+// SECURITY: Only auditors, government officials, and admins.
+func (s *SmartContract) GetAnomaly(ctx contractapi.TransactionContextInterface, anomalyID string) (*Anomaly, error) {
+	if anomalyID == "" {
+		return nil, fmt.Errorf("anomalyID is required")
 	}
 
-	anomaly := Anomaly{
-		DocType:      "anomaly",
-		WageID:       wageID,
-		AnomalyScore: anomalyScore,
-		Reason:       reason,
-		FlaggedBy:    flaggedBy,
-		Status:       "pending",
-		Timestamp:    time.Now().UTC().Format(time.RFC3339),
+	// IAM Check
+	if IAMEnabled {
+		_, err := CheckAccess(ctx, "GetAnomaly")
+		if err != nil {
+			s.LogAccessDenied(ctx, "GetAnomaly", anomalyID, "anomaly", err.Error())
+			return nil, fmt.Errorf("access denied: %w", err)
+		}
+		s.LogDataRead(ctx, "GetAnomaly", anomalyID, "anomaly")
 	}
 
-	payload, err := json.Marshal(anomaly)
+	payload, err := ctx.GetStub().GetState(fmt.Sprintf("ANOMALY_%s", anomalyID))
 	if err != nil {
-		return fmt.Errorf("marshal anomaly: %w", err)
+		return nil, fmt.Errorf("get state: %w", err)
 	}
-
-	key := fmt.Sprintf("ANOMALY_%s", wageID)
-	if err := ctx.GetStub().PutState(key, payload); err != nil {
-		return fmt.Errorf("put state: %w", err)
+	if payload == nil {
+		return nil, fmt.Errorf("anomaly %s not found", anomalyID)
 	}
 
-	// Emit event for anomaly flagging
-	if err := ctx.GetStub().SetEvent("AnomalyFlagged", []byte(wageID)); err != nil {
-		fmt.Printf("warning: failed to emit event: %v\n", err)
+	anomaly := new(Anomaly)
+	if err := json.Unmarshal(payload, anomaly); err != nil || anomaly.DocType != "anomaly" {
+		return nil, fmt.Errorf("anomaly %s not found", anomalyID)
 	}
+	anomaly.AnomalyID = anomalyKeyID(anomaly)
 
-	return nil
+	return anomaly, nil
 }
 
 // GetFlaggedWages retrieves all wages flagged above a threshold score.
+// Results are ordered oldest first (by timestamp, then ID).
 // SECURITY: Only auditors, government officials, and admins.
 func (s *SmartContract) GetFlaggedWages(ctx contractapi.TransactionContextInterface, thresholdStr string) ([]*Anomaly, error) {
 	// IAM Check
@@ -1304,10 +6325,13 @@ func (s *SmartContract) GetFlaggedWages(ctx contractapi.TransactionContextInterf
 		}
 	}
 
+	sortAnomaliesChronologically(anomalies)
+
 	return anomalies, nil
 }
 
-// UpdateAnomalyStatus updates the status of a flagged anomaly.
+// UpdateAnomalyStatus updates the status of a flagged anomaly. wageID identifies the
+// anomaly: the wage ID for the first anomaly on a wage, or the anomalyId of an additional one.
 // SECURITY: Only auditors, government officials, and admins with 'canReviewAnomaly' permission.
 func (s *SmartContract) UpdateAnomalyStatus(ctx contractapi.TransactionContextInterface, wageID string, status string, reviewedBy string) error {
 	if wageID == "" {
@@ -1349,15 +6373,390 @@ func (s *SmartContract) UpdateAnomalyStatus(ctx contractapi.TransactionContextIn
 		return fmt.Errorf("unmarshal anomaly: %w", err)
 	}
 
+	anomaly.AnomalyID = anomalyKeyID(&anomaly)
 	anomaly.Status = status
-	anomaly.Timestamp = time.Now().UTC().Format(time.RFC3339)
+	anomaly.Timestamp = GetTxTimestampRFC3339(ctx)
+
+	return putAnomaly(ctx, &anomaly)
+}
+
+// isOpenAnomalyStatus reports whether an anomaly still needs auditor action.
+func isOpenAnomalyStatus(status string) bool {
+	return status == "pending" || status == "reviewed"
+}
+
+// BatchUpdateAnomalyStatus applies the same status to many anomalies during triage, e.g.
+// dismissing a set of low-severity flags. Each ID is a wage ID or anomalyId, as for
+// UpdateAnomalyStatus. Only open (pending or reviewed) anomalies can move, and only to a
+// different status; anomalies that are missing or already dismissed/confirmed are reported
+// in Failed while the rest are still updated. Each change is audit-logged.
+// SECURITY: Only auditors, government officials, and admins with 'canReviewAnomaly' permission.
+func (s *SmartContract) BatchUpdateAnomalyStatus(ctx contractapi.TransactionContextInterface, anomalyIDs []string, newStatus string, notes string) (*AnomalyBatchResult, error) {
+	// IAM Check
+	if IAMEnabled {
+		identity, err := CheckAccess(ctx, "BatchUpdateAnomalyStatus")
+		if err != nil {
+			s.LogAccessDenied(ctx, "BatchUpdateAnomalyStatus", "batch", "anomaly", err.Error())
+			return nil, fmt.Errorf("access denied: %w", err)
+		}
+		fmt.Printf("[IAM] BatchUpdateAnomalyStatus by %s: %d anomalies -> %s\n", identity.ID, len(anomalyIDs), newStatus)
+	}
+
+	validStatuses := map[string]bool{
+		"pending":   true,
+		"reviewed":  true,
+		"dismissed": true,
+		"confirmed": true,
+	}
+	if !validStatuses[newStatus] {
+		return nil, fmt.Errorf("invalid status: %s", newStatus)
+	}
+	if len(anomalyIDs) == 0 {
+		return nil, fmt.Errorf("at least one anomaly ID is required")
+	}
+
+	result := &AnomalyBatchResult{Updated: []string{}, Failed: make(map[string]string)}
+	processed := make(map[string]bool, len(anomalyIDs))
+	for _, anomalyID := range anomalyIDs {
+		if processed[anomalyID] {
+			continue
+		}
+		processed[anomalyID] = true
+
+		payload, err := ctx.GetStub().GetState(fmt.Sprintf("ANOMALY_%s", anomalyID))
+		if err != nil {
+			return nil, fmt.Errorf("get state: %w", err)
+		}
+		if payload == nil {
+			result.Failed[anomalyID] = "not found"
+			continue
+		}
+
+		var anomaly Anomaly
+		if err := json.Unmarshal(payload, &anomaly); err != nil {
+			result.Failed[anomalyID] = "unreadable anomaly record"
+			continue
+		}
+		if !isOpenAnomalyStatus(anomaly.Status) {
+			result.Failed[anomalyID] = fmt.Sprintf("already %s", anomaly.Status)
+			continue
+		}
+		if anomaly.Status == newStatus {
+			result.Failed[anomalyID] = fmt.Sprintf("already %s", newStatus)
+			continue
+		}
+
+		anomaly.AnomalyID = anomalyKeyID(&anomaly)
+		anomaly.Status = newStatus
+		anomaly.ReviewNotes = notes
+		anomaly.Timestamp = GetTxTimestampRFC3339(ctx)
+		if err := putAnomaly(ctx, &anomaly); err != nil {
+			return nil, err
+		}
+
+		s.LogAccess(ctx, EventAnomalyReviewed, "BatchUpdateAnomalyStatus", anomalyID, "anomaly", "success", fmt.Sprintf("status: %s", newStatus))
+		result.Updated = append(result.Updated, anomalyID)
+	}
+
+	return result, nil
+}
+
+// GetWagesNeedingReview returns a single worklist of wages requiring auditor action,
+// ordered by severity (highest first) and then by age (oldest first).
+// The worklist is built from open anomalies only; wages pending approval are listed for
+// approvers by QueryWagesByStatus.
+// SECURITY: Only auditors, government officials, and admins.
+func (s *SmartContract) GetWagesNeedingReview(ctx contractapi.TransactionContextInterface, offset int, limit int) ([]*ReviewItem, error) {
+	// IAM Check
+	if IAMEnabled {
+		_, err := CheckAccess(ctx, "GetWagesNeedingReview")
+		if err != nil {
+			s.LogAccessDenied(ctx, "GetWagesNeedingReview", "all", "anomaly", err.Error())
+			return nil, fmt.Errorf("access denied: %w", err)
+		}
+		s.LogDataRead(ctx, "GetWagesNeedingReview", fmt.Sprintf("offset:%d", offset), "anomaly")
+	}
+
+	if offset < 0 {
+		offset = 0
+	}
+	if limit <= 0 || limit > 500 {
+		limit = 100
+	}
 
-	newPayload, err := json.Marshal(anomaly)
+	iterator, err := ctx.GetStub().GetStateByRange("ANOMALY_", "ANOMALY_~")
 	if err != nil {
-		return fmt.Errorf("marshal anomaly: %w", err)
+		return nil, fmt.Errorf("get state range: %w", err)
+	}
+	defer iterator.Close()
+
+	var items []*ReviewItem
+	for iterator.HasNext() {
+		queryResponse, err := iterator.Next()
+		if err != nil {
+			return nil, fmt.Errorf("iterate: %w", err)
+		}
+
+		var anomaly Anomaly
+		if err := json.Unmarshal(queryResponse.Value, &anomaly); err != nil {
+			continue
+		}
+		if !isOpenAnomalyStatus(anomaly.Status) {
+			continue
+		}
+
+		item := &ReviewItem{
+			WageID:    anomaly.WageID,
+			Source:    "anomaly",
+			Status:    anomaly.Status,
+			Severity:  anomaly.AnomalyScore,
+			Reason:    anomaly.Reason,
+			FlaggedAt: anomaly.Timestamp,
+		}
+		if wage, err := readWageRecord(ctx, anomaly.WageID); err == nil {
+			item.Wage = wage
+		}
+		items = append(items, item)
+	}
+
+	// Highest severity first, then oldest first (RFC3339 UTC strings sort chronologically)
+	sort.Slice(items, func(i, j int) bool {
+		if items[i].Severity != items[j].Severity {
+			return items[i].Severity > items[j].Severity
+		}
+		if items[i].FlaggedAt != items[j].FlaggedAt {
+			return items[i].FlaggedAt < items[j].FlaggedAt
+		}
+		return items[i].WageID < items[j].WageID
+	})
+
+	if offset >= len(items) {
+		return []*ReviewItem{}, nil
+	}
+	end := offset + limit
+	if end > len(items) {
+		end = len(items)
+	}
+
+	return items[offset:end], nil
+}
+
+// GetFlaggedWagesWithDetails returns open anomalies joined with their full wage records,
+// in anomaly key (wageID) order, so reviewers need a single call instead of one ReadWage
+// per anomaly. Anomalies whose wage is missing are returned with Orphaned set.
+// Results are paged with offset/limit (limit defaults to 100, max 500).
+// SECURITY: Only auditors, government officials, and admins.
+func (s *SmartContract) GetFlaggedWagesWithDetails(ctx contractapi.TransactionContextInterface, offset int, limit int) ([]*FlaggedWageDetail, error) {
+	// IAM Check
+	if IAMEnabled {
+		_, err := CheckAccess(ctx, "GetFlaggedWagesWithDetails")
+		if err != nil {
+			s.LogAccessDenied(ctx, "GetFlaggedWagesWithDetails", "all", "anomaly", err.Error())
+			return nil, fmt.Errorf("access denied: %w", err)
+		}
+		s.LogDataRead(ctx, "GetFlaggedWagesWithDetails", fmt.Sprintf("offset:%d", offset), "anomaly")
+	}
+
+	if offset < 0 {
+		offset = 0
+	}
+	if limit <= 0 || limit > 500 {
+		limit = 100
+	}
+
+	iterator, err := ctx.GetStub().GetStateByRange("ANOMALY_", "ANOMALY_~")
+	if err != nil {
+		return nil, fmt.Errorf("get state range: %w", err)
+	}
+	defer iterator.Close()
+
+	details := []*FlaggedWageDetail{}
+	skipped := 0
+	for iterator.HasNext() && len(details) < limit {
+		queryResponse, err := iterator.Next()
+		if err != nil {
+			return nil, fmt.Errorf("iterate: %w", err)
+		}
+
+		var anomaly Anomaly
+		if err := json.Unmarshal(queryResponse.Value, &anomaly); err != nil {
+			continue
+		}
+		if !isOpenAnomalyStatus(anomaly.Status) {
+			continue
+		}
+		if skipped < offset {
+			skipped++
+			continue
+		}
+
+		detail := &FlaggedWageDetail{Anomaly: &anomaly}
+		payload, err := ctx.GetStub().GetState(anomaly.WageID)
+		if err != nil {
+			return nil, fmt.Errorf("get state: %w", err)
+		}
+		var wage WageRecord
+		if payload != nil && json.Unmarshal(payload, &wage) == nil && wage.DocType == "wage" {
+			detail.Wage = &wage
+		} else {
+			detail.Orphaned = true
+		}
+		details = append(details, detail)
+	}
+
+	return details, nil
+}
+
+// anomalyLifecycle is one raise-to-close span of an anomaly key, read from ledger history
+type anomalyLifecycle struct {
+	raisedAt time.Time
+	closedAt time.Time // Zero while open
+	status   string    // Latest status within the lifecycle
+}
+
+// anomalyLifecycles replays the history of an anomaly key oldest first. A key reused for a
+// new anomaly after the previous one closed yields a new lifecycle.
+func anomalyLifecycles(ctx contractapi.TransactionContextInterface, key string) ([]anomalyLifecycle, error) {
+	historyIter, err := ctx.GetStub().GetHistoryForKey(key)
+	if err != nil {
+		return nil, fmt.Errorf("get history: %w", err)
+	}
+	defer historyIter.Close()
+
+	type version struct {
+		at     time.Time
+		status string
+	}
+	var versions []version
+	for historyIter.HasNext() {
+		modification, err := historyIter.Next()
+		if err != nil {
+			return nil, fmt.Errorf("iterate history: %w", err)
+		}
+		if modification.GetIsDelete() {
+			continue
+		}
+		var anomaly Anomaly
+		if err := json.Unmarshal(modification.GetValue(), &anomaly); err != nil {
+			continue
+		}
+		at := time.Unix(modification.GetTimestamp().GetSeconds(), int64(modification.GetTimestamp().GetNanos())).UTC()
+		versions = append(versions, version{at: at, status: anomaly.Status})
+	}
+	sort.SliceStable(versions, func(i, j int) bool { return versions[i].at.Before(versions[j].at) })
+
+	var lifecycles []anomalyLifecycle
+	for _, v := range versions {
+		last := len(lifecycles) - 1
+		if last < 0 || (!lifecycles[last].closedAt.IsZero() && isOpenAnomalyStatus(v.status)) {
+			lifecycles = append(lifecycles, anomalyLifecycle{raisedAt: v.at})
+			last++
+		}
+		lifecycle := &lifecycles[last]
+		if !lifecycle.closedAt.IsZero() {
+			continue // Further edits to a closed anomaly do not change when it closed
+		}
+		lifecycle.status = v.status
+		if !isOpenAnomalyStatus(v.status) {
+			lifecycle.closedAt = v.at
+		}
+	}
+	return lifecycles, nil
+}
+
+// GetAnomalyResolutionStats reports, for anomalies raised between startDate and endDate
+// (inclusive, YYYY-MM-DD, UTC), how many were resolved (confirmed), dismissed, or are still
+// open, the average hours from raising to closing, and the age of the open backlog at the
+// transaction timestamp ("0-7d", "8-30d", "31-90d", "90d+"). Raise and close times come from
+// the ledger history of each anomaly key, so records created before any timestamp fields
+// existed are measured too.
+// NOTE: This scans all ANOMALY_ keys and reads the history of each, so keep ranges narrow.
+// SECURITY: Only government officials and admins.
+func (s *SmartContract) GetAnomalyResolutionStats(ctx contractapi.TransactionContextInterface, startDate string, endDate string) (*ResolutionStats, error) {
+	start, err := time.Parse("2006-01-02", startDate)
+	if err != nil {
+		return nil, fmt.Errorf("invalid startDate %q: expected YYYY-MM-DD", startDate)
+	}
+	end, err := time.Parse("2006-01-02", endDate)
+	if err != nil {
+		return nil, fmt.Errorf("invalid endDate %q: expected YYYY-MM-DD", endDate)
+	}
+	if end.Before(start) {
+		return nil, fmt.Errorf("endDate must not be before startDate")
+	}
+	endExclusive := end.AddDate(0, 0, 1)
+
+	// IAM Check
+	if IAMEnabled {
+		_, err := CheckAccess(ctx, "GetAnomalyResolutionStats")
+		if err != nil {
+			s.LogAccessDenied(ctx, "GetAnomalyResolutionStats", "all", "anomaly", err.Error())
+			return nil, fmt.Errorf("access denied: %w", err)
+		}
+		s.LogDataRead(ctx, "GetAnomalyResolutionStats", fmt.Sprintf("%s..%s", startDate, endDate), "anomaly")
+	}
+
+	now, err := time.Parse(time.RFC3339, GetTxTimestampRFC3339(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("parse tx timestamp: %w", err)
+	}
+
+	iterator, err := ctx.GetStub().GetStateByRange("ANOMALY_", "ANOMALY_~")
+	if err != nil {
+		return nil, fmt.Errorf("get state range: %w", err)
+	}
+	defer iterator.Close()
+
+	stats := &ResolutionStats{
+		Period:     fmt.Sprintf("%s to %s", startDate, endDate),
+		BacklogAge: map[string]int{"0-7d": 0, "8-30d": 0, "31-90d": 0, "90d+": 0},
+	}
+	var totalResolution time.Duration
+	for iterator.HasNext() {
+		queryResponse, err := iterator.Next()
+		if err != nil {
+			return nil, fmt.Errorf("iterate: %w", err)
+		}
+
+		lifecycles, err := anomalyLifecycles(ctx, queryResponse.Key)
+		if err != nil {
+			return nil, err
+		}
+		for _, lifecycle := range lifecycles {
+			if lifecycle.raisedAt.Before(start) || !lifecycle.raisedAt.Before(endExclusive) {
+				continue
+			}
+			stats.Raised++
+
+			if lifecycle.closedAt.IsZero() {
+				stats.OpenCount++
+				switch ageDays := int(now.Sub(lifecycle.raisedAt).Hours() / 24); {
+				case ageDays <= 7:
+					stats.BacklogAge["0-7d"]++
+				case ageDays <= 30:
+					stats.BacklogAge["8-30d"]++
+				case ageDays <= 90:
+					stats.BacklogAge["31-90d"]++
+				default:
+					stats.BacklogAge["90d+"]++
+				}
+				continue
+			}
+
+			if lifecycle.status == "dismissed" {
+				stats.DismissedCount++
+			} else {
+				stats.ResolvedCount++
+			}
+			totalResolution += lifecycle.closedAt.Sub(lifecycle.raisedAt)
+		}
 	}
 
-	return ctx.GetStub().PutState(key, newPayload)
+	if closed := stats.ResolvedCount + stats.DismissedCount; closed > 0 {
+		stats.AverageResolutionHours = math.Round(totalResolution.Hours()/float64(closed)*100) / 100
+	}
+
+	return stats, nil
 }
 
 // ============================================================================
@@ -1391,6 +6790,8 @@ func (s *SmartContract) GenerateComplianceReport(ctx contractapi.TransactionCont
 
 	switch reportType {
 	case "wage_summary":
+		report.CurrencyBreakdown = make(map[string]float64)
+
 		// Get all wages and calculate summary
 		iterator, err := ctx.GetStub().GetStateByRange("WAGE", "WAGE~")
 		if err != nil {
@@ -1426,9 +6827,10 @@ func (s *SmartContract) GenerateComplianceReport(ctx contractapi.TransactionCont
 				}
 			}
 
-			totalAmount += wage.Amount
+			totalAmount = addAmount(totalAmount, wage.WageID, wage.Amount)
 			count++
 			wages = append(wages, &wage)
+			report.CurrencyBreakdown[wage.Currency] = addAmount(report.CurrencyBreakdown[wage.Currency], wage.WageID, wage.Amount)
 		}
 
 		report.TotalRecords = count
@@ -1468,7 +6870,7 @@ func (s *SmartContract) GenerateComplianceReport(ctx contractapi.TransactionCont
 			}
 
 			data := employerData[wage.EmployerIDHash]
-			data.TotalPaid += wage.Amount
+			data.TotalPaid = addAmount(data.TotalPaid, wage.WageID, wage.Amount)
 			data.WageCount++
 			employerData[wage.EmployerIDHash] = data
 		}
@@ -1476,10 +6878,51 @@ func (s *SmartContract) GenerateComplianceReport(ctx contractapi.TransactionCont
 		report.TotalRecords = len(employerData)
 		report.Data = employerData
 
+	case "program_spending":
+		// Get wages grouped by program; untagged wages are reported as "unassigned"
+		iterator, err := ctx.GetStub().GetStateByRange("WAGE", "WAGE~")
+		if err != nil {
+			return nil, fmt.Errorf("get state range: %w", err)
+		}
+		defer iterator.Close()
+
+		programData := make(map[string]struct {
+			TotalPaid float64 `json:"totalPaid"`
+			WageCount int     `json:"wageCount"`
+		})
+
+		for iterator.HasNext() {
+			queryResponse, err := iterator.Next()
+			if err != nil {
+				continue
+			}
+
+			var wage WageRecord
+			if err := json.Unmarshal(queryResponse.Value, &wage); err != nil || wage.DocType != "wage" {
+				continue
+			}
+
+			program := wage.Program
+			if program == "" {
+				program = "unassigned"
+			}
+			data := programData[program]
+			data.TotalPaid = addAmount(data.TotalPaid, wage.WageID, wage.Amount)
+			data.WageCount++
+			programData[program] = data
+			report.TotalAmount = addAmount(report.TotalAmount, wage.WageID, wage.Amount)
+		}
+
+		report.TotalRecords = len(programData)
+		report.Data = programData
+
 	default:
 		return nil, fmt.Errorf("unknown report type: %s", reportType)
 	}
 
+	report.TotalAmount = roundMoney(report.TotalAmount, "")
+	roundMoneyTotals(report.CurrencyBreakdown)
+
 	return report, nil
 }
 
@@ -1488,12 +6931,19 @@ func (s *SmartContract) GenerateComplianceReport(ctx contractapi.TransactionCont
 // ============================================================================
 
 func main() {
-	chaincode, err := contractapi.NewChaincode(new(SmartContract))
+	for _, problem := range validateAccessRules(GetAccessRules()) {
+		fmt.Printf("[IAM] access rule misconfiguration: %s\n", problem)
+	}
+
+	contract := new(SmartContract)
+	contract.TransactionContextHandler = new(TransactionContext)
+
+	chaincode, err := contractapi.NewChaincode(contract)
 	if err != nil {
 		panic(fmt.Errorf("create chaincode: %w", err))
 	}
 
-	if err := chaincode.Start(); err != nil {
+	if err := startChaincode(chaincode); err != nil {
 		panic(fmt.Errorf("start chaincode: %w", err))
 	}
 }
@@ -0,0 +1,1351 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAddAmountIsExactInPaise(t *testing.T) {
+	// 0.1 + 0.2 drifts in float64; summing a million of them must still be exact
+	var total float64
+	for i := 0; i < 1000000; i++ {
+		total = addAmount(total, "WAGE", 0.1)
+	}
+	if total != 100000 {
+		t.Fatalf("expected exact total 100000, got %v", total)
+	}
+	if got := addAmount(0.1, "WAGE", 0.2); got != 0.3 {
+		t.Fatalf("expected 0.3, got %v", got)
+	}
+}
+
+func TestAddAmountSkipsInvalidAmounts(t *testing.T) {
+	tests := []struct {
+		name   string
+		total  float64
+		amount float64
+	}{
+		{"negative", 500, -100},
+		{"overflow", float64(maxExactPaise) / 100, 1},
+		{"too large", 0, 1e300},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := addAmount(tt.total, "WAGE", tt.amount); got != tt.total {
+				t.Fatalf("expected total %v to be unchanged, got %v", tt.total, got)
+			}
+		})
+	}
+}
+
+func TestGenerateDeterministicIDSequencesWithinTransaction(t *testing.T) {
+	ctx := newMockContext("Org1MSP")
+	ctx.stub.txID = "a1b2c3d4e5f60718293a4b5c6d7e8f90"
+
+	first := generateDeterministicID(ctx, "OP")
+	second := generateDeterministicID(ctx, "OP")
+	if first != "OP_a1b2c3d4e5f60718_000" || second != "OP_a1b2c3d4e5f60718_001" {
+		t.Fatalf("unexpected IDs %q, %q", first, second)
+	}
+
+	// A short txID must not panic and another transaction starts its own sequence
+	other := newMockContext("Org1MSP")
+	other.stub.txID = "tx1"
+	if got := generateDeterministicID(other, "OP"); got != "OP_tx1_000" {
+		t.Fatalf("unexpected ID %q", got)
+	}
+
+	// Re-simulating the same transaction on a fresh context mints the same IDs again
+	again := newMockContext("Org1MSP")
+	again.stub.txID = ctx.stub.txID
+	if got := generateDeterministicID(again, "OP"); got != first {
+		t.Fatalf("re-simulation minted %q, want %q", got, first)
+	}
+}
+
+func TestDetectRoleDrift(t *testing.T) {
+	s := new(SmartContract)
+	ctx := newMockContext("Org1MSP", "role=auditor", "idHash=user-1")
+	user, _ := marshalState(User{DocType: "user", UserIDHash: "user-1", Role: "employer", Status: "active"})
+	ctx.stub.state["USER_user-1"] = user
+
+	drift, err := s.DetectRoleDrift(ctx)
+	if err != nil {
+		t.Fatalf("DetectRoleDrift: %v", err)
+	}
+	if !drift.Drifted || drift.CertRole != "auditor" || drift.StoredRole != "employer" {
+		t.Fatalf("unexpected drift %+v", drift)
+	}
+	if _, ok := ctx.stub.events["RoleDriftDetected"]; !ok {
+		t.Fatal("expected RoleDriftDetected event")
+	}
+
+	ctx.identity.attrs["role"] = "employer"
+	drift, err = s.DetectRoleDrift(ctx)
+	if err != nil {
+		t.Fatalf("DetectRoleDrift: %v", err)
+	}
+	if drift.Drifted {
+		t.Fatalf("matching roles reported as drift: %+v", drift)
+	}
+}
+
+func TestAnomalyReasonCategory(t *testing.T) {
+	tests := map[string]string{
+		"UPI amount mismatch: wage W1 declared 100.00 INR": "upi amount mismatch",
+		"Amount exceeds typical range for job type":        "amount exceeds typical range for job type",
+		"  Ghost Worker ": "ghost worker",
+		"":                "unspecified",
+	}
+	for reason, want := range tests {
+		if got := anomalyReasonCategory(reason); got != want {
+			t.Errorf("anomalyReasonCategory(%q) = %q, want %q", reason, got, want)
+		}
+	}
+}
+
+func TestAmountBucketKey(t *testing.T) {
+	tests := []struct {
+		amountPaise, bucketPaise int64
+		want                     string
+	}{
+		{0, 50000, "0.00-500.00"},
+		{49999, 50000, "0.00-500.00"},
+		{50000, 50000, "500.00-1000.00"},
+		{1234567, 250, "12345.00-12347.50"},
+	}
+	for _, tt := range tests {
+		if got := amountBucketKey(tt.amountPaise, tt.bucketPaise); got != tt.want {
+			t.Errorf("amountBucketKey(%d, %d) = %q, want %q", tt.amountPaise, tt.bucketPaise, got, tt.want)
+		}
+	}
+}
+
+func TestCaptureCallerAttributesWhitelist(t *testing.T) {
+	ctx := newMockContext("Org1MSP", "role=worker", "specialProgram=PMKVY", "aadhaarLast4=1234")
+	if got := captureCallerAttributes(ctx); got != nil {
+		t.Fatalf("captured %v with no whitelist configured", got)
+	}
+
+	if err := putConfigValue(ctx, ConfigAuditCaptureAttributes, "specialProgram,missing", "test"); err != nil {
+		t.Fatalf("putConfigValue: %v", err)
+	}
+	got := captureCallerAttributes(ctx)
+	if len(got) != 1 || got["specialProgram"] != "PMKVY" {
+		t.Fatalf("captureCallerAttributes = %v, want only specialProgram", got)
+	}
+
+	log := &AuditLog{CallerAttributes: got}
+	if !matchesAuditQuery(log, &AuditQuery{CallerAttributes: map[string]string{"specialProgram": ""}}) {
+		t.Error("presence filter should match")
+	}
+	if matchesAuditQuery(log, &AuditQuery{CallerAttributes: map[string]string{"specialProgram": "MGNREGA"}}) {
+		t.Error("value filter should not match a different value")
+	}
+}
+
+func TestWageIndexDate(t *testing.T) {
+	tests := map[string]string{
+		"2025-12-01T10:00:00Z":      "2025-12-01",
+		"2025-12-01T02:00:00+05:30": "2025-11-30",
+		"2025-12-01":                "",
+		"":                          "",
+	}
+	for timestamp, want := range tests {
+		got, ok := wageIndexDate(timestamp)
+		if got != want || ok != (want != "") {
+			t.Errorf("wageIndexDate(%q) = %q, %v; want %q", timestamp, got, ok, want)
+		}
+	}
+}
+
+func TestListFunctionsMatchesAccessRules(t *testing.T) {
+	functions, err := (&SmartContract{}).ListFunctions(newMockContext("Org2MSP", "role=worker"))
+	if err != nil {
+		t.Fatalf("ListFunctions: %v", err)
+	}
+	if len(functions) != len(GetAccessRules()) {
+		t.Fatalf("got %d functions, want %d", len(functions), len(GetAccessRules()))
+	}
+	for i := 1; i < len(functions); i++ {
+		if functions[i-1].Name >= functions[i].Name {
+			t.Fatalf("functions not sorted: %q before %q", functions[i-1].Name, functions[i].Name)
+		}
+	}
+}
+
+func TestBatchUpdateAnomalyStatus(t *testing.T) {
+	ctx := newMockContext("Org1MSP", "role=auditor", "clearanceLevel=7", "canReviewAnomaly=true")
+	for _, anomaly := range []*Anomaly{
+		{DocType: "anomaly", WageID: "WAGE001", Reason: "Ghost worker", Status: "pending"},
+		{DocType: "anomaly", WageID: "WAGE002", Reason: "Ghost worker", Status: "confirmed"},
+	} {
+		if err := putAnomaly(ctx, anomaly); err != nil {
+			t.Fatalf("putAnomaly: %v", err)
+		}
+	}
+
+	s := &SmartContract{}
+	result, err := s.BatchUpdateAnomalyStatus(ctx, []string{"WAGE001", "WAGE002", "WAGE003", "WAGE001"}, "dismissed", "low severity")
+	if err != nil {
+		t.Fatalf("BatchUpdateAnomalyStatus: %v", err)
+	}
+	if len(result.Updated) != 1 || result.Updated[0] != "WAGE001" {
+		t.Fatalf("updated = %v, want [WAGE001]", result.Updated)
+	}
+	if result.Failed["WAGE002"] != "already confirmed" || result.Failed["WAGE003"] != "not found" || len(result.Failed) != 2 {
+		t.Fatalf("failed = %v", result.Failed)
+	}
+
+	var anomaly Anomaly
+	if err := json.Unmarshal(ctx.stub.state["ANOMALY_WAGE001"], &anomaly); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if anomaly.Status != "dismissed" || anomaly.ReviewNotes != "low severity" {
+		t.Fatalf("unexpected anomaly %+v", anomaly)
+	}
+
+	if _, err := s.BatchUpdateAnomalyStatus(ctx, []string{"WAGE001"}, "closed", ""); err == nil {
+		t.Fatal("expected invalid status error")
+	}
+}
+
+func TestSortWagesChronologically(t *testing.T) {
+	wages := []*WageRecord{
+		{WageID: "WAGE001", Timestamp: "2025-12-01T10:00:00Z"},
+		{WageID: "WAGE002", Timestamp: "2025-12-01T12:00:00+05:30"}, // 06:30 UTC
+		{WageID: "WAGE003", Timestamp: "not a timestamp"},
+		{WageID: "WAGE000", Timestamp: "2025-12-01T10:00:00Z"},
+	}
+	sortWagesChronologically(wages)
+
+	var got []string
+	for _, wage := range wages {
+		got = append(got, wage.WageID)
+	}
+	if want := "WAGE003,WAGE002,WAGE000,WAGE001"; strings.Join(got, ",") != want {
+		t.Fatalf("order = %v, want %s", got, want)
+	}
+}
+
+func TestSortWagesNewestFirst(t *testing.T) {
+	wages := []*WageRecord{
+		{WageID: "WAGE001", Timestamp: "2025-12-01T10:00:00Z"},
+		{WageID: "WAGE002", Timestamp: "2025-12-02T10:00:00Z"},
+		{WageID: "WAGE000", Timestamp: "2025-12-01T10:00:00Z"},
+	}
+	sortWagesNewestFirst(wages)
+
+	var got []string
+	for _, wage := range wages {
+		got = append(got, wage.WageID)
+	}
+	if want := "WAGE002,WAGE001,WAGE000"; strings.Join(got, ",") != want {
+		t.Fatalf("order = %v, want %s", got, want)
+	}
+}
+
+func TestQueryWagesByEmployerDeniesOtherEmployers(t *testing.T) {
+	employer := newMockContext("Org1MSP", "role=employer", "clearanceLevel=5", "idHash=employer-1")
+	s := &SmartContract{}
+	if _, err := s.QueryWagesByEmployer(employer, "employer-2"); err == nil || !strings.Contains(err.Error(), "access denied") {
+		t.Fatalf("expected access denied, got %v", err)
+	}
+}
+
+func TestValidateWageIDRejectsReservedPrefixes(t *testing.T) {
+	ctx := newMockContext("Org1MSP")
+	for _, prefix := range reservedKeyPrefixes {
+		if err := validateWageID(ctx, prefix+"001"); err == nil || !strings.Contains(err.Error(), "reserved") {
+			t.Errorf("wageID %q: expected reserved-prefix error, got %v", prefix+"001", err)
+		}
+	}
+	for _, wageID := range []string{"", LedgerInitializedKey, "\x00wage~state\x00"} {
+		if err := validateWageID(ctx, wageID); err == nil {
+			t.Errorf("wageID %q: expected error", wageID)
+		}
+	}
+	for _, wageID := range []string{"WAGE001", "WAGE_UPI_001", "W-2025-0001"} {
+		if err := validateWageID(ctx, wageID); err != nil {
+			t.Errorf("wageID %q: unexpected error %v", wageID, err)
+		}
+	}
+}
+
+func TestValidateWageIDConfiguredPrefixes(t *testing.T) {
+	ctx := newMockContext("Org1MSP")
+	if err := putConfigValue(ctx, ConfigReservedWageIDPrefixes, "TMP_,LEGACY_", "test"); err != nil {
+		t.Fatalf("putConfigValue: %v", err)
+	}
+	if err := validateWageID(ctx, "LEGACY_42"); err == nil {
+		t.Fatal("expected configured prefix to be rejected")
+	}
+	if err := validateWageID(ctx, "UPI_42"); err == nil {
+		t.Fatal("built-in prefixes must still apply")
+	}
+	if err := validateWageID(ctx, "WAGE042"); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+}
+
+func TestGetSchemas(t *testing.T) {
+	payload, err := (&SmartContract{}).GetSchemas(newMockContext("Org2MSP", "role=worker"))
+	if err != nil {
+		t.Fatalf("GetSchemas: %v", err)
+	}
+
+	var doc struct {
+		Version     string `json:"version"`
+		Definitions map[string]struct {
+			Properties map[string]map[string]interface{} `json:"properties"`
+			Required   []string                          `json:"required"`
+		} `json:"definitions"`
+	}
+	if err := json.Unmarshal([]byte(payload), &doc); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if doc.Version != ChaincodeVersion {
+		t.Fatalf("version = %q, want %q", doc.Version, ChaincodeVersion)
+	}
+
+	wage, ok := doc.Definitions["WageRecord"]
+	if !ok {
+		t.Fatal("missing WageRecord definition")
+	}
+	if wage.Properties["amount"]["type"] != "number" || wage.Properties["tags"]["type"] != "array" {
+		t.Fatalf("unexpected WageRecord properties %v", wage.Properties)
+	}
+	required := strings.Join(wage.Required, ",")
+	if !strings.Contains(required, "wageId") || strings.Contains(required, "jobType") {
+		t.Fatalf("required = %v; want wageId but not the omitempty jobType", wage.Required)
+	}
+}
+
+func TestAuditKeyRange(t *testing.T) {
+	startKey, endKey, err := auditKeyRange("2025-12-01", "2025-12-31")
+	if err != nil || startKey != "AUDIT_20251201" || endKey != "AUDIT_20260101" {
+		t.Fatalf("auditKeyRange = %q, %q, %v", startKey, endKey, err)
+	}
+	if startKey, endKey, err := auditKeyRange("", ""); err != nil || startKey != "AUDIT_" || endKey != "AUDIT_~" {
+		t.Fatalf("empty range = %q, %q, %v", startKey, endKey, err)
+	}
+
+	for _, tc := range [][2]string{
+		{"2025-12-01", ""},
+		{"", "2025-12-31"},
+		{"2025-13-01", "2025-12-31"},
+		{"2025-12-01", "31/12/2025"},
+		{"2025-12-31", "2025-12-01"},
+	} {
+		if _, _, err := auditKeyRange(tc[0], tc[1]); err == nil {
+			t.Errorf("auditKeyRange(%q, %q): expected error", tc[0], tc[1])
+		}
+	}
+}
+
+func TestGetWageRecordRawReturnsStoredBytes(t *testing.T) {
+	ctx := newMockContext("Org1MSP", "role=worker", "idHash=worker-1")
+	stored := []byte(`{"wageId":"WAGE001","docType":"wage","workerIdHash":"worker-1","amount":100}`)
+	ctx.stub.state["WAGE001"] = stored
+	ctx.stub.state["WAGE002"] = []byte(`{"docType":"wage","workerIdHash":"worker-2"}`)
+
+	s := &SmartContract{}
+	got, err := s.GetWageRecordRaw(ctx, "WAGE001")
+	if err != nil {
+		t.Fatalf("GetWageRecordRaw: %v", err)
+	}
+	if string(got) != string(stored) {
+		t.Fatalf("got %s, want the stored bytes verbatim", got)
+	}
+
+	if _, err := s.GetWageRecordRaw(ctx, "WAGE002"); err == nil {
+		t.Fatal("worker must not read another worker's wage")
+	}
+	if _, err := s.GetWageRecordRaw(ctx, "WAGE003"); err == nil {
+		t.Fatal("expected not found error")
+	}
+}
+
+func TestNewPaymentCalendar(t *testing.T) {
+	tests := map[string]int{"2024-02": 29, "2025-02": 28, "2025-12": 31, "2025-04": 30}
+	for yearMonth, days := range tests {
+		calendar, err := newPaymentCalendar(yearMonth)
+		if err != nil {
+			t.Fatalf("newPaymentCalendar(%q): %v", yearMonth, err)
+		}
+		if len(calendar) != days {
+			t.Errorf("%s: got %d days, want %d", yearMonth, len(calendar), days)
+		}
+		if day := calendar[yearMonth+"-01"]; day.Date != yearMonth+"-01" || day.Wages == nil || day.WageTotals == nil {
+			t.Errorf("%s: first day not initialised: %+v", yearMonth, day)
+		}
+	}
+
+	for _, yearMonth := range []string{"", "2025-13", "2025-1", "2025-12-01"} {
+		if _, err := newPaymentCalendar(yearMonth); err == nil {
+			t.Errorf("newPaymentCalendar(%q): expected error", yearMonth)
+		}
+	}
+}
+
+func TestCheckBlockedWageValues(t *testing.T) {
+	ctx := newMockContext("Org1MSP")
+	if err := checkBlockedWageValues(ctx, "INR", "construction"); err != nil {
+		t.Fatalf("nothing is blocked by default: %v", err)
+	}
+
+	if err := putConfigValue(ctx, ConfigAllowedCurrencies, "INR,USD", "test"); err != nil {
+		t.Fatalf("putConfigValue: %v", err)
+	}
+	if err := putConfigValue(ctx, ConfigBlockedCurrencies, "USD", "test"); err != nil {
+		t.Fatalf("putConfigValue: %v", err)
+	}
+	if err := putConfigValue(ctx, ConfigBlockedJobTypes, "mining,demolition", "test"); err != nil {
+		t.Fatalf("putConfigValue: %v", err)
+	}
+
+	if err := checkBlockedWageValues(ctx, "USD", "construction"); err == nil || !strings.Contains(err.Error(), "currency USD is blocked") {
+		t.Fatalf("allowed but blocked currency: got %v", err)
+	}
+	if err := checkBlockedWageValues(ctx, "INR", "mining"); err == nil || !strings.Contains(err.Error(), "job type mining is blocked") {
+		t.Fatalf("blocked job type: got %v", err)
+	}
+	if err := checkBlockedWageValues(ctx, "INR", ""); err != nil {
+		t.Fatalf("empty job type must not match: %v", err)
+	}
+	if err := validateBlockedCurrencyList("usd"); err == nil {
+		t.Fatal("expected invalid currency code to be rejected")
+	}
+}
+
+func TestValidateCurrency(t *testing.T) {
+	ctx := newMockContext("Org1MSP")
+	if err := putConfigValue(ctx, ConfigAllowedCurrencies, "INR,USD", "test"); err != nil {
+		t.Fatalf("putConfigValue: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		currency string
+		wantErr  bool
+	}{
+		{"allowed", "USD", false},
+		{"not allowed", "EUR", true},
+		{"lowercase", "inr", true},
+		{"empty", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateCurrency(ctx, tt.currency)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ValidateCurrency(%q) error = %v, wantErr %v", tt.currency, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateUPITxID(t *testing.T) {
+	for _, txID := range []string{"UPI001", "TXN-2025.12.01_42", "412345678901"} {
+		if err := validateUPITxID(txID); err != nil {
+			t.Errorf("txID %q: unexpected error %v", txID, err)
+		}
+	}
+
+	invalid := []string{
+		"",
+		"_foo",
+		"-foo",
+		"UPI_001",
+		"AUDIT_001",
+		"tx~1",
+		"tx\x00wage",
+		"tx 1",
+		"tx/1",
+		"tx\u00e91",
+		strings.Repeat("a", MaxUPITxIDLength+1),
+	}
+	for _, txID := range invalid {
+		if err := validateUPITxID(txID); err == nil {
+			t.Errorf("txID %q: expected error", txID)
+		}
+	}
+}
+
+func TestGetSystemConfig(t *testing.T) {
+	ctx := newMockContext("Org1MSP", "role=admin", "clearanceLevel=9")
+	if err := putConfigValue(ctx, ConfigAuditLevel, AuditLevelWritesOnly, "admin-1"); err != nil {
+		t.Fatalf("putConfigValue: %v", err)
+	}
+
+	s := &SmartContract{}
+	config, err := s.GetSystemConfig(ctx)
+	if err != nil {
+		t.Fatalf("GetSystemConfig: %v", err)
+	}
+	if len(config.Settings) != len(GetConfigSpecs()) {
+		t.Fatalf("got %d settings, want %d", len(config.Settings), len(GetConfigSpecs()))
+	}
+	if entry := config.Settings[ConfigAuditLevel]; entry.Value != AuditLevelWritesOnly || entry.UpdatedBy != "admin-1" {
+		t.Fatalf("stored setting not returned: %+v", entry)
+	}
+	if entry := config.Settings[ConfigAllowedCurrencies]; entry.Value != "INR" || entry.UpdatedBy != "default" {
+		t.Fatalf("unset setting should report its default: %+v", entry)
+	}
+
+	if _, err := s.GetSystemConfig(newMockContext("Org1MSP", "role=auditor", "clearanceLevel=9")); err == nil {
+		t.Fatal("expected non-admins to be denied")
+	}
+}
+
+func TestRecordUPITransactionEventPayload(t *testing.T) {
+	ctx := newMockContext("Org1MSP", "role=bank_officer", "clearanceLevel=5", "canRecordUPI=true")
+	if _, err := (&SmartContract{}).RecordUPITransaction(ctx, "UPI001", "worker-1", 250.5, "INR", "Acme Builders", "", "", "", "", "employer-1"); err != nil {
+		t.Fatalf("RecordUPITransaction: %v", err)
+	}
+
+	var event UPIRecordedEvent
+	if err := json.Unmarshal(ctx.stub.events["UPITransactionRecorded"], &event); err != nil {
+		t.Fatalf("event payload is not JSON: %v", err)
+	}
+	if event.TxID != "UPI001" || event.WorkerIDHash != "worker-1" || event.Amount != 250.5 || event.Currency != "INR" || event.EmployerIDHash != "employer-1" || event.Timestamp == "" {
+		t.Fatalf("unexpected event %+v", event)
+	}
+}
+
+func TestWageVolatility(t *testing.T) {
+	now := time.Date(2025, 12, 15, 10, 0, 0, 0, time.UTC)
+	wage := func(id string, timestamp string, amount float64) *WageRecord {
+		return &WageRecord{WageID: id, Timestamp: timestamp, Amount: amount}
+	}
+
+	// Equal income every month: no volatility
+	steady := []*WageRecord{
+		wage("W1", "2025-10-05T00:00:00Z", 1000),
+		wage("W2", "2025-11-05T00:00:00Z", 1000),
+		wage("W3", "2025-12-05T00:00:00Z", 1000),
+	}
+	if cv, err := wageVolatility(steady, now, 3); err != nil || cv != 0 {
+		t.Fatalf("steady income: cv=%v err=%v, want 0", cv, err)
+	}
+
+	// 1000, 0, 2000 (November unpaid): mean 1000, std-dev sqrt(2/3)*1000
+	uneven := []*WageRecord{
+		wage("W1", "2025-10-05T00:00:00Z", 1000),
+		wage("W2", "2025-12-01T00:00:00Z", 1500),
+		wage("W3", "2025-12-10T00:00:00Z", 500),
+		wage("W4", "2026-01-01T00:00:00Z", 9999), // after now
+	}
+	cv, err := wageVolatility(uneven, now, 3)
+	if err != nil {
+		t.Fatalf("wageVolatility: %v", err)
+	}
+	if want := math.Sqrt(2.0 / 3.0); math.Abs(cv-want) > 1e-9 {
+		t.Fatalf("cv = %v, want %v", cv, want)
+	}
+
+	if _, err := wageVolatility(uneven, now, 4); err == nil || !strings.Contains(err.Error(), "insufficient history") {
+		t.Fatalf("window before first wage: got %v", err)
+	}
+	if _, err := wageVolatility(nil, now, 3); err == nil || !strings.Contains(err.Error(), "insufficient history") {
+		t.Fatalf("no wages: got %v", err)
+	}
+
+	zero := []*WageRecord{wage("W1", "2025-01-05T00:00:00Z", 1000)}
+	if _, err := wageVolatility(zero, now, 6); err == nil || !strings.Contains(err.Error(), "no income") {
+		t.Fatalf("zero mean: got %v", err)
+	}
+}
+
+func TestReadWagesReportsMissingAndDenied(t *testing.T) {
+	ctx := newMockContext("Org1MSP", "role=worker", "idHash=worker-1")
+	ctx.stub.state["WAGE001"] = []byte(`{"wageId":"WAGE001","docType":"wage","workerIdHash":"worker-1","amount":100}`)
+	ctx.stub.state["WAGE002"] = []byte(`{"wageId":"WAGE002","docType":"wage","workerIdHash":"worker-2","amount":200}`)
+	ctx.stub.state["USER001"] = []byte(`{"docType":"user"}`)
+
+	s := &SmartContract{}
+	batch, err := s.ReadWages(ctx, []string{"WAGE001", "WAGE002", "WAGE003", "USER001", "WAGE001"})
+	if err != nil {
+		t.Fatalf("ReadWages: %v", err)
+	}
+	if len(batch.Wages) != 1 || batch.Wages["WAGE001"] == nil {
+		t.Fatalf("wages = %v, want only WAGE001", batch.Wages)
+	}
+	if strings.Join(batch.NotFound, ",") != "WAGE003,USER001" {
+		t.Fatalf("notFound = %v", batch.NotFound)
+	}
+	if strings.Join(batch.Denied, ",") != "WAGE002" {
+		t.Fatalf("denied = %v", batch.Denied)
+	}
+
+	tooMany := make([]string, MaxWagesPerRead+1)
+	for i := range tooMany {
+		tooMany[i] = fmt.Sprintf("WAGE%03d", i)
+	}
+	if _, err := s.ReadWages(ctx, tooMany); err == nil {
+		t.Fatal("expected an error above MaxWagesPerRead")
+	}
+}
+
+func TestCompareThresholdsFallsBackToDefault(t *testing.T) {
+	ctx := newMockContext("Org1MSP", "role=government_official", "clearanceLevel=8")
+	ctx.stub.state["THRESHOLD_DEFAULT_BPL"] = []byte(`{"docType":"threshold","state":"DEFAULT","category":"BPL","amount":32000}`)
+	ctx.stub.state["THRESHOLD_KERALA_BPL"] = []byte(`{"docType":"threshold","state":"KERALA","category":"BPL","amount":40000}`)
+
+	s := &SmartContract{}
+	thresholds, err := s.CompareThresholds(ctx, []string{"KERALA", "BIHAR"}, "BPL")
+	if err != nil {
+		t.Fatalf("CompareThresholds: %v", err)
+	}
+	if got := thresholds["KERALA"]; got == nil || got.Amount != 40000 {
+		t.Fatalf("KERALA = %+v, want its own threshold", got)
+	}
+	if got := thresholds["BIHAR"]; got == nil || got.State != "DEFAULT" || got.Amount != 32000 {
+		t.Fatalf("BIHAR = %+v, want the national threshold", got)
+	}
+
+	if _, err := s.CompareThresholds(ctx, []string{"KERALA"}, "XYZ"); err == nil {
+		t.Fatal("expected an invalid category error")
+	}
+
+	worker := newMockContext("Org1MSP", "role=worker", "idHash=worker-1")
+	if _, err := s.CompareThresholds(worker, []string{"KERALA"}, "BPL"); err == nil {
+		t.Fatal("workers must not compare thresholds")
+	}
+}
+
+func TestPaymentDiscrepancy(t *testing.T) {
+	end := time.Date(2025, 12, 1, 0, 0, 0, 0, time.UTC)
+	start := end.AddDate(0, 0, -DiscrepancyPeriodDays)
+
+	empty := paymentDiscrepancy("worker-1", nil, nil, start, end, 0.01)
+	if empty.HasData || empty.Shortfall || empty.Message == "" {
+		t.Fatalf("expected a no-data result, got %+v", empty)
+	}
+
+	wages := []*WageRecord{
+		{WageID: "W1", Amount: 1000, Currency: "INR", Timestamp: "2025-06-01T00:00:00Z"},
+		{WageID: "W2", Amount: 500, Currency: "INR", Timestamp: "2025-07-01T00:00:00Z"},
+		{WageID: "W3", Amount: 9999, Currency: "INR", Timestamp: "2020-01-01T00:00:00Z"}, // before the period
+	}
+	upis := []*UPITransaction{
+		{TxID: "U1", Amount: 1000, Currency: "INR", Timestamp: "2025-06-02T00:00:00Z"},
+		{TxID: "U2", Amount: 200.5, Currency: "INR", Timestamp: "2025-07-02T00:00:00Z"},
+	}
+	result := paymentDiscrepancy("worker-1", wages, upis, start, end, 0.01)
+	inr := result.Currencies["INR"]
+	if !result.HasData || !result.Shortfall || inr == nil {
+		t.Fatalf("expected an INR shortfall, got %+v", result)
+	}
+	if inr.Declared != 1500 || inr.Received != 1200.5 || inr.Shortfall != 299.5 {
+		t.Fatalf("INR totals = %+v", inr)
+	}
+
+	upis = append(upis, &UPITransaction{TxID: "U3", Amount: 299.5, Currency: "INR", Timestamp: "2025-07-03T00:00:00Z"})
+	if result := paymentDiscrepancy("worker-1", wages, upis, start, end, 0.01); result.Shortfall {
+		t.Fatalf("expected no shortfall once paid in full, got %+v", result.Currencies["INR"])
+	}
+}
+
+func TestRoundMoney(t *testing.T) {
+	tests := []struct {
+		amount   float64
+		currency string
+		want     float64
+	}{
+		{1200.5000000001, "INR", 1200.5},
+		{0.1 + 0.2, "USD", 0.3},
+		{2.675000001, "", 2.68},
+		{1234.5, "JPY", 1235},
+		{1.23456, "KWD", 1.235},
+		{-0.125, "INR", -0.13},
+	}
+	for _, tt := range tests {
+		if got := roundMoney(tt.amount, tt.currency); got != tt.want {
+			t.Errorf("roundMoney(%v, %q) = %v, want %v", tt.amount, tt.currency, got, tt.want)
+		}
+	}
+}
+
+func TestWagesExist(t *testing.T) {
+	ctx := newMockContext("Org1MSP", "role=employer", "idHash=employer-1")
+	ctx.stub.state["WAGE001"] = []byte(`{"wageId":"WAGE001","docType":"wage"}`)
+
+	s := &SmartContract{}
+	exists, err := s.WagesExist(ctx, []string{"WAGE001", "WAGE002", "WAGE001"})
+	if err != nil {
+		t.Fatalf("WagesExist: %v", err)
+	}
+	if len(exists) != 2 || !exists["WAGE001"] || exists["WAGE002"] {
+		t.Fatalf("exists = %v", exists)
+	}
+	if _, ok := exists["WAGE002"]; !ok {
+		t.Fatal("every requested ID must be a key")
+	}
+
+	if _, err := s.WagesExist(ctx, nil); err == nil {
+		t.Fatal("expected an error for no IDs")
+	}
+}
+
+func TestBatchRecordWagesIsAllOrNothing(t *testing.T) {
+	// Fabric discards every write of a failed transaction; the mock does not, so each
+	// call gets a fresh ledger
+	newLedger := func() *mockTransactionContext {
+		ctx := newMockContext("Org1MSP", "role=employer", "idHash=employer-1")
+		ctx.stub.state["WAGE001"] = []byte(`{"docType":"wage","wageId":"WAGE001"}`)
+		return ctx
+	}
+	s := &SmartContract{}
+
+	wage := func(id string) string {
+		return fmt.Sprintf(`{"wageId":%q,"workerIdHash":"worker-1","employerIdHash":"employer-1","amount":500,"currency":"INR","jobType":"construction","timestamp":"2025-12-01T10:00:%02dZ"}`, id, len(id))
+	}
+
+	_, err := s.BatchRecordWages(newLedger(), "["+wage("WAGE002")+","+wage("WAGE001")+"]")
+	if err == nil || !strings.Contains(err.Error(), "WAGE001") {
+		t.Fatalf("expected an error naming WAGE001, got %v", err)
+	}
+	_, err = s.BatchRecordWages(newLedger(), "["+wage("WAGE003")+","+wage("WAGE003")+"]")
+	if err == nil || !strings.Contains(err.Error(), "more than once") {
+		t.Fatalf("expected a repeated-ID error, got %v", err)
+	}
+
+	ctx := newLedger()
+	summary, err := s.BatchRecordWages(ctx, "["+wage("WAGE004")+","+wage("WAGE0005")+"]")
+	if err != nil {
+		t.Fatalf("BatchRecordWages: %v", err)
+	}
+	if summary != "recorded 2 wages" {
+		t.Fatalf("summary = %q", summary)
+	}
+	var event BatchWagesRecordedEvent
+	if err := json.Unmarshal(ctx.stub.events["BatchWagesRecorded"], &event); err != nil {
+		t.Fatalf("unmarshal event: %v", err)
+	}
+	if strings.Join(event.WageIDs, ",") != "WAGE004,WAGE0005" || event.Count != 2 {
+		t.Fatalf("event = %+v", event)
+	}
+}
+
+func TestQuarantineBlocksStateWrites(t *testing.T) {
+	ctx := newMockContext("Org1MSP", "role=admin", "clearanceLevel=10")
+	s := &SmartContract{}
+
+	if err := s.QuarantineState(ctx, "KERALA", "incident 42"); err != nil {
+		t.Fatalf("QuarantineState: %v", err)
+	}
+	if _, ok := ctx.stub.events["StateQuarantined"]; !ok {
+		t.Fatal("expected a StateQuarantined event")
+	}
+	if err := s.QuarantineState(ctx, "KERALA", "again"); err == nil {
+		t.Fatal("expected an error for an already quarantined state")
+	}
+
+	err := checkStateQuarantine(ctx, "KERALA")
+	if err == nil || !strings.Contains(err.Error(), "incident 42") {
+		t.Fatalf("expected a descriptive quarantine error, got %v", err)
+	}
+	for _, state := range []string{"kerala", " Kerala "} {
+		if err := checkStateQuarantine(ctx, state); err == nil {
+			t.Fatalf("expected %q to match the KERALA quarantine", state)
+		}
+	}
+	if err := checkStateQuarantine(ctx, "BIHAR"); err != nil {
+		t.Fatalf("other states must not be affected: %v", err)
+	}
+	if err := checkStateQuarantine(ctx, ""); err != nil {
+		t.Fatalf("records without a state must not be affected: %v", err)
+	}
+
+	if err := s.LiftQuarantine(ctx, "kerala"); err != nil {
+		t.Fatalf("LiftQuarantine: %v", err)
+	}
+	if _, ok := ctx.stub.events["StateQuarantineLifted"]; !ok {
+		t.Fatal("expected a StateQuarantineLifted event")
+	}
+	if err := checkStateQuarantine(ctx, "KERALA"); err != nil {
+		t.Fatalf("expected writes to resume: %v", err)
+	}
+	if err := s.LiftQuarantine(ctx, "KERALA"); err == nil {
+		t.Fatal("expected an error lifting a state that is not quarantined")
+	}
+
+	employer := newMockContext("Org1MSP", "role=employer", "clearanceLevel=5")
+	if err := s.QuarantineState(employer, "KERALA", "x"); err == nil {
+		t.Fatal("only admins may quarantine a state")
+	}
+}
+
+func TestProjectRecords(t *testing.T) {
+	wageType := reflect.TypeOf(WageRecord{})
+	if err := validateProjection(wageType, []string{"amount", "timestamp"}); err != nil {
+		t.Fatalf("validateProjection: %v", err)
+	}
+	if err := validateProjection(wageType, []string{"amount", "Amount"}); err == nil {
+		t.Fatal("expected Go field names to be rejected")
+	}
+
+	wages := []*WageRecord{{WageID: "W1", Amount: 100, Currency: "INR", Timestamp: "2025-12-01T00:00:00Z"}}
+	projected, err := projectRecords(wages, []string{"amount", "timestamp", "program"})
+	if err != nil {
+		t.Fatalf("projectRecords: %v", err)
+	}
+	if len(projected) != 1 || len(projected[0]) != 2 || projected[0]["amount"] != 100.0 || projected[0]["timestamp"] != "2025-12-01T00:00:00Z" {
+		t.Fatalf("projected = %v", projected)
+	}
+
+	full, err := projectRecords(wages, nil)
+	if err != nil || full[0]["wageId"] != "W1" || full[0]["currency"] != "INR" {
+		t.Fatalf("expected every field without a projection, got %v (%v)", full, err)
+	}
+}
+
+func TestFieldQueriesAuditUnderTheirOwnName(t *testing.T) {
+	s := &SmartContract{}
+	auditedFunctions := func(ctx *mockTransactionContext) []string {
+		var functions []string
+		for key, value := range ctx.stub.state {
+			if strings.HasPrefix(key, "AUDIT_") {
+				var log AuditLog
+				if err := json.Unmarshal(value, &log); err != nil {
+					t.Fatalf("unmarshal audit log: %v", err)
+				}
+				functions = append(functions, log.Function)
+			}
+		}
+		return functions
+	}
+
+	worker := newMockContext("Org1MSP", "role=worker", "idHash=worker-1")
+	worker.stub.state["WAGE001"] = []byte(`{"docType":"wage","wageId":"WAGE001","workerIdHash":"worker-1","amount":100,"timestamp":"2025-12-01T10:00:00Z"}`)
+	projected, err := s.QueryWagesByWorkerFields(worker, "worker-1", []string{"amount"})
+	if err != nil || len(projected) != 1 || projected[0]["amount"] != 100.0 {
+		t.Fatalf("QueryWagesByWorkerFields = %v, %v", projected, err)
+	}
+	if functions := auditedFunctions(worker); !reflect.DeepEqual(functions, []string{"QueryWagesByWorkerFields"}) {
+		t.Fatalf("expected a single QueryWagesByWorkerFields entry, got %v", functions)
+	}
+
+	other := newMockContext("Org1MSP", "role=worker", "idHash=worker-2")
+	if _, err := s.QueryUPITransactionsByWorkerFields(other, "worker-1", nil); err == nil || !strings.Contains(err.Error(), "access denied") {
+		t.Fatalf("expected access denied for another worker, got %v", err)
+	}
+	if functions := auditedFunctions(other); !reflect.DeepEqual(functions, []string{"QueryUPITransactionsByWorkerFields"}) {
+		t.Fatalf("expected the denial under QueryUPITransactionsByWorkerFields, got %v", functions)
+	}
+}
+
+func TestGetAnomaly(t *testing.T) {
+	ctx := newMockContext("Org1MSP", "role=auditor", "clearanceLevel=8")
+	ctx.stub.state["ANOMALY_WAGE001"] = []byte(`{"docType":"anomaly","wageId":"WAGE001","reason":"test","status":"pending"}`)
+
+	s := &SmartContract{}
+	anomaly, err := s.GetAnomaly(ctx, "WAGE001")
+	if err != nil {
+		t.Fatalf("GetAnomaly: %v", err)
+	}
+	if anomaly.WageID != "WAGE001" || anomaly.AnomalyID != "WAGE001" {
+		t.Fatalf("anomaly = %+v", anomaly)
+	}
+
+	if _, err := s.GetAnomaly(ctx, "WAGE002"); err == nil || !strings.Contains(err.Error(), "not found") {
+		t.Fatalf("expected a not-found error, got %v", err)
+	}
+
+	worker := newMockContext("Org1MSP", "role=worker", "idHash=worker-1")
+	worker.stub.state = ctx.stub.state
+	if _, err := s.GetAnomaly(worker, "WAGE001"); err == nil {
+		t.Fatal("workers must not read anomalies")
+	}
+}
+
+func TestMonthlyIncome(t *testing.T) {
+	wages := []*WageRecord{
+		{WageID: "WAGE001", Amount: 100.10, Timestamp: "2025-11-05T10:00:00Z"},
+		{WageID: "WAGE002", Amount: 200.20, Timestamp: "2025-12-31T23:59:59Z"},
+		{WageID: "WAGE003", Amount: 0.10, Timestamp: "2025-12-01T00:00:00Z"},
+		{WageID: "WAGE004", Amount: 50, Timestamp: "2026-01-01T00:00:00Z"},
+		{WageID: "WAGE005", Amount: 75, Timestamp: "not a timestamp"},
+		{WageID: "WAGE006", Amount: 10, Currency: "USD", Timestamp: "2025-12-15T10:00:00Z"},
+	}
+	transactions := []*UPITransaction{
+		{TxID: "UPI1", Amount: 200.20, OnChainReference: "WAGE002", Timestamp: "2026-01-01T08:00:00Z"},
+		{TxID: "UPI2", Amount: 25, Timestamp: "2026-01-15T10:00:00Z"},
+		{TxID: "UPI3", Amount: 5, Timestamp: ""},
+	}
+
+	months := monthlyIncome(wages, transactions, 2)
+	var got []string
+	for _, month := range months {
+		got = append(got, fmt.Sprintf("%s%s=%.2f/%d/%d", month.Month, month.Currency, month.TotalIncome, month.WageCount, month.PaymentCount))
+	}
+	// 2025-11 falls outside the limit; linked UPI1 is not counted again; the USD wage
+	// gets its own 2025-12 entry instead of being added to the other one
+	want := "2026-01=75.00/1/1,2025-12=200.30/2/0,2025-12USD=10.00/1/0,unknown=80.00/1/1"
+	if strings.Join(got, ",") != want {
+		t.Fatalf("months = %v, want %s", got, want)
+	}
+
+	if months := monthlyIncome(nil, nil, 12); len(months) != 0 {
+		t.Fatalf("expected no months, got %d", len(months))
+	}
+}
+
+func TestGetWorkerCaseFileDeniesWorkers(t *testing.T) {
+	worker := newMockContext("Org1MSP", "role=worker", "idHash=worker-1")
+	s := &SmartContract{}
+	if _, err := s.GetWorkerCaseFile(worker, "worker-1"); err == nil || !strings.Contains(err.Error(), "access denied") {
+		t.Fatalf("expected access denied, got %v", err)
+	}
+}
+
+func TestGetWorkerCaseFileWarnings(t *testing.T) {
+	ctx := newMockContext("Org1MSP", "role=government_official")
+	ctx.stub.state["WAGE001"] = []byte(`{"docType":"wage","wageId":"WAGE001","workerIdHash":"worker-1","amount":100,"currency":"INR","timestamp":"2025-11-01T10:00:00Z"}`)
+	s := &SmartContract{}
+
+	caseFile, err := s.GetWorkerCaseFile(ctx, "worker-1")
+	if err != nil {
+		t.Fatalf("GetWorkerCaseFile: %v", err)
+	}
+	if caseFile.Profile != nil || !strings.Contains(strings.Join(caseFile.Warnings, "; "), "worker is not registered") {
+		t.Fatalf("expected a missing profile warning, got %v", caseFile.Warnings)
+	}
+	if caseFile.TotalIncome != 100 {
+		t.Fatalf("expected total income 100, got %v", caseFile.TotalIncome)
+	}
+
+	ctx.stub.state["WAGE002"] = []byte(`{"docType":"wage","wageId":"WAGE002","workerIdHash":"worker-1","amount":50,"currency":"USD","timestamp":"2025-11-02T10:00:00Z"}`)
+	caseFile, err = s.GetWorkerCaseFile(ctx, "worker-1")
+	if err != nil {
+		t.Fatalf("GetWorkerCaseFile: %v", err)
+	}
+	if caseFile.TotalIncome != 0 || !strings.Contains(strings.Join(caseFile.Warnings, "; "), "total income unavailable") {
+		t.Fatalf("expected no total and a warning for mixed currencies, got %v (%v)", caseFile.TotalIncome, caseFile.Warnings)
+	}
+}
+
+func TestSumIncome(t *testing.T) {
+	all := func(string) bool { return true }
+	wages := []*WageRecord{
+		{WageID: "WAGE001", Amount: 100.10, Currency: "INR", Timestamp: "2025-12-01T10:00:00Z"},
+		{WageID: "WAGE002", Amount: 200.20, Currency: "INR", Timestamp: "2025-12-02T10:00:00Z"},
+	}
+	transactions := []*UPITransaction{
+		{TxID: "UPI1", Amount: 100.10, Currency: "INR", OnChainReference: "WAGE001", Timestamp: "2025-12-03T10:00:00Z"},
+		{TxID: "UPI2", Amount: 50.05, Currency: "INR", Timestamp: "2025-12-04T10:00:00Z"},
+	}
+
+	total, err := sumIncome(wages, transactions, all)
+	if err != nil {
+		t.Fatalf("sumIncome: %v", err)
+	}
+	if total != 350.35 {
+		t.Fatalf("total = %v, want 350.35 (linked payments must not be counted twice)", total)
+	}
+
+	before := func(timestamp string) bool { return timestamp < "2025-12-02" }
+	if total, err := sumIncome(wages, transactions, before); err != nil || total != 100.10 {
+		t.Fatalf("ranged total = %v, %v", total, err)
+	}
+
+	transactions = append(transactions, &UPITransaction{TxID: "UPI3", Amount: 10, Currency: "USD", Timestamp: "2025-12-05T10:00:00Z"})
+	if _, err := sumIncome(wages, transactions, all); err == nil || !strings.Contains(err.Error(), "INR, USD") {
+		t.Fatalf("expected a mixed-currency error, got %v", err)
+	}
+}
+
+func TestQueryWagesByWorkersAuditsDeterministically(t *testing.T) {
+	s := &SmartContract{}
+	run := func() (map[string]string, map[string][]*WageRecord) {
+		ctx := newMockContext("Org1MSP", "role=auditor", "clearanceLevel=8")
+		ctx.stub.txID = "a1b2c3d4e5f60718293a4b5c6d7e8f90"
+		ctx.stub.state["WAGE001"] = []byte(`{"docType":"wage","wageId":"WAGE001","workerIdHash":"worker-b","timestamp":"2025-12-01T10:00:00Z"}`)
+
+		result, err := s.QueryWagesByWorkers(ctx, []string{"worker-c", "worker-a", "worker-b", "worker-a", "worker-d"})
+		if err != nil {
+			t.Fatalf("QueryWagesByWorkers: %v", err)
+		}
+		targets := make(map[string]string)
+		for key, value := range ctx.stub.state {
+			if strings.HasPrefix(key, "AUDIT_") {
+				var log AuditLog
+				if err := json.Unmarshal(value, &log); err != nil {
+					t.Fatalf("unmarshal audit log: %v", err)
+				}
+				targets[key] = log.TargetID
+			}
+		}
+		return targets, result
+	}
+
+	first, result := run()
+	if len(first) != 4 {
+		t.Fatalf("expected one audit entry per distinct worker, got %v", first)
+	}
+	if len(result) != 4 || len(result["worker-b"]) != 1 || len(result["worker-a"]) != 0 {
+		t.Fatalf("result = %v", result)
+	}
+	for i := 0; i < 10; i++ {
+		if again, _ := run(); !reflect.DeepEqual(again, first) {
+			t.Fatalf("audit keys differ between runs: %v vs %v", again, first)
+		}
+	}
+}
+
+func TestRecordUPITransactionLinkedWageChecks(t *testing.T) {
+	s := &SmartContract{}
+	newLedger := func() *mockTransactionContext {
+		ctx := newMockContext("Org1MSP", "role=bank_officer", "clearanceLevel=5", "canRecordUPI=true")
+		if err := putConfigValue(ctx, ConfigAllowedCurrencies, "INR,USD", "test"); err != nil {
+			t.Fatal(err)
+		}
+		ctx.stub.state["WAGE001"] = []byte(`{"docType":"wage","wageId":"WAGE001","workerIdHash":"worker-1","employerIdHash":"employer-1","amount":500,"currency":"INR"}`)
+		return ctx
+	}
+
+	ctx := newLedger()
+	_, err := s.RecordUPITransaction(ctx, "UPI001", "worker-1", 500, "USD", "Acme Builders", "", "", "", "WAGE001", "")
+	if err == nil || !strings.Contains(err.Error(), "is in INR but the payment is in USD") {
+		t.Fatalf("expected a currency mismatch error, got %v", err)
+	}
+
+	ctx = newLedger()
+	if _, err := s.RecordUPITransaction(ctx, "UPI002", "worker-1", 300, "INR", "Acme Builders", "", "", "", "WAGE001", ""); err != nil {
+		t.Fatalf("RecordUPITransaction: %v", err)
+	}
+	if ctx.stub.state["ANOMALY_WAGE001"] == nil || ctx.stub.events["UPIAmountMismatch"] == nil {
+		t.Fatal("expected the short payment to be flagged")
+	}
+
+	mismatches := func(ctx *mockTransactionContext) []*Anomaly {
+		var found []*Anomaly
+		for key, value := range ctx.stub.state {
+			if !strings.HasPrefix(key, "ANOMALY_") {
+				continue
+			}
+			var anomaly Anomaly
+			if err := json.Unmarshal(value, &anomaly); err != nil {
+				t.Fatalf("unmarshal anomaly: %v", err)
+			}
+			if anomaly.Category == "upi amount mismatch" {
+				found = append(found, &anomaly)
+			}
+		}
+		return found
+	}
+
+	// An unrelated open anomaly is kept and the mismatch gets its own ID
+	ctx = newLedger()
+	if err := putAnomaly(ctx, &Anomaly{DocType: "anomaly", WageID: "WAGE001", Reason: "auditor", Status: "reviewed"}); err != nil {
+		t.Fatal(err)
+	}
+	existing := string(ctx.stub.state["ANOMALY_WAGE001"])
+	if _, err := s.RecordUPITransaction(ctx, "UPI003", "worker-1", 300, "INR", "Acme Builders", "", "", "", "WAGE001", ""); err != nil {
+		t.Fatalf("RecordUPITransaction: %v", err)
+	}
+	if string(ctx.stub.state["ANOMALY_WAGE001"]) != existing {
+		t.Fatal("existing anomaly must not be overwritten")
+	}
+	if found := mismatches(ctx); len(found) != 1 || found[0].AnomalyID == "WAGE001" || ctx.stub.events["UPIAmountMismatch"] == nil {
+		t.Fatalf("expected a separately keyed mismatch anomaly and event, got %+v", found)
+	}
+
+	// A closed anomaly does not suppress the flag; its slot is reused
+	ctx = newLedger()
+	if err := putAnomaly(ctx, &Anomaly{DocType: "anomaly", WageID: "WAGE001", Reason: "UPI amount mismatch: earlier", Status: "dismissed"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.RecordUPITransaction(ctx, "UPI004", "worker-1", 300, "INR", "Acme Builders", "", "", "", "WAGE001", ""); err != nil {
+		t.Fatalf("RecordUPITransaction: %v", err)
+	}
+	found := mismatches(ctx)
+	if len(found) != 1 || found[0].AnomalyID != "WAGE001" || found[0].Status != "pending" || ctx.stub.events["UPIAmountMismatch"] == nil {
+		t.Fatalf("expected the closed anomaly's slot to carry the new flag, got %+v", found)
+	}
+
+	// An open mismatch anomaly is neither duplicated nor reported again
+	ctx = newLedger()
+	if err := putAnomaly(ctx, &Anomaly{DocType: "anomaly", WageID: "WAGE001", Reason: "UPI amount mismatch: earlier", Status: "pending"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.RecordUPITransaction(ctx, "UPI005", "worker-1", 300, "INR", "Acme Builders", "", "", "", "WAGE001", ""); err != nil {
+		t.Fatalf("RecordUPITransaction: %v", err)
+	}
+	if found := mismatches(ctx); len(found) != 1 {
+		t.Fatalf("expected no duplicate mismatch anomaly, got %+v", found)
+	}
+	if _, ok := ctx.stub.events["UPIAmountMismatch"]; ok {
+		t.Fatal("no mismatch event expected when nothing was flagged")
+	}
+}
+
+func TestMonthlyIncomeCapAcrossBatchAndBackdating(t *testing.T) {
+	s := &SmartContract{}
+	newLedger := func() *mockTransactionContext {
+		ctx := newMockContext("Org1MSP", "role=employer", "idHash=employer-1")
+		if err := putConfigValue(ctx, ConfigMonthlyIncomeCap, "1000", "test"); err != nil {
+			t.Fatal(err)
+		}
+		// Transaction time is December; November already has 900 paid
+		ctx.stub.state["WAGE001"] = []byte(`{"docType":"wage","wageId":"WAGE001","workerIdHash":"worker-1","amount":900,"timestamp":"2025-11-10T10:00:00Z"}`)
+		return ctx
+	}
+	wage := func(id string, amount float64, timestamp string) string {
+		return fmt.Sprintf(`{"wageId":%q,"workerIdHash":"worker-1","employerIdHash":"employer-1","amount":%v,"currency":"INR","jobType":"construction","timestamp":%q}`, id, amount, timestamp)
+	}
+
+	// Wages of one batch count towards each other's cap
+	_, err := s.BatchRecordWages(newLedger(), "["+wage("WAGE002", 600, "2025-12-01T10:00:00Z")+","+wage("WAGE003", 600, "2025-12-02T10:00:00Z")+"]")
+	if err == nil || !strings.Contains(err.Error(), "WAGE003") || !strings.Contains(err.Error(), "monthly income cap") {
+		t.Fatalf("expected the second wage to exceed the cap, got %v", err)
+	}
+
+	// A backdated wage is checked against its own month
+	if err := s.RecordWage(newLedger(), "WAGE004", "worker-1", "employer-1", 200, "INR", "construction", "2025-11-20T10:00:00Z", "", ""); err == nil {
+		t.Fatal("expected the November wage to exceed November's cap")
+	}
+	if err := s.RecordWage(newLedger(), "WAGE005", "worker-1", "employer-1", 200, "INR", "construction", "2025-12-20T10:00:00Z", "", ""); err != nil {
+		t.Fatalf("December wage is within December's cap: %v", err)
+	}
+}
+
+func TestWageApprovalQueue(t *testing.T) {
+	employer := newMockContext("Org1MSP", "role=employer", "idHash=employer-1")
+	s := &SmartContract{}
+	for i, id := range []string{"WAGE001", "WAGE002", "WAGE003"} {
+		timestamp := fmt.Sprintf("2025-11-0%dT10:00:00Z", i+1)
+		if err := s.RecordWage(employer, id, "worker-1", "employer-1", 100, "INR", "construction", timestamp, "", ""); err != nil {
+			t.Fatalf("RecordWage %s: %v", id, err)
+		}
+	}
+	official := newMockContext("Org1MSP", "role=government_official")
+	official.stub = employer.stub
+
+	page, err := s.QueryWagesByStatus(official, WageStatusPending, 2, "")
+	if err != nil {
+		t.Fatalf("QueryWagesByStatus: %v", err)
+	}
+	if page.Count != 2 || page.Records[0].WageID != "WAGE001" || page.Bookmark == "" {
+		t.Fatalf("first page = %+v", page)
+	}
+	if page, err = s.QueryWagesByStatus(official, WageStatusPending, 2, page.Bookmark); err != nil || page.Count != 1 || page.Records[0].WageID != "WAGE003" {
+		t.Fatalf("second page = %+v, %v", page, err)
+	}
+
+	if err := s.UpdateWageStatus(employer, "WAGE001", WageStatusApproved); err == nil {
+		t.Fatal("employers must not approve wages")
+	}
+	if err := s.UpdateWageStatus(official, "WAGE001", WageStatusApproved); err != nil {
+		t.Fatalf("UpdateWageStatus: %v", err)
+	}
+	if err := s.UpdateWageStatus(official, "WAGE001", WageStatusRejected); err == nil {
+		t.Fatal("only pending wages can be decided")
+	}
+
+	if page, err = s.QueryWagesByStatus(official, WageStatusPending, 10, ""); err != nil || page.Count != 2 {
+		t.Fatalf("pending after approval = %+v, %v", page, err)
+	}
+	if page, err = s.QueryWagesByStatus(official, WageStatusApproved, 10, ""); err != nil || page.Count != 1 || page.Records[0].Status != WageStatusApproved {
+		t.Fatalf("approved = %+v, %v", page, err)
+	}
+	if page, err = s.QueryWagesByStatus(official, WageStatusRejected, 10, ""); err != nil || page.Records == nil || page.Count != 0 {
+		t.Fatalf("expected an empty rejected page, got %+v, %v", page, err)
+	}
+	if _, err := s.QueryWagesByStatus(official, "done", 10, ""); err == nil || !strings.Contains(err.Error(), "invalid status") {
+		t.Fatalf("expected an unknown status to be rejected, got %v", err)
+	}
+}
+
+func TestCheckPovertyStatusCountsWagesOnly(t *testing.T) {
+	ctx := newMockContext("Org1MSP", "role=worker", "idHash=worker-1")
+	ctx.stub.state["WAGE001"] = []byte(`{"docType":"wage","wageId":"WAGE001","workerIdHash":"worker-1","amount":20000,"currency":"INR","timestamp":"2025-06-01T10:00:00Z"}`)
+	ctx.stub.state["UPI_UPI1"] = []byte(`{"docType":"upi","txId":"UPI1","workerIdHash":"worker-1","amount":20000,"currency":"USD","timestamp":"2025-07-01T10:00:00Z"}`)
+	s := &SmartContract{}
+
+	result, err := s.CheckPovertyStatus(ctx, "worker-1", "", "", "")
+	if err != nil {
+		t.Fatalf("CheckPovertyStatus: %v", err)
+	}
+	if result.Status != "BPL" || result.TotalIncome != 20000 {
+		t.Fatalf("expected BPL on wages alone, got %+v", result)
+	}
+
+	if _, err := s.CalculateTotalIncome(ctx, "worker-1", "", ""); err == nil || !strings.Contains(err.Error(), "INR, USD") {
+		t.Fatalf("expected CalculateTotalIncome to reject mixed currencies, got %v", err)
+	}
+	if _, err := s.CalculateTotalIncome(ctx, "worker-1", "2025-01-01", "2025-13-01"); err == nil || !strings.Contains(err.Error(), "invalid endDate") {
+		t.Fatalf("expected an invalid endDate error, got %v", err)
+	}
+	if _, err := s.CheckPovertyStatus(ctx, "worker-1", "", "2025-01-01", ""); err == nil {
+		t.Fatal("expected an error for a start date without an end date")
+	}
+}
+
+func TestQueryUPIBySenderPrefersEmployerAttribution(t *testing.T) {
+	ctx := newMockContext("Org1MSP", "role=bank_officer", "clearanceLevel=5", "canRecordUPI=true")
+	s := &SmartContract{}
+	record := func(txID string, senderName string, employerIDHash string) {
+		ctx.stub.txTime = ctx.stub.txTime.Add(time.Minute)
+		if _, err := s.RecordUPITransaction(ctx, txID, "worker-1", 100, "INR", senderName, "", "", "", "", employerIDHash); err != nil {
+			t.Fatalf("RecordUPITransaction %s: %v", txID, err)
+		}
+	}
+	record("UPI1", "Acme Builders", "employer-1")
+	record("UPI2", "ACME BUILDERS PVT LTD", "employer-1") // Same employer, different spelling
+	record("UPI3", "Acme Builders", "")                   // No attribution: matched by name
+	record("UPI4", "Other Co", "employer-2")
+
+	transactions, err := s.QueryUPIBySender(ctx, "Acme Builders")
+	if err != nil {
+		t.Fatalf("QueryUPIBySender: %v", err)
+	}
+	var got []string
+	for _, tx := range transactions {
+		got = append(got, tx.TxID)
+	}
+	sort.Strings(got)
+	if want := "UPI1,UPI2,UPI3"; strings.Join(got, ",") != want {
+		t.Fatalf("got %v, want %s", got, want)
+	}
+}
+
+func TestInitLedgerRejectsSecondCall(t *testing.T) {
+	ctx := newMockContext("Org1MSP", "role=admin", "clearanceLevel=10")
+	s := &SmartContract{}
+
+	if err := s.InitLedger(ctx); err != nil {
+		t.Fatalf("InitLedger: %v", err)
+	}
+	err := s.InitLedger(ctx)
+	if err == nil || !strings.Contains(err.Error(), "already initialized") {
+		t.Fatalf("expected a second InitLedger to be rejected, got %v", err)
+	}
+	if err := s.ForceInitLedger(ctx); err != nil {
+		t.Fatalf("ForceInitLedger should re-seed an initialized ledger: %v", err)
+	}
+}
+
+func TestFlagAnomalyAttributesCaller(t *testing.T) {
+	ctx := newMockContext("Org1MSP", "role=government_official")
+	ctx.stub.state["WAGE001"] = []byte(`{"docType":"wage","wageId":"WAGE001","workerIdHash":"worker-1","amount":100,"currency":"INR","timestamp":"2025-12-01T10:00:00Z"}`)
+	s := &SmartContract{}
+
+	if err := s.FlagAnomaly(ctx, "WAGE001", "0.9", "outlier", "someone-else"); err != nil {
+		t.Fatalf("FlagAnomaly: %v", err)
+	}
+	callerID, err := ctx.GetClientIdentity().GetID()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	anomalies, err := s.QueryAnomaliesByFlagger(ctx, callerID)
+	if err != nil {
+		t.Fatalf("QueryAnomaliesByFlagger: %v", err)
+	}
+	if len(anomalies) != 1 || anomalies[0].FlaggedBy != callerID {
+		t.Fatalf("expected the anomaly to be attributed to the caller, got %+v", anomalies)
+	}
+	if spoofed, err := s.QueryAnomaliesByFlagger(ctx, "someone-else"); err != nil || len(spoofed) != 0 {
+		t.Fatalf("the flaggedBy argument must not be indexed, got %v, %v", spoofed, err)
+	}
+}
+
+func TestFlagAnomalyRetryIsNotAudited(t *testing.T) {
+	ctx := newMockContext("Org1MSP", "role=government_official")
+	ctx.stub.state["WAGE001"] = []byte(`{"docType":"wage","wageId":"WAGE001","workerIdHash":"worker-1","amount":100,"currency":"INR","timestamp":"2025-12-01T10:00:00Z"}`)
+	s := &SmartContract{}
+
+	flagAudits := func() int {
+		count := 0
+		for key, value := range ctx.stub.state {
+			var log AuditLog
+			if strings.HasPrefix(key, "AUDIT_") && json.Unmarshal(value, &log) == nil && log.EventType == EventAnomalyFlagged {
+				count++
+			}
+		}
+		return count
+	}
+
+	if err := s.FlagAnomaly(ctx, "WAGE001", "0.9", "outlier", ""); err != nil {
+		t.Fatalf("FlagAnomaly: %v", err)
+	}
+	var anomaly Anomaly
+	if err := json.Unmarshal(ctx.stub.state["ANOMALY_WAGE001"], &anomaly); err != nil {
+		t.Fatal(err)
+	}
+	if anomaly.Timestamp != "2025-12-01T10:00:00Z" {
+		t.Fatalf("Timestamp = %s, want the transaction timestamp", anomaly.Timestamp)
+	}
+
+	// The retry arrives in a later transaction
+	retry := newMockContext("Org1MSP", "role=government_official")
+	retry.stub.state = ctx.stub.state
+	retry.stub.txID = "mocktx9876543210"
+	if err := s.FlagAnomaly(retry, "WAGE001", "0.9", "outlier", ""); err != nil {
+		t.Fatalf("retrying FlagAnomaly should be a no-op: %v", err)
+	}
+	if got := flagAudits(); got != 1 {
+		t.Fatalf("expected only the recorded flag to be audited, got %d audit entries", got)
+	}
+}
+
+func TestProgramSpendingReportSkipsNonWageDocuments(t *testing.T) {
+	ctx := newMockContext("Org1MSP", "role=government_official")
+	ctx.stub.state["WAGE001"] = []byte(`{"docType":"wage","wageId":"WAGE001","amount":100,"currency":"INR","program":"MGNREGA"}`)
+	ctx.stub.state["WAGE_SUMMARY"] = []byte(`{"docType":"summary","amount":500,"program":"MGNREGA"}`)
+	s := &SmartContract{}
+
+	report, err := s.GenerateComplianceReport(ctx, "", "", "program_spending")
+	if err != nil {
+		t.Fatalf("GenerateComplianceReport: %v", err)
+	}
+	if report.TotalAmount != 100 || report.TotalRecords != 1 {
+		t.Fatalf("expected only the wage to be counted, got total %v over %d programs", report.TotalAmount, report.TotalRecords)
+	}
+}
+
+func TestGetWorkerRiskScoreRejectsMixedCurrencies(t *testing.T) {
+	ctx := newMockContext("Org1MSP", "role=government_official")
+	ctx.stub.state["WAGE001"] = []byte(`{"docType":"wage","wageId":"WAGE001","workerIdHash":"worker-1","amount":100,"currency":"INR","timestamp":"2025-11-01T10:00:00Z"}`)
+	s := &SmartContract{}
+
+	risk, err := s.GetWorkerRiskScore(ctx, "worker-1")
+	if err != nil || risk.Currency != "INR" || risk.AnnualIncome != 100 {
+		t.Fatalf("GetWorkerRiskScore = %+v, %v", risk, err)
+	}
+
+	ctx.stub.state["WAGE002"] = []byte(`{"docType":"wage","wageId":"WAGE002","workerIdHash":"worker-1","amount":50,"currency":"USD","timestamp":"2025-11-02T10:00:00Z"}`)
+	if _, err := s.GetWorkerRiskScore(ctx, "worker-1"); err == nil || !strings.Contains(err.Error(), "multiple currencies") {
+		t.Fatalf("expected mixed currencies to be rejected, got %v", err)
+	}
+}
+
+func TestGetWorkerCreditProfileUsesWageCurrency(t *testing.T) {
+	ctx := newMockContext("Org1MSP", "role=bank_officer", "clearanceLevel=5")
+	ctx.stub.state["WAGE001"] = []byte(`{"docType":"wage","wageId":"WAGE001","workerIdHash":"worker-1","amount":100.10,"currency":"INR","timestamp":"2025-11-01T10:00:00Z"}`)
+	ctx.stub.state["WAGE002"] = []byte(`{"docType":"wage","wageId":"WAGE002","workerIdHash":"worker-1","amount":0.20,"currency":"INR","timestamp":"2025-11-02T10:00:00Z"}`)
+	ctx.stub.state[consentKey("worker-1", "C1")] = []byte(`{"docType":"consent","consentId":"C1","workerIdHash":"worker-1","grantee":"Org1MSP","grantedAt":"2025-11-01T00:00:00Z"}`)
+	s := &SmartContract{}
+
+	profile, err := s.GetWorkerCreditProfile(ctx, "worker-1")
+	if err != nil {
+		t.Fatalf("GetWorkerCreditProfile: %v", err)
+	}
+	if profile.Currency != "INR" || profile.TotalIncome != 100.30 || profile.AverageMonthlyIncome != 100.30 {
+		t.Fatalf("profile = %+v", profile)
+	}
+
+	ctx.stub.state["WAGE003"] = []byte(`{"docType":"wage","wageId":"WAGE003","workerIdHash":"worker-1","amount":50,"currency":"USD","timestamp":"2025-11-03T10:00:00Z"}`)
+	if _, err := s.GetWorkerCreditProfile(ctx, "worker-1"); err == nil || !strings.Contains(err.Error(), "multiple currencies") {
+		t.Fatalf("expected mixed currencies to be rejected, got %v", err)
+	}
+}
@@ -0,0 +1,655 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/hyperledger/fabric-contract-api-go/v2/contractapi"
+)
+
+// ============================================================================
+// CONFIGURATION DATA STRUCTURES
+// ============================================================================
+
+// ConfigEntry represents a tunable contract setting stored on ledger under CONFIG_<name>.
+type ConfigEntry struct {
+	DocType   string `json:"docType"`
+	Name      string `json:"name"`
+	Value     string `json:"value"`
+	UpdatedBy string `json:"updatedBy"`
+	UpdatedAt string `json:"updatedAt"`
+}
+
+// SystemConfig is the full set of configuration settings in force, keyed by setting name
+type SystemConfig struct {
+	ChaincodeVersion string                  `json:"chaincodeVersion"`
+	IAMEnabled       bool                    `json:"iamEnabled"`
+	Settings         map[string]*ConfigEntry `json:"settings"`
+}
+
+// ConfigSpec describes a known configuration setting and its default value
+type ConfigSpec struct {
+	Default     string             // Value used when the setting has never been stored
+	Description string             // Human-readable description
+	Validate    func(string) error // Optional validation applied by SetConfig
+}
+
+// ============================================================================
+// CONFIGURATION KEYS
+// ============================================================================
+
+const (
+	// ConfigUPIAmountTolerance is the maximum absolute difference allowed between
+	// a UPI payment and the wage it is linked to before an anomaly is raised
+	ConfigUPIAmountTolerance = "upiAmountTolerance"
+
+	// ConfigAllowedCurrencies is the comma-separated list of ISO 4217 currency codes
+	// accepted by monetary writes
+	ConfigAllowedCurrencies = "allowedCurrencies"
+
+	// ConfigBlockedCurrencies is the comma-separated list of currency codes RecordWage rejects
+	// even when they are in ConfigAllowedCurrencies
+	ConfigBlockedCurrencies = "blockedCurrencies"
+
+	// ConfigBlockedJobTypes is the comma-separated list of job types RecordWage rejects
+	ConfigBlockedJobTypes = "blockedJobTypes"
+
+	// ConfigStrictWageTags enables validation of wage tags against ConfigAllowedWageTags
+	ConfigStrictWageTags = "strictWageTags"
+
+	// ConfigAllowedWageTags is the comma-separated list of tags accepted in strict mode
+	ConfigAllowedWageTags = "allowedWageTags"
+
+	// ConfigBruteForceDenialThreshold is the number of denials within a window above
+	// which DetectBruteForcePatterns flags a caller
+	ConfigBruteForceDenialThreshold = "bruteForceDenialThreshold"
+
+	// ConfigAuditLevel controls which audit events LogAccess persists to state
+	ConfigAuditLevel = "auditLevel"
+
+	// ConfigSelfAccessBypassRoles is the comma-separated list of roles that CheckSelfAccess
+	// lets read any subject's data
+	ConfigSelfAccessBypassRoles = "selfAccessBypassRoles"
+
+	// ConfigSelfAccessBypassClearance is the clearance level at or above which CheckSelfAccess
+	// lets any role read any subject's data (0 disables it)
+	ConfigSelfAccessBypassClearance = "selfAccessBypassClearance"
+
+	// ConfigHighValueWageThreshold is the wage amount above which RecordWage attaches a
+	// key-level endorsement policy to the record (0 disables it)
+	ConfigHighValueWageThreshold = "highValueWageThreshold"
+
+	// ConfigHighValueEndorsingOrgs is the comma-separated list of MSP IDs whose peers must
+	// all endorse later changes to a high-value wage record
+	ConfigHighValueEndorsingOrgs = "highValueEndorsingOrgs"
+
+	// ConfigMonthlyIncomeCap is the most a worker may be paid in wages per calendar month
+	// (UTC, by transaction time) before RecordWage applies the cap action (0 disables it)
+	ConfigMonthlyIncomeCap = "monthlyIncomeCap"
+
+	// ConfigMonthlyIncomeCapAction selects whether a wage over the monthly cap is rejected
+	// or recorded and flagged as an anomaly
+	ConfigMonthlyIncomeCapAction = "monthlyIncomeCapAction"
+
+	// ConfigReservedWageIDPrefixes is a comma-separated list of key prefixes RecordWage rejects
+	// in wageIDs, in addition to the built-in reserved prefixes
+	ConfigReservedWageIDPrefixes = "reservedWageIDPrefixes"
+
+	// ConfigCurrentPolicyVersion is the wage policy version currently in force; wages recorded
+	// under any other version may need re-evaluation
+	ConfigCurrentPolicyVersion = "currentPolicyVersion"
+
+	// ConfigAuditCaptureAttributes is the comma-separated whitelist of certificate
+	// attributes that LogAccess copies into each audit entry (empty captures none)
+	ConfigAuditCaptureAttributes = "auditCaptureAttributes"
+
+	// ConfigHighRiskEventLevel is the lowest risk level for which LogAccess emits the
+	// HighRiskActivity chaincode event
+	ConfigHighRiskEventLevel = "highRiskEventLevel"
+)
+
+// DefaultSelfAccessBypassRoles is the compiled default for ConfigSelfAccessBypassRoles
+const DefaultSelfAccessBypassRoles = "admin,government_official,auditor"
+
+// Actions for ConfigMonthlyIncomeCapAction
+const (
+	IncomeCapActionReject = "REJECT" // Refuse the wage
+	IncomeCapActionFlag   = "FLAG"   // Record the wage and raise an anomaly
+)
+
+// Audit verbosity levels for ConfigAuditLevel. Denials and high-risk events are
+// persisted at every level.
+const (
+	AuditLevelAll         = "ALL"          // Persist every event
+	AuditLevelWritesOnly  = "WRITES_ONLY"  // Skip DATA_READ events
+	AuditLevelDenialsOnly = "DENIALS_ONLY" // Persist only denials and high-risk events
+)
+
+// Risk levels for ConfigHighRiskEventLevel
+const (
+	EventLevelHigh     = "HIGH"     // Emit for high and critical entries
+	EventLevelCritical = "CRITICAL" // Emit for critical entries only
+)
+
+// GetConfigSpecs returns the known configuration settings and their defaults
+func GetConfigSpecs() map[string]ConfigSpec {
+	return map[string]ConfigSpec{
+		ConfigUPIAmountTolerance: {
+			Default:     "0.01",
+			Description: "Allowed difference between a UPI amount and its linked wage amount",
+			Validate:    validateNonNegativeFloat,
+		},
+		ConfigAllowedCurrencies: {
+			Default:     "INR",
+			Description: "Comma-separated currency codes accepted by RecordWage and RecordUPITransaction",
+			Validate:    validateCurrencyList,
+		},
+		ConfigBlockedCurrencies: {
+			Default:     "",
+			Description: "Comma-separated currency codes RecordWage rejects; takes precedence over allowedCurrencies",
+			Validate:    validateBlockedCurrencyList,
+		},
+		ConfigBlockedJobTypes: {
+			Default:     "",
+			Description: "Comma-separated job types RecordWage rejects",
+		},
+		ConfigStrictWageTags: {
+			Default:     "false",
+			Description: "Reject wage tags that are not in allowedWageTags",
+			Validate:    validateBool,
+		},
+		ConfigAllowedWageTags: {
+			Default:     "",
+			Description: "Comma-separated wage tags accepted when strictWageTags is enabled",
+		},
+		ConfigBruteForceDenialThreshold: {
+			Default:     "5",
+			Description: "Denials per caller within the detection window that count as a brute-force pattern",
+			Validate:    validatePositiveInt,
+		},
+		ConfigAuditLevel: {
+			Default:     AuditLevelAll,
+			Description: "Audit verbosity: ALL, WRITES_ONLY, or DENIALS_ONLY",
+			Validate:    validateAuditLevel,
+		},
+		ConfigSelfAccessBypassRoles: {
+			Default:     DefaultSelfAccessBypassRoles,
+			Description: "Comma-separated roles that bypass self-access checks",
+			Validate:    validateRoleList,
+		},
+		ConfigSelfAccessBypassClearance: {
+			Default:     "0",
+			Description: "Clearance level that bypasses self-access checks regardless of role (0 disables)",
+			Validate:    validateClearanceThreshold,
+		},
+		ConfigHighValueWageThreshold: {
+			Default:     "100000",
+			Description: "Wage amount above which the record gets a multi-org key-level endorsement policy (0 disables)",
+			Validate:    validateNonNegativeFloat,
+		},
+		ConfigHighValueEndorsingOrgs: {
+			Default:     "Org1MSP,Org2MSP",
+			Description: "Comma-separated MSP IDs that must all endorse changes to high-value wage records",
+			Validate:    validateNonEmptyList,
+		},
+		ConfigMonthlyIncomeCap: {
+			Default:     "0",
+			Description: "Maximum wages per worker per calendar month before the cap action applies (0 disables)",
+			Validate:    validateNonNegativeFloat,
+		},
+		ConfigMonthlyIncomeCapAction: {
+			Default:     IncomeCapActionReject,
+			Description: "What RecordWage does with a wage over monthlyIncomeCap: REJECT or FLAG",
+			Validate:    validateIncomeCapAction,
+		},
+		ConfigReservedWageIDPrefixes: {
+			Default:     "",
+			Description: "Comma-separated extra key prefixes that wageIDs must not start with",
+		},
+		ConfigCurrentPolicyVersion: {
+			Default:     CurrentPolicyVersion,
+			Description: "Wage policy version currently in force, used by QueryWagesByOutdatedPolicy",
+			Validate:    validateNonEmpty,
+		},
+		ConfigAuditCaptureAttributes: {
+			Default:     "",
+			Description: "Comma-separated certificate attributes recorded on audit entries for forensic queries",
+			Validate:    validateAttributeNameList,
+		},
+		ConfigHighRiskEventLevel: {
+			Default:     EventLevelHigh,
+			Description: "Lowest risk level that emits the HighRiskActivity event: HIGH or CRITICAL",
+			Validate:    validateEventLevel,
+		},
+	}
+}
+
+// configKey returns the ledger key for a configuration setting
+func configKey(name string) string {
+	return fmt.Sprintf("CONFIG_%s", name)
+}
+
+// validateNonNegativeFloat checks that a config value is a number >= 0
+func validateNonNegativeFloat(value string) error {
+	parsed, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return fmt.Errorf("value must be a number: %w", err)
+	}
+	if parsed < 0 {
+		return fmt.Errorf("value must not be negative")
+	}
+	return nil
+}
+
+// validatePositiveInt checks that a config value is an integer >= 1
+func validatePositiveInt(value string) error {
+	parsed, err := strconv.Atoi(value)
+	if err != nil {
+		return fmt.Errorf("value must be an integer: %w", err)
+	}
+	if parsed < 1 {
+		return fmt.Errorf("value must be at least 1")
+	}
+	return nil
+}
+
+// validateClearanceThreshold checks that a config value is 0 (disabled) or a clearance level from 1 to 10
+func validateClearanceThreshold(value string) error {
+	parsed, err := strconv.Atoi(value)
+	if err != nil {
+		return fmt.Errorf("value must be an integer: %w", err)
+	}
+	if parsed < 0 || parsed > 10 {
+		return fmt.Errorf("value must be between 0 and 10")
+	}
+	return nil
+}
+
+// validateBool checks that a config value is "true" or "false"
+func validateBool(value string) error {
+	if _, err := strconv.ParseBool(value); err != nil || (value != "true" && value != "false") {
+		return fmt.Errorf("value must be true or false")
+	}
+	return nil
+}
+
+// validateAuditLevel checks that a config value is a known audit verbosity level
+func validateAuditLevel(value string) error {
+	switch value {
+	case AuditLevelAll, AuditLevelWritesOnly, AuditLevelDenialsOnly:
+		return nil
+	}
+	return fmt.Errorf("value must be one of %s, %s, %s", AuditLevelAll, AuditLevelWritesOnly, AuditLevelDenialsOnly)
+}
+
+// validateEventLevel checks that a config value is a known high-risk event level
+func validateEventLevel(value string) error {
+	switch value {
+	case EventLevelHigh, EventLevelCritical:
+		return nil
+	}
+	return fmt.Errorf("value must be one of %s, %s", EventLevelHigh, EventLevelCritical)
+}
+
+// validateIncomeCapAction checks that a config value is a known monthly income cap action
+func validateIncomeCapAction(value string) error {
+	switch value {
+	case IncomeCapActionReject, IncomeCapActionFlag:
+		return nil
+	}
+	return fmt.Errorf("value must be one of %s, %s", IncomeCapActionReject, IncomeCapActionFlag)
+}
+
+// validateRoleList checks that a config value lists only known roles (an empty list is allowed)
+func validateRoleList(value string) error {
+	for _, role := range splitConfigList(value) {
+		if !knownRoles[role] {
+			return fmt.Errorf("unknown role %q", role)
+		}
+	}
+	return nil
+}
+
+// validateNonEmpty checks that a config value is not blank
+func validateNonEmpty(value string) error {
+	if strings.TrimSpace(value) == "" {
+		return fmt.Errorf("value must not be empty")
+	}
+	return nil
+}
+
+// validateNonEmptyList checks that a config value lists at least one item
+func validateNonEmptyList(value string) error {
+	if len(splitConfigList(value)) == 0 {
+		return fmt.Errorf("at least one value is required")
+	}
+	return nil
+}
+
+// validateAttributeNameList checks that a config value lists plausible certificate attribute
+// names (letters, digits, '.', '_' and '-'); an empty list is allowed
+func validateAttributeNameList(value string) error {
+	for _, name := range splitConfigList(value) {
+		for _, r := range name {
+			if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '.' || r == '_' || r == '-') {
+				return fmt.Errorf("invalid attribute name %q", name)
+			}
+		}
+	}
+	return nil
+}
+
+// validateCurrencyList checks that a config value is a non-empty list of 3-letter currency codes
+func validateCurrencyList(value string) error {
+	codes := splitConfigList(value)
+	if len(codes) == 0 {
+		return fmt.Errorf("at least one currency is required")
+	}
+	for _, code := range codes {
+		if len(code) != 3 || strings.ToUpper(code) != code {
+			return fmt.Errorf("invalid currency code %q: expected 3 uppercase letters", code)
+		}
+	}
+	return nil
+}
+
+// validateBlockedCurrencyList checks that a config value lists valid currency codes (an empty list is allowed)
+func validateBlockedCurrencyList(value string) error {
+	if len(splitConfigList(value)) == 0 {
+		return nil
+	}
+	return validateCurrencyList(value)
+}
+
+// ============================================================================
+// CONFIGURATION FUNCTIONS
+// ============================================================================
+
+// SetConfig stores a configuration setting on the ledger.
+// SECURITY: Only admins from Org1MSP can change contract configuration.
+func (s *SmartContract) SetConfig(ctx contractapi.TransactionContextInterface, name string, value string) error {
+	// IAM Check
+	updatedBy := "system"
+	if IAMEnabled {
+		identity, err := CheckAccess(ctx, "SetConfig")
+		if err != nil {
+			s.LogAccessDenied(ctx, "SetConfig", name, "config", err.Error())
+			return fmt.Errorf("access denied: %w", err)
+		}
+		s.LogAccess(ctx, EventConfigChanged, "SetConfig", name, "config", "success", fmt.Sprintf("value: %s", value))
+		fmt.Printf("[IAM] SetConfig by %s: %s = %s\n", identity.ID, name, value)
+		updatedBy = identity.ID
+	}
+
+	spec, known := GetConfigSpecs()[name]
+	if !known {
+		return fmt.Errorf("unknown config setting: %s", name)
+	}
+	if spec.Validate != nil {
+		if err := spec.Validate(value); err != nil {
+			return fmt.Errorf("invalid value for %s: %w", name, err)
+		}
+	}
+
+	if err := putConfigValue(ctx, name, value, updatedBy); err != nil {
+		return err
+	}
+
+	// Emit event
+	if err := ctx.GetStub().SetEvent("ConfigChanged", []byte(name)); err != nil {
+		fmt.Printf("warning: failed to emit event: %v\n", err)
+	}
+
+	return nil
+}
+
+// GetConfig retrieves a configuration setting, falling back to its default if unset.
+// SECURITY: Only admins, government officials, and auditors can read configuration.
+func (s *SmartContract) GetConfig(ctx contractapi.TransactionContextInterface, name string) (*ConfigEntry, error) {
+	// IAM Check
+	if IAMEnabled {
+		_, err := CheckAccess(ctx, "GetConfig")
+		if err != nil {
+			s.LogAccessDenied(ctx, "GetConfig", name, "config", err.Error())
+			return nil, fmt.Errorf("access denied: %w", err)
+		}
+		s.LogDataRead(ctx, "GetConfig", name, "config")
+	}
+
+	spec, known := GetConfigSpecs()[name]
+	if !known {
+		return nil, fmt.Errorf("unknown config setting: %s", name)
+	}
+
+	return readConfigEntry(ctx, name, spec)
+}
+
+// GetSystemConfig returns every known configuration setting in one read, with defaults for
+// settings that have never been stored (UpdatedBy "default"), alongside the chaincode version
+// and whether IAM is enabled.
+// SECURITY: Only admins.
+func (s *SmartContract) GetSystemConfig(ctx contractapi.TransactionContextInterface) (*SystemConfig, error) {
+	// IAM Check
+	if IAMEnabled {
+		_, err := CheckAccess(ctx, "GetSystemConfig")
+		if err != nil {
+			s.LogAccessDenied(ctx, "GetSystemConfig", "", "config", err.Error())
+			return nil, fmt.Errorf("access denied: %w", err)
+		}
+		s.LogDataRead(ctx, "GetSystemConfig", "all", "config")
+	}
+
+	specs := GetConfigSpecs()
+	config := &SystemConfig{
+		ChaincodeVersion: ChaincodeVersion,
+		IAMEnabled:       IAMEnabled,
+		Settings:         make(map[string]*ConfigEntry, len(specs)),
+	}
+	for name, spec := range specs {
+		entry, err := readConfigEntry(ctx, name, spec)
+		if err != nil {
+			return nil, err
+		}
+		config.Settings[name] = entry
+	}
+
+	return config, nil
+}
+
+// readConfigEntry reads a stored configuration entry without access checks, returning the
+// spec default when it has not been stored
+func readConfigEntry(ctx contractapi.TransactionContextInterface, name string, spec ConfigSpec) (*ConfigEntry, error) {
+	payload, err := ctx.GetStub().GetState(configKey(name))
+	if err != nil {
+		return nil, fmt.Errorf("get state: %w", err)
+	}
+	if payload == nil {
+		return &ConfigEntry{DocType: "config", Name: name, Value: spec.Default, UpdatedBy: "default"}, nil
+	}
+
+	entry := new(ConfigEntry)
+	if err := json.Unmarshal(payload, entry); err != nil {
+		return nil, fmt.Errorf("unmarshal config: %w", err)
+	}
+
+	return entry, nil
+}
+
+// ============================================================================
+// CONFIGURATION HELPERS
+// ============================================================================
+
+// getConfigValue reads a configuration value without access checks, returning
+// the setting's default when it has not been stored
+func getConfigValue(ctx contractapi.TransactionContextInterface, name string) (string, error) {
+	payload, err := ctx.GetStub().GetState(configKey(name))
+	if err != nil {
+		return "", fmt.Errorf("get config %s: %w", name, err)
+	}
+	if payload == nil {
+		return GetConfigSpecs()[name].Default, nil
+	}
+
+	var entry ConfigEntry
+	if err := json.Unmarshal(payload, &entry); err != nil {
+		return "", fmt.Errorf("unmarshal config %s: %w", name, err)
+	}
+	return entry.Value, nil
+}
+
+// putConfigValue writes a configuration value without access checks or validation
+func putConfigValue(ctx contractapi.TransactionContextInterface, name string, value string, updatedBy string) error {
+	entry := ConfigEntry{
+		DocType:   "config",
+		Name:      name,
+		Value:     value,
+		UpdatedBy: updatedBy,
+		UpdatedAt: GetTxTimestampRFC3339(ctx),
+	}
+
+	payload, err := marshalState(entry)
+	if err != nil {
+		return fmt.Errorf("marshal config: %w", err)
+	}
+
+	if err := ctx.GetStub().PutState(configKey(name), payload); err != nil {
+		return fmt.Errorf("put state: %w", err)
+	}
+	return nil
+}
+
+// splitConfigList parses a comma-separated config value, dropping blank items
+func splitConfigList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// getConfigList reads a comma-separated configuration value
+func getConfigList(ctx contractapi.TransactionContextInterface, name string) ([]string, error) {
+	value, err := getConfigValue(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	return splitConfigList(value), nil
+}
+
+// ValidateCurrency checks a currency against the configured allow-list
+func ValidateCurrency(ctx contractapi.TransactionContextInterface, currency string) error {
+	if currency == "" {
+		return fmt.Errorf("currency is required")
+	}
+	allowed, err := getConfigList(ctx, ConfigAllowedCurrencies)
+	if err != nil {
+		return err
+	}
+	for _, code := range allowed {
+		if code == currency {
+			return nil
+		}
+	}
+	return fmt.Errorf("currency %s is not accepted (allowed: %s)", currency, strings.Join(allowed, ", "))
+}
+
+// getConfigFloat reads a numeric configuration value
+func getConfigFloat(ctx contractapi.TransactionContextInterface, name string) (float64, error) {
+	value, err := getConfigValue(ctx, name)
+	if err != nil {
+		return 0, err
+	}
+	parsed, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return 0, fmt.Errorf("config %s is not a number: %w", name, err)
+	}
+	return parsed, nil
+}
+
+// getConfigInt reads an integer configuration value
+func getConfigInt(ctx contractapi.TransactionContextInterface, name string) (int, error) {
+	value, err := getConfigValue(ctx, name)
+	if err != nil {
+		return 0, err
+	}
+	parsed, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, fmt.Errorf("config %s is not an integer: %w", name, err)
+	}
+	return parsed, nil
+}
+
+// getConfigBool reads a boolean configuration value
+func getConfigBool(ctx contractapi.TransactionContextInterface, name string) (bool, error) {
+	value, err := getConfigValue(ctx, name)
+	if err != nil {
+		return false, err
+	}
+	return value == "true", nil
+}
+
+// checkBlockedWageValues rejects a wage whose currency or job type is on a configured deny-list.
+// It runs before the currency allow-list, so a value on both lists is rejected.
+func checkBlockedWageValues(ctx contractapi.TransactionContextInterface, currency string, jobType string) error {
+	blocked := []struct {
+		name  string
+		field string
+		value string
+	}{
+		{ConfigBlockedCurrencies, "currency", currency},
+		{ConfigBlockedJobTypes, "job type", jobType},
+	}
+	for _, check := range blocked {
+		if check.value == "" {
+			continue
+		}
+		values, err := getConfigList(ctx, check.name)
+		if err != nil {
+			return err
+		}
+		for _, value := range values {
+			if value == check.value {
+				return fmt.Errorf("%s %s is blocked by the %s config", check.field, check.value, check.name)
+			}
+		}
+	}
+	return nil
+}
+
+// ValidateWageTags rejects blank tags and, when strict mode is enabled, tags outside the allow-list
+func ValidateWageTags(ctx contractapi.TransactionContextInterface, tags []string) error {
+	if len(tags) == 0 {
+		return nil
+	}
+	for _, tag := range tags {
+		if strings.TrimSpace(tag) == "" {
+			return fmt.Errorf("wage tags must not be blank")
+		}
+	}
+
+	strict, err := getConfigBool(ctx, ConfigStrictWageTags)
+	if err != nil || !strict {
+		return err
+	}
+	allowed, err := getConfigList(ctx, ConfigAllowedWageTags)
+	if err != nil {
+		return err
+	}
+	for _, tag := range tags {
+		accepted := false
+		for _, candidate := range allowed {
+			if candidate == tag {
+				accepted = true
+				break
+			}
+		}
+		if !accepted {
+			return fmt.Errorf("wage tag %s is not accepted (allowed: %s)", tag, strings.Join(allowed, ", "))
+		}
+	}
+	return nil
+}
@@ -0,0 +1,265 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/v2/contractapi"
+)
+
+// ============================================================================
+// CONSENT DATA STRUCTURES
+// ============================================================================
+
+// Consent records a worker allowing an organisation to read their income data, e.g. a
+// bank assessing a loan. It is stored under CONSENT_<workerIDHash>_<consentID>, so a
+// worker's consents are one range scan. Revoked consents are kept with RevokedAt set.
+type Consent struct {
+	DocType      string `json:"docType"`
+	ConsentID    string `json:"consentId"`
+	WorkerIDHash string `json:"workerIdHash"`
+	Grantee      string `json:"grantee"` // MSP ID of the organisation allowed to read
+	Purpose      string `json:"purpose,omitempty"`
+	GrantedAt    string `json:"grantedAt"`
+	ExpiresAt    string `json:"expiresAt,omitempty"` // RFC3339; empty means no expiry
+	RevokedAt    string `json:"revokedAt,omitempty"`
+}
+
+// consentKey returns the ledger key of a worker's consent
+func consentKey(workerIDHash string, consentID string) string {
+	return fmt.Sprintf("CONSENT_%s_%s", workerIDHash, consentID)
+}
+
+// isActive reports whether the consent is neither revoked nor expired at now (RFC3339 UTC)
+func (c *Consent) isActive(now string) bool {
+	return c.RevokedAt == "" && (c.ExpiresAt == "" || c.ExpiresAt > now)
+}
+
+// ============================================================================
+// CONSENT FUNCTIONS
+// ============================================================================
+
+// queryConsents returns every consent a worker has granted, including revoked and expired
+// ones, oldest first.
+func queryConsents(ctx contractapi.TransactionContextInterface, workerIDHash string) ([]*Consent, error) {
+	prefix := consentKey(workerIDHash, "")
+	iterator, err := ctx.GetStub().GetStateByRange(prefix, prefix+"~")
+	if err != nil {
+		return nil, fmt.Errorf("get state range: %w", err)
+	}
+	defer iterator.Close()
+
+	consents := []*Consent{}
+	for iterator.HasNext() {
+		queryResponse, err := iterator.Next()
+		if err != nil {
+			return nil, fmt.Errorf("iterate: %w", err)
+		}
+
+		var consent Consent
+		if err := json.Unmarshal(queryResponse.Value, &consent); err != nil || consent.DocType != "consent" {
+			continue
+		}
+		// Another worker's hash may share this one as a prefix
+		if consent.WorkerIDHash != workerIDHash {
+			continue
+		}
+		consents = append(consents, &consent)
+	}
+
+	return consents, nil
+}
+
+// queryActiveConsents returns a worker's consents that are in force at the transaction time
+func queryActiveConsents(ctx contractapi.TransactionContextInterface, workerIDHash string) ([]*Consent, error) {
+	consents, err := queryConsents(ctx, workerIDHash)
+	if err != nil {
+		return nil, err
+	}
+
+	now := GetTxTimestampRFC3339(ctx)
+	active := []*Consent{}
+	for _, consent := range consents {
+		if consent.isActive(now) {
+			active = append(active, consent)
+		}
+	}
+	return active, nil
+}
+
+// checkWorkerConsent returns an error unless the worker (under any of their alias hashes)
+// has an active consent for the caller's organisation. Admins are exempt.
+func checkWorkerConsent(ctx contractapi.TransactionContextInterface, identity *ClientIdentity, function string, workerIDHash string) error {
+	if identity.Role == "admin" {
+		return nil
+	}
+
+	workerHashes, err := resolveWorkerHashes(ctx, workerIDHash)
+	if err != nil {
+		return err
+	}
+	for _, hash := range workerHashes {
+		consents, err := queryActiveConsents(ctx, hash)
+		if err != nil {
+			return err
+		}
+		for _, consent := range consents {
+			if consent.Grantee == identity.MSPID {
+				return nil
+			}
+		}
+	}
+
+	return &AccessDeniedError{
+		Reason:     fmt.Sprintf("Worker has not granted consent to %s", identity.MSPID),
+		UserID:     identity.ID,
+		Function:   function,
+		RequiredBy: "Worker consent",
+	}
+}
+
+// GrantConsent lets the calling worker allow an organisation (grantee, an MSP ID) to read
+// their income data for a purpose, until expiresAt (RFC3339, optional) or until revoked.
+// Returns the consent ID. Emits the "ConsentGranted" event with the Consent as payload.
+// SECURITY: Workers only, and only for themselves (identified by the idHash attribute).
+func (s *SmartContract) GrantConsent(ctx contractapi.TransactionContextInterface, grantee string, purpose string, expiresAt string) (string, error) {
+	grantee = strings.TrimSpace(grantee)
+
+	workerIDHash, err := callerWorkerHash(ctx, "GrantConsent")
+	if err != nil {
+		s.LogAccessDenied(ctx, "GrantConsent", grantee, "consent", err.Error())
+		return "", fmt.Errorf("access denied: %w", err)
+	}
+
+	if grantee == "" {
+		return "", fmt.Errorf("grantee is required")
+	}
+
+	txTimestamp, err := ctx.GetStub().GetTxTimestamp()
+	if err != nil {
+		return "", fmt.Errorf("get tx timestamp: %w", err)
+	}
+	txTime := txTimestamp.AsTime().UTC()
+
+	if expiresAt != "" {
+		expiry, err := time.Parse(time.RFC3339, expiresAt)
+		if err != nil {
+			return "", fmt.Errorf("invalid expiresAt: %w", err)
+		}
+		if !expiry.After(txTime) {
+			return "", fmt.Errorf("expiresAt %s is not in the future", expiresAt)
+		}
+		expiresAt = expiry.UTC().Format(time.RFC3339)
+	}
+
+	consent := Consent{
+		DocType:      "consent",
+		ConsentID:    generateDeterministicID(ctx, txTime.Format("20060102150405")),
+		WorkerIDHash: workerIDHash,
+		Grantee:      grantee,
+		Purpose:      strings.TrimSpace(purpose),
+		GrantedAt:    txTime.Format(time.RFC3339),
+		ExpiresAt:    expiresAt,
+	}
+
+	payload, err := marshalState(consent)
+	if err != nil {
+		return "", fmt.Errorf("marshal consent: %w", err)
+	}
+	if err := ctx.GetStub().PutState(consentKey(workerIDHash, consent.ConsentID), payload); err != nil {
+		return "", fmt.Errorf("put state: %w", err)
+	}
+
+	s.LogDataWrite(ctx, "GrantConsent", consent.ConsentID, "consent", fmt.Sprintf("grantee: %s", grantee))
+
+	if err := ctx.GetStub().SetEvent("ConsentGranted", payload); err != nil {
+		fmt.Printf("warning: failed to emit event: %v\n", err)
+	}
+
+	return consent.ConsentID, nil
+}
+
+// RevokeConsent withdraws one of the caller's consents. Revoking an already revoked
+// consent is a no-op. Emits the "ConsentRevoked" event with the Consent as payload.
+// SECURITY: Workers only, and only their own consents.
+func (s *SmartContract) RevokeConsent(ctx contractapi.TransactionContextInterface, consentID string) error {
+	if consentID == "" {
+		return fmt.Errorf("consentID is required")
+	}
+
+	workerIDHash, err := callerWorkerHash(ctx, "RevokeConsent")
+	if err != nil {
+		s.LogAccessDenied(ctx, "RevokeConsent", consentID, "consent", err.Error())
+		return fmt.Errorf("access denied: %w", err)
+	}
+
+	key := consentKey(workerIDHash, consentID)
+	payload, err := ctx.GetStub().GetState(key)
+	if err != nil {
+		return fmt.Errorf("get state: %w", err)
+	}
+	if payload == nil {
+		return fmt.Errorf("consent %s not found", consentID)
+	}
+
+	var consent Consent
+	if err := json.Unmarshal(payload, &consent); err != nil {
+		return fmt.Errorf("unmarshal consent: %w", err)
+	}
+	if consent.RevokedAt != "" {
+		return nil
+	}
+	consent.RevokedAt = GetTxTimestampRFC3339(ctx)
+
+	updated, err := marshalState(consent)
+	if err != nil {
+		return fmt.Errorf("marshal consent: %w", err)
+	}
+	if err := ctx.GetStub().PutState(key, updated); err != nil {
+		return fmt.Errorf("put state: %w", err)
+	}
+
+	s.LogDataWrite(ctx, "RevokeConsent", consentID, "consent", fmt.Sprintf("grantee: %s", consent.Grantee))
+
+	if err := ctx.GetStub().SetEvent("ConsentRevoked", updated); err != nil {
+		fmt.Printf("warning: failed to emit event: %v\n", err)
+	}
+
+	return nil
+}
+
+// GetMyConsents returns the consents the caller has granted that are still in force
+// (neither revoked nor expired), oldest first.
+// SECURITY: Workers only, and only their own consents (identified by the idHash attribute).
+func (s *SmartContract) GetMyConsents(ctx contractapi.TransactionContextInterface) ([]*Consent, error) {
+	workerIDHash, err := callerWorkerHash(ctx, "GetMyConsents")
+	if err != nil {
+		s.LogAccessDenied(ctx, "GetMyConsents", "", "consent", err.Error())
+		return nil, fmt.Errorf("access denied: %w", err)
+	}
+
+	return queryActiveConsents(ctx, workerIDHash)
+}
+
+// GetGrantedConsentsForWorker returns the consents a worker has granted that are still in
+// force, oldest first.
+// SECURITY: Only government officials, auditors, and admins.
+func (s *SmartContract) GetGrantedConsentsForWorker(ctx contractapi.TransactionContextInterface, workerIDHash string) ([]*Consent, error) {
+	if workerIDHash == "" {
+		return nil, fmt.Errorf("workerIDHash is required")
+	}
+
+	// IAM Check
+	if IAMEnabled {
+		_, err := CheckAccess(ctx, "GetGrantedConsentsForWorker")
+		if err != nil {
+			s.LogAccessDenied(ctx, "GetGrantedConsentsForWorker", workerIDHash, "consent", err.Error())
+			return nil, fmt.Errorf("access denied: %w", err)
+		}
+		s.LogDataRead(ctx, "GetGrantedConsentsForWorker", workerIDHash, "consent")
+	}
+
+	return queryActiveConsents(ctx, workerIDHash)
+}
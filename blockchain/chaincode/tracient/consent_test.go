@@ -0,0 +1,85 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestConsentLifecycle(t *testing.T) {
+	worker := newMockContext("Org2MSP", "role=worker", "idHash=worker-1")
+	s := &SmartContract{}
+
+	if _, err := s.GrantConsent(worker, "Org1MSP", "loan", "2025-11-30T00:00:00Z"); err == nil {
+		t.Fatal("an expiry in the past must be rejected")
+	}
+	expired := consentKey("worker-1", "OLD")
+	worker.stub.state[expired] = []byte(`{"docType":"consent","consentId":"OLD","workerIdHash":"worker-1","grantee":"Org3MSP","grantedAt":"2025-01-01T00:00:00Z","expiresAt":"2025-06-01T00:00:00Z"}`)
+
+	consentID, err := s.GrantConsent(worker, "Org1MSP", "loan", "")
+	if err != nil {
+		t.Fatalf("GrantConsent: %v", err)
+	}
+	if _, ok := worker.stub.events["ConsentGranted"]; !ok {
+		t.Fatal("expected a ConsentGranted event")
+	}
+
+	consents, err := s.GetMyConsents(worker)
+	if err != nil {
+		t.Fatalf("GetMyConsents: %v", err)
+	}
+	if len(consents) != 1 || consents[0].ConsentID != consentID || consents[0].Grantee != "Org1MSP" {
+		t.Fatalf("expected only the active consent, got %+v", consents)
+	}
+
+	official := newMockContext("Org1MSP", "role=government_official")
+	official.stub = worker.stub
+	if granted, err := s.GetGrantedConsentsForWorker(official, "worker-1"); err != nil || len(granted) != 1 {
+		t.Fatalf("GetGrantedConsentsForWorker = %+v, %v", granted, err)
+	}
+
+	other := newMockContext("Org2MSP", "role=worker", "idHash=worker-2")
+	other.stub = worker.stub
+	if err := s.RevokeConsent(other, consentID); err == nil {
+		t.Fatal("another worker must not find this consent")
+	}
+	if _, err := s.GetGrantedConsentsForWorker(other, "worker-1"); err == nil || !strings.Contains(err.Error(), "access denied") {
+		t.Fatalf("workers must not list another worker's consents, got %v", err)
+	}
+
+	if err := s.RevokeConsent(worker, consentID); err != nil {
+		t.Fatalf("RevokeConsent: %v", err)
+	}
+	if consents, err := s.GetMyConsents(worker); err != nil || len(consents) != 0 {
+		t.Fatalf("expected no active consents after revoking, got %+v, %v", consents, err)
+	}
+	if err := s.RevokeConsent(worker, consentID); err != nil {
+		t.Fatalf("revoking twice should be a no-op: %v", err)
+	}
+}
+
+func TestGetWorkerCreditProfileRequiresConsent(t *testing.T) {
+	bank := newMockContext("Org1MSP", "role=bank_officer", "clearanceLevel=5")
+	bank.stub.state["WAGE001"] = []byte(`{"docType":"wage","wageId":"WAGE001","workerIdHash":"worker-1","amount":100,"currency":"INR","timestamp":"2025-11-01T10:00:00Z"}`)
+	s := &SmartContract{}
+
+	if _, err := s.GetWorkerCreditProfile(bank, "worker-1"); err == nil || !strings.Contains(err.Error(), "consent") {
+		t.Fatalf("expected a read without consent to be denied, got %v", err)
+	}
+
+	worker := newMockContext("Org2MSP", "role=worker", "idHash=worker-1")
+	worker.stub = bank.stub
+	if _, err := s.GrantConsent(worker, "Org2MSP", "loan", ""); err != nil {
+		t.Fatalf("GrantConsent: %v", err)
+	}
+	if _, err := s.GetWorkerCreditProfile(bank, "worker-1"); err == nil {
+		t.Fatal("a consent to another organisation must not admit this bank")
+	}
+
+	worker.stub.txID = "mocktx9876543210"
+	if _, err := s.GrantConsent(worker, "Org1MSP", "loan", ""); err != nil {
+		t.Fatalf("GrantConsent: %v", err)
+	}
+	if _, err := s.GetWorkerCreditProfile(bank, "worker-1"); err != nil {
+		t.Fatalf("GetWorkerCreditProfile with consent: %v", err)
+	}
+}
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/hyperledger/fabric-contract-api-go/v2/contractapi"
+)
+
+// ============================================================================
+// TRANSACTION CONTEXT
+// ============================================================================
+
+// txState is bookkeeping that must last exactly one invocation. contractapi creates a new
+// transaction context for every invocation, so keeping it there means the keys and entries
+// a transaction writes depend only on that transaction, never on what else the peer
+// process has executed (re-simulations, concurrent or failed transactions).
+type txState struct {
+	idSequence int  // IDs minted so far by generateDeterministicID
+	audited    bool // LogAccess stored an entry, so afterTransaction need not add one
+}
+
+// txStateCarrier is implemented by transaction contexts that carry a txState
+type txStateCarrier interface {
+	transactionState() *txState
+}
+
+// TransactionContext is the contract's transaction context: the contractapi default plus
+// the per-invocation txState. main registers it as SmartContract.TransactionContextHandler.
+type TransactionContext struct {
+	contractapi.TransactionContext
+	state txState
+}
+
+func (c *TransactionContext) transactionState() *txState {
+	return &c.state
+}
+
+// txStateOf returns the per-invocation state of ctx. A context without one means the
+// contract was started without TransactionContext, which would make generated keys
+// non-deterministic, so it panics (recovered as a failed transaction) instead.
+func txStateOf(ctx contractapi.TransactionContextInterface) *txState {
+	carrier, ok := ctx.(txStateCarrier)
+	if !ok {
+		panic(fmt.Sprintf("transaction context %T carries no per-transaction state; register TransactionContext as the TransactionContextHandler", ctx))
+	}
+	return carrier.transactionState()
+}
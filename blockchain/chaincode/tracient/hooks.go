@@ -0,0 +1,86 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/hyperledger/fabric-contract-api-go/v2/contractapi"
+)
+
+// ============================================================================
+// TRANSACTION HOOKS
+// ============================================================================
+//
+// contractapi runs beforeTransaction ahead of every transaction function and
+// afterTransaction once it has succeeded. Together they guarantee that each invocation
+// passes CheckAccess for its own name and leaves at least one audit entry, even if the
+// function forgets to do either. Functions still run their own finer-grained checks
+// (self-access, state scope, employer identity) and their own, more specific, logging.
+//
+// Opting out:
+//   - publicTransactions skip both hooks. Use it only for functions that check the caller
+//     identity themselves and never write state (e.g. GetHealth).
+//   - unauditedTransactions are access-checked but not auto-audited, because Fabric forbids
+//     state writes in a transaction that uses pagination.
+//
+// A transaction function without an access rule that is in neither list is denied, which
+// also keeps the exported audit helpers (LogAccess etc.) from being invoked by clients.
+
+// publicTransactions require only a valid client identity, checked by the function itself
+var publicTransactions = map[string]bool{
+	"GetHealth":     true,
+	"ListFunctions": true,
+	"GetSchemas":    true,
+}
+
+// unauditedTransactions use paginated queries and so must not write audit entries
+var unauditedTransactions = map[string]bool{
+	"QueryWagesByCurrencyPaginated": true,
+	"QueryWagesByState":             true,
+	"QueryWagesByEmployerPaginated": true,
+	"GetAccessDenialsPage":          true,
+}
+
+// GetBeforeTransaction registers beforeTransaction with contractapi
+func (s *SmartContract) GetBeforeTransaction() interface{} {
+	return s.beforeTransaction
+}
+
+// GetAfterTransaction registers afterTransaction with contractapi
+func (s *SmartContract) GetAfterTransaction() interface{} {
+	return s.afterTransaction
+}
+
+// transactionName returns the invoked function name without any contract namespace
+func transactionName(ctx contractapi.TransactionContextInterface) string {
+	function, _ := ctx.GetStub().GetFunctionAndParameters()
+	if i := strings.LastIndex(function, ":"); i >= 0 {
+		function = function[i+1:]
+	}
+	return function
+}
+
+// beforeTransaction denies the invocation unless the caller passes the access rule of the
+// invoked function.
+func (s *SmartContract) beforeTransaction(ctx contractapi.TransactionContextInterface) error {
+	function := transactionName(ctx)
+	if !IAMEnabled || publicTransactions[function] {
+		return nil
+	}
+
+	if _, err := CheckAccess(ctx, function); err != nil {
+		s.LogAccessDenied(ctx, function, "", "transaction", err.Error())
+		return fmt.Errorf("access denied: %w", err)
+	}
+	return nil
+}
+
+// afterTransaction logs an ACCESS_GRANTED entry for a successful invocation that did not
+// write any audit entry of its own.
+func (s *SmartContract) afterTransaction(ctx contractapi.TransactionContextInterface) error {
+	function := transactionName(ctx)
+	if !IAMEnabled || publicTransactions[function] || unauditedTransactions[function] || txStateOf(ctx).audited {
+		return nil
+	}
+	return s.LogAccessGranted(ctx, function, "", "transaction")
+}
@@ -0,0 +1,260 @@
+package main
+
+import (
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/hyperledger/fabric-chaincode-go/v2/pkg/cid"
+	"github.com/hyperledger/fabric-chaincode-go/v2/shim"
+	"github.com/hyperledger/fabric-protos-go-apiv2/ledger/queryresult"
+	"github.com/hyperledger/fabric-protos-go-apiv2/peer"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// ============================================================================
+// MOCK TRANSACTION CONTEXT
+// ============================================================================
+
+// mockStub is an in-memory world state. It embeds the stub interface so only the
+// methods the tests exercise need implementing; calling any other method panics.
+type mockStub struct {
+	shim.ChaincodeStubInterface
+	txID     string
+	txTime   time.Time
+	function string
+	state    map[string][]byte
+	events   map[string][]byte
+}
+
+func newMockStub() *mockStub {
+	return &mockStub{
+		txID:   "mocktx0123456789",
+		txTime: time.Date(2025, 12, 1, 10, 0, 0, 0, time.UTC),
+		state:  make(map[string][]byte),
+		events: make(map[string][]byte),
+	}
+}
+
+func (m *mockStub) GetFunctionAndParameters() (string, []string) {
+	return m.function, nil
+}
+
+func (m *mockStub) GetTxID() string {
+	return m.txID
+}
+
+func (m *mockStub) GetTxTimestamp() (*timestamppb.Timestamp, error) {
+	return timestamppb.New(m.txTime), nil
+}
+
+func (m *mockStub) GetState(key string) ([]byte, error) {
+	return m.state[key], nil
+}
+
+func (m *mockStub) PutState(key string, value []byte) error {
+	if key == "" {
+		return fmt.Errorf("key must not be empty")
+	}
+	m.state[key] = value
+	return nil
+}
+
+func (m *mockStub) DelState(key string) error {
+	delete(m.state, key)
+	return nil
+}
+
+func (m *mockStub) CreateCompositeKey(objectType string, attributes []string) (string, error) {
+	// Same layout as the Fabric shim: U+0000 objectType U+0000 attr U+0000 ...
+	key := "\x00" + objectType + "\x00"
+	for _, attr := range attributes {
+		key += attr + "\x00"
+	}
+	return key, nil
+}
+
+func (m *mockStub) SplitCompositeKey(compositeKey string) (string, []string, error) {
+	parts := strings.Split(strings.TrimPrefix(compositeKey, "\x00"), "\x00")
+	if len(parts) < 2 || parts[len(parts)-1] != "" {
+		return "", nil, fmt.Errorf("invalid composite key %q", compositeKey)
+	}
+	return parts[0], parts[1 : len(parts)-1], nil
+}
+
+// GetStateByRange iterates simple keys in [startKey, endKey) in key order; an empty
+// endKey is unbounded and composite keys are excluded, as on a peer.
+func (m *mockStub) GetStateByRange(startKey string, endKey string) (shim.StateQueryIteratorInterface, error) {
+	return m.iterate(func(key string) bool {
+		return !strings.HasPrefix(key, "\x00") && key >= startKey && (endKey == "" || key < endKey)
+	}), nil
+}
+
+// GetStateByPartialCompositeKey iterates the composite keys starting with the given prefix
+func (m *mockStub) GetStateByPartialCompositeKey(objectType string, attributes []string) (shim.StateQueryIteratorInterface, error) {
+	prefix, _ := m.CreateCompositeKey(objectType, attributes)
+	return m.iterate(func(key string) bool { return strings.HasPrefix(key, prefix) }), nil
+}
+
+// GetStateByPartialCompositeKeyWithPagination returns up to pageSize composite keys with the
+// given prefix. The bookmark is the last key of the previous page.
+func (m *mockStub) GetStateByPartialCompositeKeyWithPagination(objectType string, keys []string, pageSize int32, bookmark string) (shim.StateQueryIteratorInterface, *peer.QueryResponseMetadata, error) {
+	prefix, _ := m.CreateCompositeKey(objectType, keys)
+	it := m.iterate(func(key string) bool { return strings.HasPrefix(key, prefix) && key > bookmark })
+	if int32(len(it.results)) > pageSize {
+		it.results = it.results[:pageSize]
+	}
+	metadata := &peer.QueryResponseMetadata{FetchedRecordsCount: int32(len(it.results))}
+	if len(it.results) > 0 {
+		metadata.Bookmark = it.results[len(it.results)-1].Key
+	}
+	return it, metadata, nil
+}
+
+func (m *mockStub) iterate(match func(key string) bool) *mockIterator {
+	var keys []string
+	for key := range m.state {
+		if match(key) {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	results := make([]*queryresult.KV, 0, len(keys))
+	for _, key := range keys {
+		results = append(results, &queryresult.KV{Key: key, Value: m.state[key]})
+	}
+	return &mockIterator{results: results}
+}
+
+// mockIterator is an in-memory state query iterator
+type mockIterator struct {
+	results []*queryresult.KV
+}
+
+func (it *mockIterator) HasNext() bool {
+	return len(it.results) > 0
+}
+
+func (it *mockIterator) Next() (*queryresult.KV, error) {
+	if len(it.results) == 0 {
+		return nil, fmt.Errorf("iterator exhausted")
+	}
+	next := it.results[0]
+	it.results = it.results[1:]
+	return next, nil
+}
+
+func (it *mockIterator) Close() error {
+	return nil
+}
+
+func (m *mockStub) SetEvent(name string, payload []byte) error {
+	m.events[name] = payload
+	return nil
+}
+
+// mockClientIdentity is a certificate-free client identity.
+type mockClientIdentity struct {
+	commonName string
+	ous        []string
+	mspID      string
+	attrs      map[string]string
+	err        error // returned by every method when set
+}
+
+// GetID mimics cid: base64 of "x509::<subject DN>::<issuer DN>"
+func (m *mockClientIdentity) GetID() (string, error) {
+	if m.err != nil {
+		return "", m.err
+	}
+	subject := "CN=" + m.commonName
+	for _, ou := range m.ous {
+		subject += ",OU=" + ou
+	}
+	subject += ",L=San Francisco,ST=California,C=US"
+	id := fmt.Sprintf("x509::%s::CN=ca.org1.example.com,O=org1.example.com,L=Durham,ST=North Carolina,C=US", subject)
+	return base64.StdEncoding.EncodeToString([]byte(id)), nil
+}
+
+func (m *mockClientIdentity) GetMSPID() (string, error) {
+	if m.err != nil {
+		return "", m.err
+	}
+	return m.mspID, nil
+}
+
+func (m *mockClientIdentity) GetAttributeValue(attrName string) (string, bool, error) {
+	if m.err != nil {
+		return "", false, m.err
+	}
+	value, found := m.attrs[attrName]
+	return value, found, nil
+}
+
+func (m *mockClientIdentity) AssertAttributeValue(attrName, attrValue string) error {
+	value, found, err := m.GetAttributeValue(attrName)
+	if err != nil {
+		return err
+	}
+	if !found || value != attrValue {
+		return fmt.Errorf("attribute %s does not equal %s", attrName, attrValue)
+	}
+	return nil
+}
+
+func (m *mockClientIdentity) GetX509Certificate() (*x509.Certificate, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+	return &x509.Certificate{Subject: pkix.Name{CommonName: m.commonName, OrganizationalUnit: m.ous}}, nil
+}
+
+var _ cid.ClientIdentity = (*mockClientIdentity)(nil)
+
+// mockTransactionContext satisfies contractapi.TransactionContextInterface.
+type mockTransactionContext struct {
+	stub     *mockStub
+	identity *mockClientIdentity
+	state    txState
+}
+
+func (m *mockTransactionContext) transactionState() *txState {
+	return &m.state
+}
+
+func (m *mockTransactionContext) GetStub() shim.ChaincodeStubInterface {
+	return m.stub
+}
+
+func (m *mockTransactionContext) GetClientIdentity() cid.ClientIdentity {
+	return m.identity
+}
+
+// newMockContext builds a context for a client certificate with the given MSP and
+// attributes, e.g. newMockContext("Org1MSP", "role=employer", "clearanceLevel=6").
+func newMockContext(mspID string, attrs ...string) *mockTransactionContext {
+	identity := &mockClientIdentity{
+		commonName: "user1",
+		ous:        []string{"client", "org1"},
+		mspID:      mspID,
+		attrs:      make(map[string]string),
+	}
+	for _, attr := range attrs {
+		name, value, _ := strings.Cut(attr, "=")
+		identity.attrs[name] = value
+	}
+	return &mockTransactionContext{stub: newMockStub(), identity: identity}
+}
+
+// newMockAdminContext builds a context for a default Fabric admin certificate
+// (OU=admin, no role attribute).
+func newMockAdminContext(mspID string) *mockTransactionContext {
+	ctx := newMockContext(mspID)
+	ctx.identity.commonName = "Admin@org1.example.com"
+	ctx.identity.ous = []string{"admin"}
+	return ctx
+}
@@ -0,0 +1,164 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/v2/contractapi"
+)
+
+// ============================================================================
+// NOTIFICATION DATA STRUCTURES
+// ============================================================================
+
+// Notification is a pull-based inbox entry telling a worker that something was recorded
+// for them. It is stored under NOTIFY_<workerIDHash>_<notificationID>, so a worker app can
+// poll its inbox without running a chaincode event listener.
+type Notification struct {
+	DocType        string `json:"docType"`
+	NotificationID string `json:"notificationId"`
+	WorkerIDHash   string `json:"workerIdHash"`
+	Type           string `json:"type"` // WAGE_RECORDED, UPI_RECORDED
+	Ref            string `json:"ref"`  // wageID or UPI txID
+	Timestamp      string `json:"timestamp"`
+	Read           bool   `json:"read"`
+}
+
+// Notification types
+const (
+	NotificationWageRecorded = "WAGE_RECORDED"
+	NotificationUPIRecorded  = "UPI_RECORDED"
+)
+
+// notificationKey returns the ledger key of a worker's notification
+func notificationKey(workerIDHash string, notificationID string) string {
+	return fmt.Sprintf("NOTIFY_%s_%s", workerIDHash, notificationID)
+}
+
+// ============================================================================
+// NOTIFICATION FUNCTIONS
+// ============================================================================
+
+// putNotification queues a notification for a worker. Notification IDs start with the
+// transaction timestamp, so a worker's inbox range scan returns entries oldest first.
+func putNotification(ctx contractapi.TransactionContextInterface, workerIDHash string, notificationType string, ref string) error {
+	txTimestamp, err := ctx.GetStub().GetTxTimestamp()
+	if err != nil {
+		return fmt.Errorf("get tx timestamp: %w", err)
+	}
+	txTime := txTimestamp.AsTime().UTC()
+
+	notification := Notification{
+		DocType:        "notification",
+		NotificationID: generateDeterministicID(ctx, txTime.Format("20060102150405")),
+		WorkerIDHash:   workerIDHash,
+		Type:           notificationType,
+		Ref:            ref,
+		Timestamp:      txTime.Format(time.RFC3339),
+	}
+
+	payload, err := marshalState(notification)
+	if err != nil {
+		return fmt.Errorf("marshal notification: %w", err)
+	}
+	if err := ctx.GetStub().PutState(notificationKey(workerIDHash, notification.NotificationID), payload); err != nil {
+		return fmt.Errorf("put notification: %w", err)
+	}
+	return nil
+}
+
+// callerWorkerHash returns the idHash attribute of the calling worker, which identifies
+// their inbox and consents. Access is checked even when IAM is disabled, since both are
+// defined by the caller's identity.
+func callerWorkerHash(ctx contractapi.TransactionContextInterface, function string) (string, error) {
+	identity, err := CheckAccess(ctx, function)
+	if err != nil {
+		return "", err
+	}
+	idHash := identity.Attributes["idHash"]
+	if idHash == "" {
+		return "", &AccessDeniedError{
+			Reason:     "Certificate has no idHash attribute to identify the worker",
+			UserID:     identity.ID,
+			Function:   function,
+			RequiredBy: "Self-access only",
+		}
+	}
+	return idHash, nil
+}
+
+// GetMyNotifications returns the caller's notifications, oldest first.
+// SECURITY: Workers only, and only their own inbox (identified by the idHash attribute).
+func (s *SmartContract) GetMyNotifications(ctx contractapi.TransactionContextInterface) ([]*Notification, error) {
+	workerIDHash, err := callerWorkerHash(ctx, "GetMyNotifications")
+	if err != nil {
+		s.LogAccessDenied(ctx, "GetMyNotifications", "", "notification", err.Error())
+		return nil, fmt.Errorf("access denied: %w", err)
+	}
+
+	prefix := notificationKey(workerIDHash, "")
+	iterator, err := ctx.GetStub().GetStateByRange(prefix, prefix+"~")
+	if err != nil {
+		return nil, fmt.Errorf("get state range: %w", err)
+	}
+	defer iterator.Close()
+
+	notifications := []*Notification{}
+	for iterator.HasNext() {
+		queryResponse, err := iterator.Next()
+		if err != nil {
+			return nil, fmt.Errorf("iterate: %w", err)
+		}
+
+		var notification Notification
+		if err := json.Unmarshal(queryResponse.Value, &notification); err != nil || notification.DocType != "notification" {
+			continue
+		}
+		// Another worker's hash may share this one as a prefix
+		if notification.WorkerIDHash != workerIDHash {
+			continue
+		}
+		notifications = append(notifications, &notification)
+	}
+
+	return notifications, nil
+}
+
+// MarkNotificationRead marks one of the caller's notifications as read.
+// SECURITY: Workers only, and only notifications in their own inbox.
+func (s *SmartContract) MarkNotificationRead(ctx contractapi.TransactionContextInterface, notificationID string) error {
+	if notificationID == "" {
+		return fmt.Errorf("notificationID is required")
+	}
+
+	workerIDHash, err := callerWorkerHash(ctx, "MarkNotificationRead")
+	if err != nil {
+		s.LogAccessDenied(ctx, "MarkNotificationRead", notificationID, "notification", err.Error())
+		return fmt.Errorf("access denied: %w", err)
+	}
+
+	key := notificationKey(workerIDHash, notificationID)
+	payload, err := ctx.GetStub().GetState(key)
+	if err != nil {
+		return fmt.Errorf("get state: %w", err)
+	}
+	if payload == nil {
+		return fmt.Errorf("notification %s not found", notificationID)
+	}
+
+	var notification Notification
+	if err := json.Unmarshal(payload, &notification); err != nil {
+		return fmt.Errorf("unmarshal notification: %w", err)
+	}
+	if notification.Read {
+		return nil
+	}
+	notification.Read = true
+
+	updated, err := marshalState(notification)
+	if err != nil {
+		return fmt.Errorf("marshal notification: %w", err)
+	}
+	return ctx.GetStub().PutState(key, updated)
+}
@@ -0,0 +1,49 @@
+package main
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestMarkNotificationReadOwnInboxOnly(t *testing.T) {
+	ctx := newMockContext("Org2MSP", "role=worker", "idHash=worker-1")
+	if err := putNotification(ctx, "worker-1", NotificationWageRecorded, "WAGE001"); err != nil {
+		t.Fatalf("putNotification: %v", err)
+	}
+
+	var key string
+	for k := range ctx.stub.state {
+		if strings.HasPrefix(k, "NOTIFY_worker-1_") {
+			key = k
+		}
+	}
+	if key == "" {
+		t.Fatal("notification not stored")
+	}
+	notificationID := strings.TrimPrefix(key, "NOTIFY_worker-1_")
+
+	s := &SmartContract{}
+	other := newMockContext("Org2MSP", "role=worker", "idHash=worker-2")
+	other.stub = ctx.stub
+	if err := s.MarkNotificationRead(other, notificationID); err == nil {
+		t.Fatal("another worker must not find this notification")
+	}
+
+	if err := s.MarkNotificationRead(ctx, notificationID); err != nil {
+		t.Fatalf("MarkNotificationRead: %v", err)
+	}
+	var notification Notification
+	if err := json.Unmarshal(ctx.stub.state[key], &notification); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if !notification.Read || notification.Type != NotificationWageRecorded || notification.Ref != "WAGE001" {
+		t.Fatalf("unexpected notification %+v", notification)
+	}
+
+	noHash := newMockContext("Org2MSP", "role=worker")
+	noHash.stub = ctx.stub
+	if err := s.MarkNotificationRead(noHash, notificationID); err == nil {
+		t.Fatal("worker without idHash must be denied")
+	}
+}
@@ -0,0 +1,233 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math"
+
+	"github.com/hyperledger/fabric-contract-api-go/v2/contractapi"
+)
+
+// ============================================================================
+// PRIVATE WAGE DATA STRUCTURES
+// ============================================================================
+
+// PrivateWageTransientKey is the transient map key RecordWagePrivate reads the wage from
+const PrivateWageTransientKey = "wage"
+
+// PrivateWageRecord holds the sensitive wage fields kept off the public ledger.
+type PrivateWageRecord struct {
+	DocType        string  `json:"docType"`
+	WageID         string  `json:"wageId"`
+	WorkerIDHash   string  `json:"workerIdHash"`
+	EmployerIDHash string  `json:"employerIdHash"`
+	Amount         float64 `json:"amount"`
+	Currency       string  `json:"currency"`
+	JobType        string  `json:"jobType,omitempty"`
+	Timestamp      string  `json:"timestamp"`
+	PolicyVersion  string  `json:"policyVersion"`
+}
+
+// PrivateWageReference is the public, non-sensitive stub of a private wage. DataHash is the
+// SHA-256 of the private record bytes, so any org can verify a disclosed record against it.
+type PrivateWageReference struct {
+	DocType    string `json:"docType"`
+	WageID     string `json:"wageId"`
+	Collection string `json:"collection"`
+	DataHash   string `json:"dataHash"`
+	RecordedBy string `json:"recordedBy"`
+	RecordedAt string `json:"recordedAt"`
+}
+
+// privateWageKey returns the public ledger key for a private wage reference.
+// A separate prefix keeps these stubs out of the WAGE range scans.
+func privateWageKey(wageID string) string {
+	return fmt.Sprintf("PRIVWAGE_%s", wageID)
+}
+
+// implicitCollection returns the implicit private data collection of an organization
+func implicitCollection(mspID string) string {
+	return fmt.Sprintf("_implicit_org_%s", mspID)
+}
+
+// ============================================================================
+// PRIVATE WAGE FUNCTIONS
+// ============================================================================
+
+// RecordWagePrivate records a wage whose sensitive fields (worker identity, exact amount)
+// must not be visible to every endorser. The PrivateWageRecord is passed as JSON in the
+// transient map under "wage", stored in the caller organization's implicit private data
+// collection, and only its hash is written to the public ledger.
+// Implicit collections (_implicit_org_<MSPID>) need no entry in collections_config.json,
+// but the proposal must be endorsed by a peer of the caller's own organization, since only
+// that org's peers hold the collection.
+// SECURITY: Only employers and admins with 'canRecordWage' permission.
+func (s *SmartContract) RecordWagePrivate(ctx contractapi.TransactionContextInterface, wageID string) error {
+	if wageID == "" {
+		return fmt.Errorf("wageID is required")
+	}
+
+	// IAM Check
+	recordedBy := "system"
+	if IAMEnabled {
+		identity, err := CheckAccess(ctx, "RecordWagePrivate")
+		if err != nil {
+			s.LogAccessDenied(ctx, "RecordWagePrivate", wageID, "wage", err.Error())
+			return fmt.Errorf("access denied: %w", err)
+		}
+		s.LogAccessGranted(ctx, "RecordWagePrivate", wageID, "wage")
+		fmt.Printf("[IAM] RecordWagePrivate by %s: %s\n", identity.ID, wageID)
+		recordedBy = identity.ID
+	}
+
+	transient, err := ctx.GetStub().GetTransient()
+	if err != nil {
+		return fmt.Errorf("get transient: %w", err)
+	}
+	transientWage, ok := transient[PrivateWageTransientKey]
+	if !ok || len(transientWage) == 0 {
+		return fmt.Errorf("transient field %q is required", PrivateWageTransientKey)
+	}
+
+	var record PrivateWageRecord
+	if err := json.Unmarshal(transientWage, &record); err != nil {
+		return fmt.Errorf("unmarshal private wage: %w", err)
+	}
+	record.DocType = "private_wage"
+	record.WageID = wageID
+
+	if record.WorkerIDHash == "" {
+		return fmt.Errorf("workerIdHash is required")
+	}
+	if record.EmployerIDHash == "" {
+		return fmt.Errorf("employerIdHash is required")
+	}
+	if record.Amount <= 0 || math.IsNaN(record.Amount) || math.IsInf(record.Amount, 0) {
+		return fmt.Errorf("amount must be positive")
+	}
+	if err := ValidateCurrency(ctx, record.Currency); err != nil {
+		return err
+	}
+	if record.Timestamp == "" {
+		record.Timestamp = GetTxTimestampRFC3339(ctx)
+	}
+
+	// wageIDs are shared between public and private wages
+	exists, err := s.WageExists(ctx, wageID)
+	if err != nil {
+		return err
+	}
+	existing, err := ctx.GetStub().GetState(privateWageKey(wageID))
+	if err != nil {
+		return fmt.Errorf("get state: %w", err)
+	}
+	if exists || existing != nil {
+		return fmt.Errorf("wage record %s already exists", wageID)
+	}
+
+	mspID, err := ctx.GetClientIdentity().GetMSPID()
+	if err != nil {
+		return fmt.Errorf("get client MSP ID: %w", err)
+	}
+	collection := implicitCollection(mspID)
+
+	privatePayload, err := marshalState(record)
+	if err != nil {
+		return fmt.Errorf("marshal private wage: %w", err)
+	}
+	if err := ctx.GetStub().PutPrivateData(collection, wageID, privatePayload); err != nil {
+		return fmt.Errorf("put private data: %w", err)
+	}
+
+	digest := sha256.Sum256(privatePayload)
+	reference := PrivateWageReference{
+		DocType:    "private_wage_ref",
+		WageID:     wageID,
+		Collection: collection,
+		DataHash:   hex.EncodeToString(digest[:]),
+		RecordedBy: recordedBy,
+		RecordedAt: GetTxTimestampRFC3339(ctx),
+	}
+	payload, err := marshalState(reference)
+	if err != nil {
+		return fmt.Errorf("marshal private wage reference: %w", err)
+	}
+	if err := ctx.GetStub().PutState(privateWageKey(wageID), payload); err != nil {
+		return fmt.Errorf("put state: %w", err)
+	}
+
+	// Emit event with the public reference only
+	if err := ctx.GetStub().SetEvent("PrivateWageRecorded", []byte(wageID)); err != nil {
+		fmt.Printf("warning: failed to emit event: %v\n", err)
+	}
+
+	return nil
+}
+
+// ReadWagePrivate retrieves a private wage from the collection named in its public reference
+// and verifies it against the on-chain hash. It only succeeds on peers of the organization
+// that owns the collection; other peers do not hold the data.
+// SECURITY: Only callers from the owning organization with an allowed role.
+func (s *SmartContract) ReadWagePrivate(ctx contractapi.TransactionContextInterface, wageID string) (*PrivateWageRecord, error) {
+	if wageID == "" {
+		return nil, fmt.Errorf("wageID is required")
+	}
+
+	// IAM Check
+	if IAMEnabled {
+		_, err := CheckAccess(ctx, "ReadWagePrivate")
+		if err != nil {
+			s.LogAccessDenied(ctx, "ReadWagePrivate", wageID, "wage", err.Error())
+			return nil, fmt.Errorf("access denied: %w", err)
+		}
+	}
+
+	payload, err := ctx.GetStub().GetState(privateWageKey(wageID))
+	if err != nil {
+		return nil, fmt.Errorf("get state: %w", err)
+	}
+	if payload == nil {
+		return nil, fmt.Errorf("private wage record %s not found", wageID)
+	}
+	var reference PrivateWageReference
+	if err := json.Unmarshal(payload, &reference); err != nil {
+		return nil, fmt.Errorf("unmarshal private wage reference: %w", err)
+	}
+
+	// Collection access: the caller must belong to the org that owns the implicit collection
+	mspID, err := ctx.GetClientIdentity().GetMSPID()
+	if err != nil {
+		return nil, fmt.Errorf("get client MSP ID: %w", err)
+	}
+	if implicitCollection(mspID) != reference.Collection {
+		err := fmt.Errorf("private wage %s is held by another organization", wageID)
+		s.LogAccessDenied(ctx, "ReadWagePrivate", wageID, "wage", err.Error())
+		return nil, fmt.Errorf("access denied: %w", err)
+	}
+
+	privatePayload, err := ctx.GetStub().GetPrivateData(reference.Collection, wageID)
+	if err != nil {
+		return nil, fmt.Errorf("get private data: %w", err)
+	}
+	if privatePayload == nil {
+		return nil, fmt.Errorf("private wage %s is not available on this peer", wageID)
+	}
+
+	digest := sha256.Sum256(privatePayload)
+	if hex.EncodeToString(digest[:]) != reference.DataHash {
+		return nil, fmt.Errorf("private wage %s does not match its on-chain hash", wageID)
+	}
+
+	record := new(PrivateWageRecord)
+	if err := json.Unmarshal(privatePayload, record); err != nil {
+		return nil, fmt.Errorf("unmarshal private wage: %w", err)
+	}
+
+	if IAMEnabled {
+		s.LogDataRead(ctx, "ReadWagePrivate", wageID, "wage")
+	}
+
+	return record, nil
+}
@@ -0,0 +1,169 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+
+	"github.com/hyperledger/fabric-contract-api-go/v2/contractapi"
+)
+
+// ============================================================================
+// FIELD PROJECTION
+// ============================================================================
+//
+// The *Fields variants of the list queries return each record reduced to the requested
+// JSON fields (e.g. ["amount","timestamp"]), to keep responses small for mobile clients.
+// Each has its own access rule and audit name, runs the query helper of the full read and
+// projects the result. Field names are the JSON names of GetSchemas; unknown names are
+// rejected. An empty list returns every field. Empty optional (omitempty) fields are left out, as in full reads.
+
+// jsonFieldNames returns the JSON names of a struct type's exported fields
+func jsonFieldNames(t reflect.Type) map[string]bool {
+	names := make(map[string]bool, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		if name, _, ok := jsonField(t.Field(i)); ok {
+			names[name] = true
+		}
+	}
+	return names
+}
+
+// validateProjection rejects field names that are not JSON fields of the record type
+func validateProjection(recordType reflect.Type, fields []string) error {
+	known := jsonFieldNames(recordType)
+	for _, field := range fields {
+		if !known[field] {
+			return fmt.Errorf("unknown field %q for %s", field, recordType.Name())
+		}
+	}
+	return nil
+}
+
+// projectRecords reduces each record (a slice of struct pointers) to the requested fields.
+// The field names must already be validated.
+func projectRecords(records interface{}, fields []string) ([]map[string]interface{}, error) {
+	values := reflect.ValueOf(records)
+	projected := make([]map[string]interface{}, 0, values.Len())
+	for i := 0; i < values.Len(); i++ {
+		payload, err := json.Marshal(values.Index(i).Interface())
+		if err != nil {
+			return nil, fmt.Errorf("marshal record: %w", err)
+		}
+		var full map[string]interface{}
+		if err := json.Unmarshal(payload, &full); err != nil {
+			return nil, fmt.Errorf("unmarshal record: %w", err)
+		}
+		if len(fields) == 0 {
+			projected = append(projected, full)
+			continue
+		}
+
+		record := make(map[string]interface{}, len(fields))
+		for _, field := range fields {
+			if value, ok := full[field]; ok {
+				record[field] = value
+			}
+		}
+		projected = append(projected, record)
+	}
+	return projected, nil
+}
+
+// QueryWagesByWorkerFields is QueryWagesByWorker with field projection.
+// SECURITY: Same as QueryWagesByWorker, audited as QueryWagesByWorkerFields.
+func (s *SmartContract) QueryWagesByWorkerFields(ctx contractapi.TransactionContextInterface, workerIDHash string, fields []string) ([]map[string]interface{}, error) {
+	if workerIDHash == "" {
+		return nil, fmt.Errorf("workerIDHash is required")
+	}
+	if err := validateProjection(reflect.TypeOf(WageRecord{}), fields); err != nil {
+		return nil, err
+	}
+
+	// IAM Check with self-access validation
+	if IAMEnabled {
+		identity, err := CheckAccess(ctx, "QueryWagesByWorkerFields")
+		if err != nil {
+			s.LogAccessDenied(ctx, "QueryWagesByWorkerFields", workerIDHash, "wage", err.Error())
+			return nil, fmt.Errorf("access denied: %w", err)
+		}
+		if err := CheckSelfAccess(ctx, identity, "QueryWagesByWorkerFields", workerIDHash); err != nil {
+			s.LogAccessDenied(ctx, "QueryWagesByWorkerFields", workerIDHash, "wage", err.Error())
+			return nil, fmt.Errorf("access denied: %w", err)
+		}
+		s.LogDataRead(ctx, "QueryWagesByWorkerFields", workerIDHash, "wage")
+	}
+
+	wages, err := queryWagesForWorkers(ctx, []string{workerIDHash})
+	if err != nil {
+		return nil, err
+	}
+	return projectRecords(wages, fields)
+}
+
+// QueryWagesByEmployerFields is QueryWagesByEmployer with field projection.
+// SECURITY: Same as QueryWagesByEmployer, audited as QueryWagesByEmployerFields.
+func (s *SmartContract) QueryWagesByEmployerFields(ctx contractapi.TransactionContextInterface, employerIDHash string, fields []string) ([]map[string]interface{}, error) {
+	if employerIDHash == "" {
+		return nil, fmt.Errorf("employerIDHash is required")
+	}
+	if err := validateProjection(reflect.TypeOf(WageRecord{}), fields); err != nil {
+		return nil, err
+	}
+
+	// IAM Check with self-access validation
+	if IAMEnabled {
+		identity, err := CheckAccess(ctx, "QueryWagesByEmployerFields")
+		if err != nil {
+			s.LogAccessDenied(ctx, "QueryWagesByEmployerFields", employerIDHash, "wage", err.Error())
+			return nil, fmt.Errorf("access denied: %w", err)
+		}
+		if err := CheckSelfAccess(ctx, identity, "QueryWagesByEmployerFields", employerIDHash); err != nil {
+			s.LogAccessDenied(ctx, "QueryWagesByEmployerFields", employerIDHash, "wage", err.Error())
+			return nil, fmt.Errorf("access denied: %w", err)
+		}
+	}
+
+	wages, err := queryWagesForEmployer(ctx, employerIDHash)
+	if err != nil {
+		return nil, err
+	}
+
+	if IAMEnabled {
+		s.LogAccess(ctx, EventDataRead, "QueryWagesByEmployerFields", employerIDHash, "wage", "success", fmt.Sprintf("Data read: %d wages", len(wages)))
+	}
+
+	return projectRecords(wages, fields)
+}
+
+// QueryUPITransactionsByWorkerFields is QueryUPITransactionsByWorker with field projection.
+// SECURITY: Same as QueryUPITransactionsByWorker, audited as QueryUPITransactionsByWorkerFields.
+func (s *SmartContract) QueryUPITransactionsByWorkerFields(ctx contractapi.TransactionContextInterface, workerIDHash string, fields []string) ([]map[string]interface{}, error) {
+	if workerIDHash == "" {
+		return nil, fmt.Errorf("workerIDHash is required")
+	}
+	if err := validateProjection(reflect.TypeOf(UPITransaction{}), fields); err != nil {
+		return nil, err
+	}
+
+	// IAM Check with self-access validation
+	if IAMEnabled {
+		identity, err := CheckAccess(ctx, "QueryUPITransactionsByWorkerFields")
+		if err != nil {
+			s.LogAccessDenied(ctx, "QueryUPITransactionsByWorkerFields", workerIDHash, "upi", err.Error())
+			return nil, fmt.Errorf("access denied: %w", err)
+		}
+		if err := CheckSelfAccess(ctx, identity, "QueryUPITransactionsByWorkerFields", workerIDHash); err != nil {
+			s.LogAccessDenied(ctx, "QueryUPITransactionsByWorkerFields", workerIDHash, "upi", err.Error())
+			return nil, fmt.Errorf("access denied: %w", err)
+		}
+		s.LogDataRead(ctx, "QueryUPITransactionsByWorkerFields", workerIDHash, "upi")
+	}
+
+	transactions, err := queryUPIForWorkers(ctx, []string{workerIDHash})
+	if err != nil {
+		return nil, err
+	}
+	sortUPIChronologically(transactions)
+	return projectRecords(transactions, fields)
+}
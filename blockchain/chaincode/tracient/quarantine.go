@@ -0,0 +1,167 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/hyperledger/fabric-contract-api-go/v2/contractapi"
+)
+
+// ============================================================================
+// STATE QUARANTINE
+// ============================================================================
+
+// Quarantine freezes wage and UPI writes for one state during an incident. It is stored
+// under QUARANTINE_<STATE> (see quarantineState) and removed when the quarantine is lifted.
+type Quarantine struct {
+	DocType       string `json:"docType"`
+	State         string `json:"state"`
+	Reason        string `json:"reason"`
+	QuarantinedBy string `json:"quarantinedBy"`
+	QuarantinedAt string `json:"quarantinedAt"`
+}
+
+// quarantineState normalises a state name for quarantine matching, so "Kerala", "KERALA"
+// and " kerala " are the same state
+func quarantineState(state string) string {
+	return strings.ToUpper(strings.TrimSpace(state))
+}
+
+// quarantineKey returns the ledger key of a state's quarantine
+func quarantineKey(state string) string {
+	return fmt.Sprintf("QUARANTINE_%s", quarantineState(state))
+}
+
+// checkStateQuarantine returns an error if writes for the state are frozen. Records
+// without a state are never quarantined.
+func checkStateQuarantine(ctx contractapi.TransactionContextInterface, state string) error {
+	if quarantineState(state) == "" {
+		return nil
+	}
+	payload, err := ctx.GetStub().GetState(quarantineKey(state))
+	if err != nil {
+		return fmt.Errorf("get state: %w", err)
+	}
+	if payload == nil {
+		return nil
+	}
+
+	var quarantine Quarantine
+	if err := json.Unmarshal(payload, &quarantine); err != nil {
+		return fmt.Errorf("state %s is quarantined", state)
+	}
+	return fmt.Errorf("state %s is quarantined since %s: %s", state, quarantine.QuarantinedAt, quarantine.Reason)
+}
+
+// QuarantineState freezes RecordWage and RecordUPITransaction writes for a state until
+// LiftQuarantine is called. A wage is in the state named by its State field; a UPI payment
+// is in the state of its linked wage, so unlinked payments are not affected. Reads are
+// not restricted. State names are matched case-insensitively and stored upper-case.
+// Emits the "StateQuarantined" event with the Quarantine as payload; Fabric keeps only the
+// last event of a transaction, so it deliberately supersedes the HighRiskActivity event of
+// the audit entry, which is still stored with its risk level.
+// SECURITY: Only admins from Org1MSP.
+func (s *SmartContract) QuarantineState(ctx contractapi.TransactionContextInterface, state string, reason string) error {
+	state = quarantineState(state)
+	reason = strings.TrimSpace(reason)
+
+	// IAM Check
+	var identity *ClientIdentity
+	if IAMEnabled {
+		var err error
+		identity, err = CheckAccess(ctx, "QuarantineState")
+		if err != nil {
+			s.LogAccessDenied(ctx, "QuarantineState", state, "quarantine", err.Error())
+			return fmt.Errorf("access denied: %w", err)
+		}
+	}
+
+	if state == "" {
+		return fmt.Errorf("state is required")
+	}
+	if reason == "" {
+		return fmt.Errorf("reason is required")
+	}
+
+	existing, err := ctx.GetStub().GetState(quarantineKey(state))
+	if err != nil {
+		return fmt.Errorf("get state: %w", err)
+	}
+	if existing != nil {
+		return fmt.Errorf("state %s is already quarantined", state)
+	}
+
+	quarantinedBy := "system"
+	if identity != nil {
+		quarantinedBy = identity.ID
+	}
+	quarantine := Quarantine{
+		DocType:       "quarantine",
+		State:         state,
+		Reason:        reason,
+		QuarantinedBy: quarantinedBy,
+		QuarantinedAt: GetTxTimestampRFC3339(ctx),
+	}
+
+	payload, err := marshalState(quarantine)
+	if err != nil {
+		return fmt.Errorf("marshal quarantine: %w", err)
+	}
+	if err := ctx.GetStub().PutState(quarantineKey(state), payload); err != nil {
+		return fmt.Errorf("put state: %w", err)
+	}
+
+	if IAMEnabled {
+		s.LogDataWrite(ctx, "QuarantineState", state, "quarantine", reason)
+	}
+
+	// Set after the audit entry so it is the transaction's event (see above)
+	if err := ctx.GetStub().SetEvent("StateQuarantined", payload); err != nil {
+		fmt.Printf("warning: failed to emit event: %v\n", err)
+	}
+
+	return nil
+}
+
+// LiftQuarantine lets writes for a quarantined state resume. Emits the
+// "StateQuarantineLifted" event with the lifted Quarantine as payload, superseding the
+// audit entry's HighRiskActivity event as in QuarantineState.
+// SECURITY: Only admins from Org1MSP.
+func (s *SmartContract) LiftQuarantine(ctx contractapi.TransactionContextInterface, state string) error {
+	state = quarantineState(state)
+
+	// IAM Check
+	if IAMEnabled {
+		_, err := CheckAccess(ctx, "LiftQuarantine")
+		if err != nil {
+			s.LogAccessDenied(ctx, "LiftQuarantine", state, "quarantine", err.Error())
+			return fmt.Errorf("access denied: %w", err)
+		}
+	}
+
+	if state == "" {
+		return fmt.Errorf("state is required")
+	}
+
+	payload, err := ctx.GetStub().GetState(quarantineKey(state))
+	if err != nil {
+		return fmt.Errorf("get state: %w", err)
+	}
+	if payload == nil {
+		return fmt.Errorf("state %s is not quarantined", state)
+	}
+	if err := ctx.GetStub().DelState(quarantineKey(state)); err != nil {
+		return fmt.Errorf("delete state: %w", err)
+	}
+
+	if IAMEnabled {
+		s.LogDataWrite(ctx, "LiftQuarantine", state, "quarantine", "quarantine lifted")
+	}
+
+	if err := ctx.GetStub().SetEvent("StateQuarantineLifted", payload); err != nil {
+		fmt.Printf("warning: failed to emit event: %v\n", err)
+	}
+
+	return nil
+}
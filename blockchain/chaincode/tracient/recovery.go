@@ -0,0 +1,73 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"runtime/debug"
+	"time"
+
+	"github.com/hyperledger/fabric-chaincode-go/v2/shim"
+	"github.com/hyperledger/fabric-contract-api-go/v2/contractapi"
+	"github.com/hyperledger/fabric-protos-go-apiv2/peer"
+)
+
+// ============================================================================
+// PANIC RECOVERY
+// ============================================================================
+
+// recoveringChaincode wraps the contract chaincode so that a panic in a transaction function
+// fails that invocation with an error response instead of crashing the chaincode process.
+type recoveringChaincode struct {
+	*contractapi.ContractChaincode
+}
+
+// Init runs the wrapped Init with panic recovery
+func (cc recoveringChaincode) Init(stub shim.ChaincodeStubInterface) (resp *peer.Response) {
+	defer recoverInvocation(stub, &resp)
+	return cc.ContractChaincode.Init(stub)
+}
+
+// Invoke runs the wrapped Invoke with panic recovery
+func (cc recoveringChaincode) Invoke(stub shim.ChaincodeStubInterface) (resp *peer.Response) {
+	defer recoverInvocation(stub, &resp)
+	return cc.ContractChaincode.Invoke(stub)
+}
+
+// recoverInvocation must be deferred directly by Init or Invoke. On a panic it logs a
+// critical audit entry with the stack trace and replaces the response with an error.
+// A failed invocation's writes are discarded, so the entry goes to the chaincode log
+// rather than the ledger.
+func recoverInvocation(stub shim.ChaincodeStubInterface, resp **peer.Response) {
+	recovered := recover()
+	if recovered == nil {
+		return
+	}
+
+	function, _ := stub.GetFunctionAndParameters()
+	entry := AuditLog{
+		DocType:    "audit_log",
+		Timestamp:  time.Now().UTC().Format(time.RFC3339),
+		EventType:  EventTransactionPanic,
+		Function:   function,
+		TargetType: "transaction",
+		Status:     "error",
+		Details:    fmt.Sprintf("panic: %v\n%s", recovered, debug.Stack()),
+		TxID:       stub.GetTxID(),
+		RiskLevel:  RiskCritical,
+	}
+	if payload, err := json.Marshal(entry); err == nil {
+		fmt.Printf("[SECURITY AUDIT] %s\n", payload)
+	}
+
+	*resp = shim.Error(fmt.Sprintf("internal error in %s (tx %s): %v", function, entry.TxID, recovered))
+}
+
+// startChaincode starts the chaincode with panic recovery. In chaincode-as-a-service mode
+// contractapi owns the server and TLS setup, so invocations are not wrapped there.
+func startChaincode(chaincode *contractapi.ContractChaincode) error {
+	if os.Getenv("CHAINCODE_SERVER_ADDRESS") != "" {
+		return chaincode.Start()
+	}
+	return shim.Start(recoveringChaincode{chaincode})
+}
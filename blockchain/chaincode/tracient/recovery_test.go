@@ -0,0 +1,41 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/hyperledger/fabric-chaincode-go/v2/shim"
+	"github.com/hyperledger/fabric-protos-go-apiv2/peer"
+)
+
+func TestRecoverInvocationConvertsPanicToError(t *testing.T) {
+	stub := newMockStub()
+	stub.function = "RecordWage"
+
+	invoke := func() (resp *peer.Response) {
+		defer recoverInvocation(stub, &resp)
+		var wages []*WageRecord
+		_ = wages[3] // index out of range
+		return shim.Success(nil)
+	}
+
+	resp := invoke()
+	if resp == nil || resp.Status != shim.ERROR {
+		t.Fatalf("expected an error response, got %+v", resp)
+	}
+	if !strings.Contains(resp.Message, "RecordWage") || !strings.Contains(resp.Message, "index out of range") {
+		t.Fatalf("unexpected message %q", resp.Message)
+	}
+}
+
+func TestRecoverInvocationLeavesNormalResponses(t *testing.T) {
+	stub := newMockStub()
+	invoke := func() (resp *peer.Response) {
+		defer recoverInvocation(stub, &resp)
+		return shim.Success([]byte("ok"))
+	}
+
+	if resp := invoke(); resp.Status != shim.OK || string(resp.Payload) != "ok" {
+		t.Fatalf("unexpected response %+v", resp)
+	}
+}
@@ -0,0 +1,142 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/hyperledger/fabric-contract-api-go/v2/contractapi"
+)
+
+// ============================================================================
+// JSON SCHEMA EXPORT
+// ============================================================================
+
+// schemaTypes lists the structs exported by GetSchemas: the documents stored on the ledger,
+// the JSON arguments clients submit, and chaincode event payloads. Add new public structs here.
+var schemaTypes = []interface{}{
+	WageRecord{},
+	WageOptions{},
+	WageBatch{},
+	UPITransaction{},
+	User{},
+	WorkerAlias{},
+	PovertyThreshold{},
+	ExchangeRate{},
+	Anomaly{},
+	AuditLog{},
+	AuditQuery{},
+	ConfigEntry{},
+	PrivateWageReference{},
+	Notification{},
+	UPIRecordedEvent{},
+	BatchWagesRecordedEvent{},
+	DiscrepancyResult{},
+	CaseFile{},
+}
+
+// GetSchemas returns JSON-schema (draft-07) definitions for the contract's public data
+// structures, generated from their json struct tags so they cannot drift from the code.
+// Fields tagged omitempty are optional; all others are required. The document carries the
+// chaincode version so clients can cache it per deployment.
+// SECURITY: Requires a valid client identity only.
+func (s *SmartContract) GetSchemas(ctx contractapi.TransactionContextInterface) (string, error) {
+	if IAMEnabled {
+		if _, err := GetClientIdentity(ctx); err != nil {
+			return "", fmt.Errorf("access denied: %w", err)
+		}
+	}
+
+	definitions := make(map[string]interface{}, len(schemaTypes))
+	for _, value := range schemaTypes {
+		t := reflect.TypeOf(value)
+		definitions[t.Name()] = structSchema(t)
+	}
+
+	// encoding/json sorts map keys, so the output is deterministic across peers
+	payload, err := json.Marshal(map[string]interface{}{
+		"$schema":     "http://json-schema.org/draft-07/schema#",
+		"version":     ChaincodeVersion,
+		"definitions": definitions,
+	})
+	if err != nil {
+		return "", fmt.Errorf("marshal schemas: %w", err)
+	}
+	return string(payload), nil
+}
+
+// structSchema builds the object schema of a struct from its exported, json-tagged fields.
+func structSchema(t reflect.Type) map[string]interface{} {
+	properties := map[string]interface{}{}
+	required := []string{}
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name, opts, ok := jsonField(field)
+		if !ok {
+			continue
+		}
+
+		properties[name] = typeSchema(field.Type)
+		if !strings.Contains(","+opts+",", ",omitempty,") {
+			required = append(required, name)
+		}
+	}
+
+	return map[string]interface{}{
+		"type":                 "object",
+		"properties":           properties,
+		"required":             required,
+		"additionalProperties": false,
+	}
+}
+
+// jsonField returns the JSON name and tag options of a struct field, or ok=false for
+// unexported fields and fields tagged "-".
+func jsonField(field reflect.StructField) (name string, opts string, ok bool) {
+	if field.PkgPath != "" {
+		return "", "", false // unexported
+	}
+	tag := field.Tag.Get("json")
+	if tag == "-" {
+		return "", "", false
+	}
+	name, opts, _ = strings.Cut(tag, ",")
+	if name == "" {
+		name = field.Name
+	}
+	return name, opts, true
+}
+
+// typeSchema maps a Go type to its JSON-schema type. Structs listed in schemaTypes are
+// referenced by name; other structs are inlined.
+func typeSchema(t reflect.Type) map[string]interface{} {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return map[string]interface{}{"type": "string"}
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]interface{}{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]interface{}{"type": "number"}
+	case reflect.Slice, reflect.Array:
+		return map[string]interface{}{"type": "array", "items": typeSchema(t.Elem())}
+	case reflect.Map:
+		return map[string]interface{}{"type": "object", "additionalProperties": typeSchema(t.Elem())}
+	case reflect.Struct:
+		for _, value := range schemaTypes {
+			if reflect.TypeOf(value) == t {
+				return map[string]interface{}{"$ref": "#/definitions/" + t.Name()}
+			}
+		}
+		return structSchema(t)
+	}
+	return map[string]interface{}{}
+}